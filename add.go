@@ -0,0 +1,63 @@
+package gache
+
+import "time"
+
+func (g *group) Add(key string, val interface{}) error {
+	return g.AddWithTTL(key, val, g.getExpiration())
+}
+
+func (g *group) Replace(key string, val interface{}) error {
+	return g.ReplaceWithTTL(key, val, g.getExpiration())
+}
+
+func (g *group) AddWithTTL(key string, val interface{}, ttl time.Duration) error {
+	if g.frozen() {
+		return &KeyError{Group: g.key, Key: key, Err: ErrGroupFrozen}
+	}
+
+	if err := g.validate(key, val); err != nil {
+		return &KeyError{Group: g.key, Key: key, Err: err}
+	}
+
+	if !g.setIf(key, val, false, ttl) {
+		return &KeyError{Group: g.key, Key: key, Err: ErrKeyExists}
+	}
+
+	return nil
+}
+
+func (g *group) ReplaceWithTTL(key string, val interface{}, ttl time.Duration) error {
+	if g.frozen() {
+		return &KeyError{Group: g.key, Key: key, Err: ErrGroupFrozen}
+	}
+
+	if err := g.validate(key, val); err != nil {
+		return &KeyError{Group: g.key, Key: key, Err: err}
+	}
+
+	if !g.setIf(key, val, true, ttl) {
+		return &KeyError{Group: g.key, Key: key, Err: ErrKeyNotFound}
+	}
+
+	return nil
+}
+
+// setIf sets value for specified key with specified live duration,
+// if existence of unexpired value matches exists, and reports whether
+// value was set. Zero or negative ttl means value never expires
+func (g *group) setIf(key string, val interface{}, exists bool, ttl time.Duration) bool {
+	now := g.now()
+	s := g.shard(key)
+	s.mx.Lock()
+	if v, ok := s.lookup(key, now.UnixNano()); (ok && !v.absent()) != exists {
+		s.unlock()
+		return false
+	}
+
+	s.store(key, val, now, ttl)
+	s.unlock()
+
+	g.persist(key, val, ttl)
+
+	return true
+}
@@ -0,0 +1,37 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddReplace(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute))
+	defer c.Close()
+
+	if err := c.Replace("a", 1); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+	if err := c.Add("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add("a", 2); !errors.Is(err, ErrKeyExists) {
+		t.Fatalf("expected existing key error, got %v", err)
+	}
+	if err := c.Replace("a", 3); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.Get("a"); v != 3 {
+		t.Fatalf("expected replaced value, got %v", v)
+	}
+
+	clock.Advance(time.Minute)
+	if err := c.Replace("a", 4); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected expired value not to be replaced, got %v", err)
+	}
+	if err := c.Add("a", 5); err != nil {
+		t.Fatalf("expected expired value to be added over, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package gache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdminHandler returns http.Handler, which exposes cache
+// for inspection and invalidation. Group is selected by
+// "group" query parameter, root group is used if it is absent.
+// Endpoints:
+//
+//	GET    /groups        list keys of cache groups
+//	GET    /keys          list keys of group values
+//	GET    /values?key=k  get value as JSON without filling
+//	                      it or changing its expiration
+//	PUT    /values?key=k  set value from JSON body, optional "ttl"
+//	                      query parameter sets its live duration
+//	DELETE /values?key=k  delete value
+//	GET    /stats         get statistics of group, or of whole
+//	                      cache if group is absent
+//	POST   /flush         clear group, or whole cache if group
+//	                      is absent
+//	GET    /hotkeys       list most frequently accessed keys of
+//	                      group, optional "n" query parameter
+//	                      limits their number
+func AdminHandler(c Cache) http.Handler {
+	a := &admin{cache: c}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/groups", a.groups)
+	mux.HandleFunc("/keys", a.keys)
+	mux.HandleFunc("/values", a.values)
+	mux.HandleFunc("/stats", a.stats)
+	mux.HandleFunc("/flush", a.flush)
+	mux.HandleFunc("/hotkeys", a.hotKeys)
+
+	return mux
+}
+
+type admin struct {
+	cache Cache
+}
+
+func (a *admin) groups(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	writeJSON(w, a.cache.Groups())
+}
+
+func (a *admin) keys(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	g, ok := a.group(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, g.Keys())
+}
+
+func (a *admin) values(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+		return
+	}
+
+	g, ok := a.group(w, r)
+	if !ok {
+		return
+	}
+
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		http.Error(w, "key is required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		val, ok := g.Peek(key)
+		if !ok {
+			http.Error(w, fmt.Sprintf("value with key %q not found", key), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, val)
+	case http.MethodPut:
+		var val interface{}
+		if err := json.NewDecoder(r.Body).Decode(&val); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if ttl := r.URL.Query().Get("ttl"); ttl != "" {
+			d, err := time.ParseDuration(ttl)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			g.SetWithTTL(key, val, d)
+		} else {
+			g.Set(key, val)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		g.Del(key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *admin) stats(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	if _, ok := r.URL.Query()["group"]; !ok {
+		writeJSON(w, a.cache.Stats())
+		return
+	}
+
+	g, ok := a.group(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, g.Stats())
+}
+
+func (a *admin) flush(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	if _, ok := r.URL.Query()["group"]; !ok {
+		a.cache.Flush(false)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	g, ok := a.group(w, r)
+	if !ok {
+		return
+	}
+
+	g.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *admin) hotKeys(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+
+	g, ok := a.group(w, r)
+	if !ok {
+		return
+	}
+
+	n := -1
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid n %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	keys := g.HotKeys(n)
+	if keys == nil {
+		keys = []HotKey{}
+	}
+
+	writeJSON(w, keys)
+}
+
+// group returns group, selected by request,
+// or writes error response if it doesn't exist
+func (a *admin) group(w http.ResponseWriter, r *http.Request) (Group, bool) {
+	key := r.URL.Query().Get("group")
+	if key == "" {
+		return a.cache, true
+	}
+
+	g, ok := a.cache.Group(key)
+	if !ok {
+		http.Error(w, fmt.Sprintf("group with key %q doesn't exist", key), http.StatusNotFound)
+		return nil, false
+	}
+
+	return g, true
+}
+
+// allowMethods writes error response if request method
+// is not one of methods
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+// writeJSON writes v as JSON. Value is encoded before writing,
+// so error response isn't appended to partially written body
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,100 @@
+package gache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g")
+
+	h := AdminHandler(c)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/values?group=g&key=a&ttl=1m", `{"n":1}`); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected value to be set, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, "/values?group=g&key=a", ""); rec.Code != http.StatusOK || rec.Body.String() != "{\"n\":1}\n" {
+		t.Fatalf("expected value as JSON, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, "/keys?group=g", ""); rec.Body.String() != "[\"a\"]\n" {
+		t.Fatalf("expected keys of group, got %s", rec.Body)
+	}
+	if rec := do(http.MethodGet, "/groups", ""); rec.Body.String() != "[\"g\"]\n" {
+		t.Fatalf("expected groups of cache, got %s", rec.Body)
+	}
+	if rec := do(http.MethodGet, "/stats?group=g", ""); !strings.Contains(rec.Body.String(), `"Items":1`) {
+		t.Fatalf("expected stats of group, got %s", rec.Body)
+	}
+
+	if rec := do(http.MethodDelete, "/values?group=g&key=a", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected value to be deleted, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/values?group=g&key=a", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected deleted value not found, got %d", rec.Code)
+	}
+
+	c.Set("b", 2)
+	if rec := do(http.MethodPost, "/flush", ""); rec.Code != http.StatusNoContent || c.TotalLen() != 0 {
+		t.Fatalf("expected flushed cache, got %d and %d values", rec.Code, c.TotalLen())
+	}
+}
+
+func TestAdminErrors(t *testing.T) {
+	h := AdminHandler(NewCache())
+
+	for _, tc := range []struct {
+		method, url, body string
+		code              int
+	}{
+		{http.MethodPost, "/keys", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/keys?group=missing", "", http.StatusNotFound},
+		{http.MethodGet, "/values", "", http.StatusBadRequest},
+		{http.MethodPut, "/values?key=a", "{", http.StatusBadRequest},
+		{http.MethodPut, "/values?key=a&ttl=soon", "1", http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body)))
+		if rec.Code != tc.code {
+			t.Fatalf("expected %d for %s %s, got %d", tc.code, tc.method, tc.url, rec.Code)
+		}
+	}
+}
+
+func TestAdminValues(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	var fills int
+	c.GetOrCreateGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		fills++
+		return "filled", true
+	}))
+	c.Set("fn", func() {})
+
+	h := AdminHandler(c)
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	if rec := get("/values?group=g&key=a"); rec.Code != http.StatusNotFound || fills != 0 {
+		t.Fatalf("expected missing value without fill, got %d, %d fills", rec.Code, fills)
+	}
+
+	rec := get("/values?key=fn")
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "{") {
+		t.Fatalf("expected only error response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); strings.Contains(ct, "json") {
+		t.Fatalf("expected error content type, got %q", ct)
+	}
+}
@@ -0,0 +1,117 @@
+package gache
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// autoSnapshot presents settings of automatic snapshots
+type autoSnapshot struct {
+	path     string
+	interval time.Duration
+	// done is closed, when periodic snapshots are stopped
+	done chan struct{}
+}
+
+// runAutoSnapshot periodically writes snapshots
+// of cache until cache is closed
+func (c *cache) runAutoSnapshot() {
+	defer close(c.autoSnapshot.done)
+
+	if c.autoSnapshot.interval <= 0 {
+		<-c.stop
+		return
+	}
+
+	ticker := c.clock.NewTicker(c.autoSnapshot.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if err := c.saveSnapshot(c.autoSnapshot.path); err != nil {
+				c.snapshotError(err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// saveSnapshot writes snapshot of cache to temporary file
+// and replaces file with specified path by it
+func (c *cache) saveSnapshot(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if err := c.writeSnapshot(f); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("gache: snapshot saved", slog.String("path", path))
+	}
+
+	return nil
+}
+
+// writeSnapshot writes snapshot of cache to f,
+// syncs and closes it
+func (c *cache) writeSnapshot(f *os.File) error {
+	if err := c.SaveTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// loadSnapshot loads snapshot of cache from file
+// with specified path, if it exists
+func (c *cache) loadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := c.LoadFrom(f); err != nil {
+		return err
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("gache: snapshot loaded", slog.String("path", path))
+	}
+
+	return nil
+}
+
+// snapshotError passes error of automatic snapshot
+// or write log to its handler
+func (c *cache) snapshotError(err error) {
+	if c.logger != nil {
+		c.logger.Warn("gache: snapshot failed", slog.Any("error", err))
+	}
+
+	if c.snapshotErrorHandler != nil {
+		c.snapshotErrorHandler(err)
+	}
+}
@@ -0,0 +1,71 @@
+package gache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutoSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	c := NewCache(WithAutoSnapshot(path, 0))
+	c.Set("a", "b")
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewCache(WithAutoSnapshot(path, 0))
+	t.Cleanup(func() { c.Close() })
+	if v, ok := c.Get("a"); !ok || v != "b" {
+		t.Fatalf("expected value loaded from final snapshot, got %v, %v", v, ok)
+	}
+}
+
+func TestAutoSnapshotPeriodic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	c := NewCache(WithAutoSnapshot(path, 10*time.Millisecond))
+	t.Cleanup(func() { c.Close() })
+	c.Set("a", "b")
+
+	waitFor(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+
+	loaded := NewCache()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := loaded.LoadFrom(f); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := loaded.Get("a"); !ok || v != "b" {
+		t.Fatalf("expected value in periodic snapshot, got %v, %v", v, ok)
+	}
+}
+
+func TestAutoSnapshotError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "cache.snap")
+
+	errs := make(chan error, 1)
+	c := NewCache(WithAutoSnapshot(path, 10*time.Millisecond), WithSnapshotErrorHandler(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("expected error of periodic snapshot")
+	}
+	if err := c.Close(); err == nil {
+		t.Fatal("expected error of final snapshot")
+	}
+}
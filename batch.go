@@ -0,0 +1,261 @@
+package gache
+
+import "sync/atomic"
+
+// BatchFillFunc presents type of function, intended for
+// filling group values by multiple keys at once.
+// Returned map contains values, which were found
+type BatchFillFunc func(keys []string) map[string]interface{}
+
+func (g *group) GetMulti(keys []string) map[string]interface{} {
+	now := g.now()
+	g.markAccess()
+	staleTTL, serveStale := g.getStaleTTL()
+	vals := make(map[string]interface{}, len(keys))
+
+	var missed []string
+	for s, skeys := range g.splitKeys(keys) {
+		s.mx.Lock()
+		for _, key := range skeys {
+			if g.hot != nil {
+				g.hot.record(key, now.UnixNano())
+			}
+
+			if serveStale {
+				if v, ok := s.getStale(key, now, staleTTL); ok {
+					vals[key] = g.copyOut(v.val())
+					continue
+				}
+			}
+
+			if v, ok := s.lookup(key, now.UnixNano()); ok {
+				if !v.absent() {
+					vals[key] = g.copyOut(v.val())
+				}
+				atomic.AddUint64(&g.stats.hits, 1)
+			} else {
+				missed = append(missed, key)
+			}
+		}
+		s.unlock()
+	}
+
+	if len(missed) == 0 {
+		return vals
+	}
+
+	if bs, ok := g.cache.store.(BatchStore); ok {
+		if missed = g.loadMulti(bs, missed, vals); len(missed) == 0 {
+			return vals
+		}
+	}
+
+	g.mx.RLock()
+	batchFillFunc := g.batchFillFunc
+	g.mx.RUnlock()
+
+	if batchFillFunc == nil {
+		for _, key := range missed {
+			if val, ok := g.Get(key); ok {
+				vals[key] = val
+			}
+		}
+
+		return vals
+	}
+
+	atomic.AddUint64(&g.stats.misses, uint64(len(missed)))
+	g.batchFill(missed, batchFillFunc, vals)
+
+	return vals
+}
+
+func (g *group) SetBatchFillFunc(batchFillFunc BatchFillFunc) {
+	g.mx.Lock()
+	g.batchFillFunc = batchFillFunc
+	g.mx.Unlock()
+}
+
+// batchCall presents call for key, which is filled
+// by batch filling function
+type batchCall struct {
+	shard *shard
+	key   string
+	call  *call
+}
+
+// batchFill fills values with specified keys by single
+// invocation of batchFillFunc, puts them into vals and
+// writes them to store of cache. Keys, which are already
+// being filled, are waited for.
+// Frozen group is not filled
+func (g *group) batchFill(keys []string, batchFillFunc BatchFillFunc, vals map[string]interface{}) {
+	if g.frozen() {
+		return
+	}
+
+	now := g.now()
+	expiration := g.getExpiration()
+
+	var own, waiting []batchCall
+	for s, skeys := range g.splitKeys(keys) {
+		s.mx.Lock()
+		for _, key := range skeys {
+			if c, ok := s.calls[key]; ok {
+				waiting = append(waiting, batchCall{shard: s, key: key, call: c})
+				continue
+			}
+
+			c := &call{done: make(chan struct{})}
+			s.calls[key] = c
+			own = append(own, batchCall{shard: s, key: key, call: c})
+		}
+		s.unlock()
+	}
+
+	if len(own) > 0 {
+		ownKeys := make([]string, len(own))
+		for i, bc := range own {
+			ownKeys[i] = bc.key
+		}
+
+		var filled map[string]interface{}
+		// panicked is kept, if batch filling function panics
+		panicked := true
+		func() {
+			defer func() {
+				for _, bc := range own {
+					bc.call.data, bc.call.ok = filled[bc.key]
+					if panicked {
+						bc.call.err = ErrFillPanic
+					}
+					g.accept(bc.key, bc.call)
+					bc.shard.complete(bc.key, bc.call, expiration, now)
+				}
+			}()
+
+			defer g.recoverFill("")
+
+			atomic.AddUint64(&g.stats.fills, 1)
+			filled = g.cache.wrapBatchFill(g.key, batchFillFunc)(ownKeys)
+			panicked = false
+		}()
+
+		for _, bc := range own {
+			if !bc.call.ok {
+				continue
+			}
+
+			if !bc.call.uncached {
+				g.persist(bc.key, bc.call.data, expiration)
+			}
+			vals[bc.key] = g.copyOut(bc.call.data)
+		}
+	}
+
+	for _, bc := range waiting {
+		<-bc.call.done
+		if bc.call.ok {
+			vals[bc.key] = g.copyOut(bc.call.data)
+		}
+	}
+}
+
+func (g *group) SetMulti(vals map[string]interface{}) {
+	if g.frozen() {
+		return
+	}
+
+	g.markAccess()
+
+	keys := make([]string, 0, len(vals))
+	for key, val := range vals {
+		if g.validate(key, val) == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	now, expiration := g.now(), g.getExpiration()
+	for s, skeys := range g.splitKeys(keys) {
+		s.mx.Lock()
+		for _, key := range skeys {
+			s.store(key, vals[key], now, expiration)
+		}
+		s.unlock()
+	}
+
+	for _, key := range keys {
+		g.persist(key, vals[key], expiration)
+	}
+}
+
+func (g *group) DelMulti(keys ...string) {
+	if g.frozen() {
+		return
+	}
+
+	g.remove(keys...)
+
+	for _, key := range keys {
+		g.unpersist(key)
+	}
+
+	g.cache.broadcast(Invalidation{Kind: InvalidateKeys, Group: g.key, Keys: keys})
+}
+
+// remove deletes values with specified keys from memory
+func (g *group) remove(keys ...string) {
+	for s, skeys := range g.splitKeys(keys) {
+		s.mx.Lock()
+		for _, key := range skeys {
+			s.remove(key)
+		}
+		s.unlock()
+	}
+}
+
+// splitKeys groups keys by shards, which hold them
+func (g *group) splitKeys(keys []string) map[*shard][]string {
+	if len(g.shards) == 1 {
+		return map[*shard][]string{g.shards[0]: keys}
+	}
+
+	split := make(map[*shard][]string, len(g.shards))
+	for _, key := range keys {
+		s := g.shard(key)
+		split[s] = append(split[s], key)
+	}
+
+	return split
+}
+
+func (c *cache) GetGroupValMulti(gkey string, vkeys []string) (map[string]interface{}, error) {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.wrapGroup(g).GetMulti(vkeys), nil
+}
+
+func (c *cache) SetGroupValMulti(gkey string, vals map[string]interface{}) error {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return err
+	}
+
+	c.wrapGroup(g).SetMulti(vals)
+
+	return nil
+}
+
+func (c *cache) DelGroupValMulti(gkey string, vkeys ...string) error {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return err
+	}
+
+	c.wrapGroup(g).DelMulti(vkeys...)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMultiOperations(t *testing.T) {
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled " + key, key != "missing"
+	}))
+	c.NewGroup("g", WithShards(4))
+
+	c.SetMulti(map[string]interface{}{"a": 1, "b": 2})
+	vals := c.GetMulti([]string{"a", "b", "c", "missing"})
+	want := map[string]interface{}{"a": 1, "b": 2, "c": "filled c"}
+	if len(vals) != len(want) {
+		t.Fatalf("expected %v, got %v", want, vals)
+	}
+	for key, val := range want {
+		if vals[key] != val {
+			t.Fatalf("expected %v, got %v", want, vals)
+		}
+	}
+
+	c.DelMulti("a", "c")
+	if vals := c.GetMulti([]string{"a", "b"}); vals["a"] != "filled a" || vals["b"] != 2 {
+		t.Fatalf("expected deleted a to be filled again, got %v", vals)
+	}
+
+	if err := c.SetGroupValMulti("missing", map[string]interface{}{"x": 1}); err == nil {
+		t.Fatal("expected error for missing group")
+	}
+
+	vals = make(map[string]interface{})
+	for _, key := range []string{"x", "y", "z", "w"} {
+		vals[key] = key
+	}
+	if err := c.SetGroupValMulti("g", vals); err != nil {
+		t.Fatal(err)
+	}
+	c.DelGroupValMulti("g", "x", "y")
+	if got, err := c.GetGroupValMulti("g", []string{"x", "y", "z", "w"}); err != nil || len(got) != 2 || got["z"] != "z" || got["w"] != "w" {
+		t.Fatalf("expected z and w in group, got %v, %v", got, err)
+	}
+	if got, err := c.GetGroupValMulti("missing", []string{"x"}); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("expected missing group error, got %v, %v", got, err)
+	}
+}
+
+func TestBatchFill(t *testing.T) {
+	var batches [][]string
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		t.Fatalf("unexpected single fill of %s", key)
+		return nil, false
+	}))
+	c.SetBatchFillFunc(func(keys []string) map[string]interface{} {
+		batches = append(batches, keys)
+		vals := make(map[string]interface{})
+		for _, key := range keys {
+			if key != "missing" {
+				vals[key] = "filled " + key
+			}
+		}
+		return vals
+	})
+
+	c.Set("a", 1)
+	vals := c.GetMulti([]string{"a", "b", "c", "missing"})
+	if len(vals) != 3 || vals["a"] != 1 || vals["b"] != "filled b" || vals["c"] != "filled c" {
+		t.Fatalf("expected found and filled values, got %v", vals)
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("expected single batch of missing keys, got %v", batches)
+	}
+
+	if v, ok := c.Get("b"); !ok || v != "filled b" {
+		t.Fatalf("expected batch filled value to be stored, got %v, %v", v, ok)
+	}
+}
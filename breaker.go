@@ -0,0 +1,94 @@
+package gache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// breaker is circuit breaker of group fills. After threshold
+// consecutive failures within window it opens and short-circuits
+// fills for cooldown. After cooldown single fill probes filling
+// function, while other fills are short-circuited: its failure
+// opens breaker again, success closes it
+type breaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mx        sync.Mutex
+	failures  int
+	first     time.Time
+	openUntil time.Time
+	// probing is set, while fill probes filling function
+	probing atomic.Bool
+}
+
+func newBreaker(threshold int, window, cooldown time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether fill may be performed at now.
+// After cooldown it allows only one fill, until its result
+// is recorded or it is released
+func (b *breaker) allow(now time.Time) bool {
+	b.mx.Lock()
+	closed := b.openUntil.IsZero()
+	cooling := now.Before(b.openUntil)
+	b.mx.Unlock()
+
+	if closed {
+		return true
+	}
+
+	if cooling {
+		return false
+	}
+
+	return b.probing.CompareAndSwap(false, true)
+}
+
+// release lets another fill probe filling function,
+// when allowed fill isn't performed
+func (b *breaker) release() {
+	b.probing.Store(false)
+}
+
+// record accounts result of fill, performed at now
+func (b *breaker) record(ok bool, now time.Time) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if ok {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.probing.Store(false)
+		return
+	}
+
+	if b.probing.Load() {
+		b.open(now)
+		b.probing.Store(false)
+		return
+	}
+
+	if b.failures == 0 || (b.window > 0 && now.Sub(b.first) > b.window) {
+		b.failures = 0
+		b.first = now
+	}
+
+	if b.failures++; b.failures >= b.threshold {
+		b.open(now)
+	}
+}
+
+// open short-circuits fills until cooldown passes.
+// Must be called with locked mutex
+func (b *breaker) open(now time.Time) {
+	b.openUntil = now.Add(b.cooldown)
+	b.failures = 0
+}
@@ -0,0 +1,117 @@
+package gache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	b := newBreaker(2, time.Second, time.Minute)
+	now := time.Unix(1000, 0)
+
+	b.record(false, now)
+	now = now.Add(2 * time.Second)
+	b.record(false, now)
+	if !b.allow(now) {
+		t.Fatal("expected failures outside window not to open breaker")
+	}
+
+	b.record(false, now)
+	if b.allow(now) {
+		t.Fatal("expected open breaker to short-circuit fill")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow(now) {
+		t.Fatal("expected probe after cooldown")
+	}
+	b.record(false, now)
+	if b.allow(now) {
+		t.Fatal("expected failed probe to open breaker")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow(now) {
+		t.Fatal("expected probe after cooldown")
+	}
+	b.record(true, now)
+	b.record(false, now)
+	if !b.allow(now) {
+		t.Fatal("expected successful probe to close breaker")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := newTestClock()
+	var fills int
+	c := NewCache(WithClock(clock), WithCircuitBreaker(2, 0, time.Minute),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			fills++
+			return nil, false
+		}))
+	defer c.Close()
+
+	for i := 0; i < 4; i++ {
+		c.Get("a")
+	}
+	if fills != 2 {
+		t.Fatalf("expected fills short-circuited after threshold, got %d fills", fills)
+	}
+
+	clock.Advance(time.Minute)
+	c.Get("a")
+	if fills != 3 {
+		t.Fatalf("expected probe fill after cooldown, got %d fills", fills)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := newBreaker(2, 0, time.Minute)
+	now := time.Unix(1000, 0)
+
+	b.record(false, now)
+	b.record(false, now)
+	if b.allow(now) {
+		t.Fatal("expected open breaker to short-circuit fill")
+	}
+
+	now = now.Add(time.Minute)
+
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.allow(now) {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 1 {
+		t.Fatalf("expected single probe after cooldown, got %d", allowed)
+	}
+
+	b.release()
+	if !b.allow(now) {
+		t.Fatal("expected probe allowed after release")
+	}
+
+	b.record(false, now)
+	if b.allow(now) {
+		t.Fatal("expected failed probe to open breaker")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow(now) {
+		t.Fatal("expected probe after cooldown")
+	}
+	b.record(true, now)
+	if !b.allow(now) || !b.allow(now) {
+		t.Fatal("expected successful probe to close breaker")
+	}
+}
@@ -0,0 +1,48 @@
+package gache
+
+import "time"
+
+// Clock presents source of current time and timers, which is used
+// for expiration of values, janitor, write-behind flushes and fill
+// timeouts. Custom clock, e.g. fakeclock.Clock, makes time-dependent
+// behavior of cache deterministic in tests
+type Clock interface {
+	// Now returns current time
+	Now() time.Time
+	// After returns channel, which receives current time
+	// once duration d elapses
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns ticker, which sends current time
+	// every period d
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker presents periodic source of time ticks
+type Ticker interface {
+	// C returns channel, which receives ticks
+	C() <-chan time.Time
+	// Stop turns ticker off
+	Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,218 @@
+package gache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/kcasctiv/gache/fakeclock"
+)
+
+// waitUntil waits until cond is true, as background
+// goroutines start waiting for clock asynchronously
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition wasn't met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newClockCache(t *testing.T, opts ...gache.Option) (gache.Cache, *fakeclock.Clock) {
+	clock := fakeclock.New(time.Unix(1000, 0))
+	c := gache.NewCache(append([]gache.Option{gache.WithClock(clock)}, opts...)...)
+	t.Cleanup(func() { c.Close() })
+
+	return c, clock
+}
+
+func TestJanitorFakeClock(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1000, 0))
+	c := gache.NewCache(gache.WithClock(clock), gache.WithExpiration(time.Minute),
+		gache.WithJanitorInterval(time.Second))
+	defer c.Close()
+
+	c.Set("a", 1)
+	waitUntil(t, func() bool { return clock.Waiters() == 1 })
+
+	clock.Advance(time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if n := c.Stats().Expirations; n != 0 {
+		t.Fatalf("expected no expirations before deadline, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	waitUntil(t, func() bool { return c.Stats().Expirations == 1 })
+}
+
+func TestFillTimeoutFakeClock(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1000, 0))
+	release := make(chan struct{})
+	defer close(release)
+
+	c := gache.NewCache(gache.WithClock(clock), gache.WithJanitorInterval(0),
+		gache.WithFillTimeout(time.Second),
+		gache.WithFillFuncCtx(func(ctx context.Context, key string) (interface{}, bool) {
+			<-release
+			return "late", true
+		}))
+	defer c.Close()
+
+	result := make(chan bool)
+	go func() {
+		_, ok := c.Get("a")
+		result <- ok
+	}()
+
+	waitUntil(t, func() bool { return clock.Waiters() == 1 })
+	clock.Advance(time.Second)
+
+	if ok := <-result; ok {
+		t.Fatal("expected fill abandoned on timeout of clock")
+	}
+}
+
+func TestNewGroupWithTTL(t *testing.T) {
+	clock := fakeclock.New(time.Unix(1000, 0))
+	c := gache.NewCache(gache.WithClock(clock), gache.WithJanitorInterval(0))
+	defer c.Close()
+
+	if err := c.NewGroupWithTTL("a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.NewGroupWithTTL("b", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, func() bool { return clock.Waiters() == 2 })
+
+	// recreated group isn't deleted by timer of previous one
+	c.DelGroup("b")
+	c.NewGroup("b")
+
+	clock.Advance(time.Minute)
+	waitUntil(t, func() bool {
+		_, ok := c.Group("a")
+		return !ok
+	})
+	if _, ok := c.Group("b"); !ok {
+		t.Fatal("expected recreated group kept")
+	}
+}
+
+func TestExpirationFakeClock(t *testing.T) {
+	c, clock := newClockCache(t, gache.WithExpiration(time.Minute))
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+
+	clock.Advance(59 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected live value 1, got %v, %v", v, ok)
+	}
+	if ttl, ok := c.TTL("a"); !ok || ttl != time.Second {
+		t.Fatalf("expected 1s left, got %v, %v", ttl, ok)
+	}
+
+	clock.Advance(time.Second)
+	if v, ok := c.Get("a"); ok {
+		t.Fatalf("expected expired value, got %v", v)
+	}
+	if _, ok := c.TTL("a"); ok {
+		t.Fatal("expected no TTL for expired value")
+	}
+	if v, ok := c.Get("b"); !ok || v != 2 {
+		t.Fatalf("expected value with own TTL, got %v, %v", v, ok)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected value with own TTL to expire")
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected value without expiration, got %v, %v", v, ok)
+	}
+}
+
+func TestTouchFakeClock(t *testing.T) {
+	c, clock := newClockCache(t, gache.WithExpiration(time.Minute))
+
+	c.Set("a", 1)
+	clock.Advance(30 * time.Second)
+	c.Touch("a", time.Minute)
+
+	clock.Advance(45 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected touched value to live")
+	}
+
+	clock.Advance(15 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected touched value to expire")
+	}
+}
+
+func TestSlidingExpirationFakeClock(t *testing.T) {
+	c, clock := newClockCache(t)
+	g := c.GetOrCreateGroup("g", gache.WithExpiration(time.Minute), gache.WithSlidingExpiration())
+
+	g.Set("a", 1)
+	for i := 0; i < 5; i++ {
+		clock.Advance(45 * time.Second)
+		if _, ok := g.Get("a"); !ok {
+			t.Fatalf("expected accessed value to live after %d reads", i)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := g.Get("a"); ok {
+		t.Fatal("expected idle value to expire")
+	}
+}
+
+func TestAddWithTTL(t *testing.T) {
+	c, _ := newClockCache(t, gache.WithExpiration(time.Minute))
+
+	c.AddWithTTL("a", 1, time.Hour)
+	c.AddWithTTL("b", 1, 0)
+	c.AddWithTTL("c", 1, -1)
+	if err := c.AddWithTTL("a", 2, time.Second); err == nil {
+		t.Fatal("expected existing value not to be added")
+	}
+	c.ReplaceWithTTL("c", 2, time.Second)
+	c.Add("d", 1)
+
+	for key, want := range map[string]time.Duration{"a": time.Hour, "b": 0, "c": time.Second, "d": time.Minute} {
+		if ttl, ok := c.TTL(key); !ok || ttl != want {
+			t.Fatalf("expected TTL %v of %s, got %v, %v", want, key, ttl, ok)
+		}
+	}
+}
+
+func TestStatsItems(t *testing.T) {
+	c, clock := newClockCache(t)
+	g := c.GetOrCreateGroup("g",
+		gache.WithNegativeCaching(time.Hour),
+		gache.WithFillFunc(func(key string) (interface{}, bool) {
+			return nil, false
+		}),
+	)
+
+	g.SetWithTTL("a", 1, time.Minute)
+	g.Set("b", 2)
+	g.Get("missing")
+
+	if n := g.Stats().Items; n != 2 {
+		t.Fatalf("expected 2 items without cached absence, got %d", n)
+	}
+
+	clock.Advance(time.Minute)
+	if n := g.Stats().Items; n != 1 {
+		t.Fatalf("expected expired item not counted, got %d", n)
+	}
+}
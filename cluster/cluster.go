@@ -0,0 +1,787 @@
+// Package cluster provides gache.Cache, which shards values
+// across fleet of gache nodes by consistent hashing
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/kcasctiv/gache/consistenthash"
+)
+
+const defaultReplicas = 50
+
+var (
+	// ErrUnsupported is returned by operations,
+	// which can't be performed on cluster
+	ErrUnsupported = errors.New("cluster: operation isn't supported")
+	// ErrNoNodes is returned by operations, which
+	// need node, when cluster has no nodes
+	ErrNoNodes = errors.New("cluster: no nodes")
+	// ErrNotScanner is returned by AddNode and RemoveNode,
+	// when values of node can't be migrated, as node
+	// doesn't implement Scanner
+	ErrNotScanner = errors.New("cluster: node doesn't implement Scanner")
+)
+
+// Scanner is implemented by caches of nodes, which list their
+// groups and values, so values can be migrated between nodes,
+// e.g. grpc.Client. Caches of process are wrapped by Local
+type Scanner interface {
+	// GroupKeys returns keys of groups of cache
+	GroupKeys() ([]string, error)
+	// Scan calls f for unexpired values of group with specified
+	// key and their remaining live durations, until f returns false.
+	// Zero duration means value doesn't expire. Values must not be
+	// filled. Empty key means root group
+	Scan(gkey string, f func(key string, val interface{}, ttl time.Duration) bool) error
+}
+
+// Local returns node, which wraps cache of process
+// and lists its values from snapshots of groups
+func Local(cache gache.Cache) gache.Cache {
+	return &localNode{Cache: cache}
+}
+
+// localNode presents cache of process, which implements Scanner
+type localNode struct {
+	gache.Cache
+}
+
+func (n *localNode) GroupKeys() ([]string, error) {
+	return n.Groups(), nil
+}
+
+func (n *localNode) Scan(gkey string, f func(key string, val interface{}, ttl time.Duration) bool) error {
+	var g gache.Group = n.Cache
+	if gkey != "" {
+		var ok bool
+		if g, ok = n.Group(gkey); !ok {
+			return nil
+		}
+	}
+
+	view := g.Snapshot()
+	for key, item := range view.Items() {
+		var ttl time.Duration
+		if !item.Expiration.IsZero() {
+			if ttl = item.Expiration.Sub(view.Taken()); ttl <= 0 {
+				continue
+			}
+		}
+
+		if !f(key, item.Value, ttl) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Dialer presents type of function, which returns cache of node
+// with specified address, e.g. client of gache gRPC server:
+//
+//	func(addr string) (gache.Cache, error) {
+//		return grpc.NewClient(addr, opts...)
+//	}
+//
+// Values are migrated only from nodes, which implement Scanner
+type Dialer func(addr string) (gache.Cache, error)
+
+// Cluster is gache.Cache, which shards values across nodes.
+// Value with key k of group g is owned by node, which owns
+// key "g/k" on hash ring, so adding or removing node moves
+// minimal number of values. Moved values are migrated between
+// nodes, which implement Scanner, without filling them.
+//
+// Operations on single value are served by its owner, operations
+// on multiple values are split by owners, operations on whole group
+// or cache are performed on every node and their results are
+// combined. Groups created by cluster are created on nodes added
+// later with the same options, settings changed after creation
+// of group are applied to current nodes only. Values are popped
+// from nodes in order of their addresses, so PopOldest returns
+// the oldest value of the first node, which has values.
+// Snapshot returns empty view, as views of nodes can't be combined
+type Cluster struct {
+	*clusterGroup
+
+	dial     Dialer
+	replicas int
+	hash     consistenthash.Hash
+
+	mx     sync.RWMutex
+	ring   *consistenthash.Map
+	nodes  map[string]gache.Cache
+	groups map[string][]gache.GroupOption
+	mws    []gache.GroupMiddleware
+}
+
+// Option presents type of function, intended for
+// configuring cluster on creation
+type Option func(*Cluster)
+
+// WithReplicas sets number of virtual nodes
+// per node on hash ring. Default is 50
+func WithReplicas(replicas int) Option {
+	return func(c *Cluster) {
+		c.replicas = replicas
+	}
+}
+
+// WithHash sets hash function of ring. Default is crc32.ChecksumIEEE
+func WithHash(hash consistenthash.Hash) Option {
+	return func(c *Cluster) {
+		c.hash = hash
+	}
+}
+
+// New returns cluster without nodes, which are connected by dial
+func New(dial Dialer, opts ...Option) *Cluster {
+	c := &Cluster{
+		dial:     dial,
+		replicas: defaultReplicas,
+		nodes:    make(map[string]gache.Cache),
+		groups:   make(map[string][]gache.GroupOption),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.ring = consistenthash.New(c.replicas, c.hash)
+	c.clusterGroup = &clusterGroup{cluster: c}
+
+	return c
+}
+
+// AddNode connects to nodes with specified addresses and puts
+// them on hash ring. Values, which new nodes own, are migrated
+// to them. Nodes, which are already added, are skipped.
+// Nodes stay on ring, if migration fails, and errors of
+// migration are returned, as values may be left on wrong nodes
+func (c *Cluster) AddNode(addrs ...string) error {
+	added := make(map[string]gache.Cache, len(addrs))
+	for _, addr := range addrs {
+		c.mx.RLock()
+		_, exists := c.nodes[addr]
+		c.mx.RUnlock()
+
+		if exists || added[addr] != nil {
+			continue
+		}
+
+		node, err := c.dial(addr)
+		if err != nil {
+			for _, node := range added {
+				node.Close()
+			}
+			return err
+		}
+		added[addr] = node
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	c.mx.Lock()
+	for key, opts := range c.groups {
+		for _, node := range added {
+			node.GetOrCreateGroup(key, opts...)
+		}
+	}
+
+	old := make(map[string]gache.Cache, len(c.nodes))
+	for addr, node := range c.nodes {
+		old[addr] = node
+	}
+
+	for addr, node := range added {
+		c.nodes[addr] = node
+		c.ring.Add(addr)
+	}
+	c.mx.Unlock()
+
+	var errs []error
+	for addr, node := range old {
+		errs = append(errs, c.migrate(node, addr))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RemoveNode takes nodes with specified addresses off hash ring,
+// migrates their values to new owners and closes them.
+// Returns errors of migration, as values of nodes,
+// which failed, are lost
+func (c *Cluster) RemoveNode(addrs ...string) error {
+	removed := make(map[string]gache.Cache, len(addrs))
+
+	c.mx.Lock()
+	for _, addr := range addrs {
+		if node, ok := c.nodes[addr]; ok {
+			removed[addr] = node
+			delete(c.nodes, addr)
+			c.ring.Remove(addr)
+		}
+	}
+	c.mx.Unlock()
+
+	var errs []error
+	for addr, node := range removed {
+		errs = append(errs, c.migrate(node, addr))
+		node.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
+// Nodes returns sorted addresses of nodes
+func (c *Cluster) Nodes() []string {
+	c.mx.RLock()
+	addrs := make([]string, 0, len(c.nodes))
+	for addr := range c.nodes {
+		addrs = append(addrs, addr)
+	}
+	c.mx.RUnlock()
+
+	sort.Strings(addrs)
+
+	return addrs
+}
+
+// migrate moves values of node with specified address,
+// which are owned by other nodes, to their owners.
+// Values keep their remaining live durations
+func (c *Cluster) migrate(node gache.Cache, addr string) error {
+	scanner, ok := node.(Scanner)
+	if !ok {
+		return fmt.Errorf("migrate %s: %w", addr, ErrNotScanner)
+	}
+
+	gkeys, err := scanner.GroupKeys()
+	if err != nil {
+		return fmt.Errorf("migrate %s: %w", addr, err)
+	}
+
+	for _, gkey := range append([]string{""}, gkeys...) {
+		var moved []string
+		err := scanner.Scan(gkey, func(key string, val interface{}, ttl time.Duration) bool {
+			owner, ok := c.owner(gkey, key)
+			if ok && owner != node {
+				c.nodeGroup(owner, gkey).SetWithTTL(key, val, ttl)
+				moved = append(moved, key)
+			}
+			return true
+		})
+
+		if len(moved) > 0 {
+			nodeGroup(node, gkey, nil).DelMulti(moved...)
+		}
+		if err != nil {
+			return fmt.Errorf("migrate %s group %q: %w", addr, gkey, err)
+		}
+	}
+
+	return nil
+}
+
+// owner returns node, which owns value with
+// specified key of group with specified gkey
+func (c *Cluster) owner(gkey, key string) (gache.Cache, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	node, ok := c.nodes[c.ring.Get(gkey+"/"+key)]
+	return node, ok
+}
+
+// all returns nodes in order of their addresses
+func (c *Cluster) all() []gache.Cache {
+	addrs := c.Nodes()
+
+	c.mx.RLock()
+	nodes := make([]gache.Cache, 0, len(addrs))
+	for _, addr := range addrs {
+		if node, ok := c.nodes[addr]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	c.mx.RUnlock()
+
+	return nodes
+}
+
+// nodeGroup returns group of node with specified key,
+// creating it with options of cluster group
+func (c *Cluster) nodeGroup(node gache.Cache, key string) gache.Group {
+	c.mx.RLock()
+	opts := c.groups[key]
+	c.mx.RUnlock()
+
+	return nodeGroup(node, key, opts)
+}
+
+// nodeGroup returns group of node with specified key, creating it
+// with specified options. Empty key means root group of node
+func nodeGroup(node gache.Cache, key string, opts []gache.GroupOption) gache.Group {
+	if key == "" {
+		return node
+	}
+
+	return node.GetOrCreateGroup(key, opts...)
+}
+
+// group returns cluster group with specified key,
+// wrapped by middlewares of cluster
+func (c *Cluster) group(key string) gache.Group {
+	var g gache.Group = &clusterGroup{cluster: c, key: key}
+	if key == "" {
+		g = c.clusterGroup
+	}
+
+	c.mx.RLock()
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		g = c.mws[i](g)
+	}
+	c.mx.RUnlock()
+
+	return g
+}
+
+// register makes group with specified key and options created
+// on nodes added later, if it isn't registered yet.
+// Returns false if group is already registered
+func (c *Cluster) register(key string, opts []gache.GroupOption) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if _, ok := c.groups[key]; ok {
+		return false
+	}
+	c.groups[key] = opts
+
+	return true
+}
+
+func (c *Cluster) Group(key string) (gache.Group, bool) {
+	c.mx.RLock()
+	_, ok := c.groups[key]
+	c.mx.RUnlock()
+
+	nodes := c.all()
+	for i := 0; i < len(nodes) && !ok; i++ {
+		_, ok = nodes[i].Group(key)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	return c.group(key), true
+}
+
+func (c *Cluster) NewGroup(key string, opts ...gache.GroupOption) error {
+	if !c.register(key, opts) {
+		return &gache.GroupError{Group: key, Err: gache.ErrGroupExists}
+	}
+
+	var err error
+	for _, node := range c.all() {
+		if nerr := node.NewGroup(key, opts...); err == nil {
+			err = nerr
+		}
+	}
+
+	return err
+}
+
+func (c *Cluster) NewGroupWithTTL(key string, ttl time.Duration, opts ...gache.GroupOption) error {
+	if !c.register(key, opts) {
+		return &gache.GroupError{Group: key, Err: gache.ErrGroupExists}
+	}
+
+	time.AfterFunc(ttl, func() {
+		c.mx.Lock()
+		delete(c.groups, key)
+		c.mx.Unlock()
+	})
+
+	var err error
+	for _, node := range c.all() {
+		if nerr := node.NewGroupWithTTL(key, ttl, opts...); err == nil {
+			err = nerr
+		}
+	}
+
+	return err
+}
+
+func (c *Cluster) GetOrCreateGroup(key string, opts ...gache.GroupOption) gache.Group {
+	c.register(key, opts)
+	return c.group(key)
+}
+
+func (c *Cluster) SetGroupDefaults(cascade bool, opts ...gache.GroupOption) {
+	for _, node := range c.all() {
+		node.SetGroupDefaults(cascade, opts...)
+	}
+}
+
+func (c *Cluster) RenameGroup(oldKey, newKey string) error {
+	return ErrUnsupported
+}
+
+func (c *Cluster) CloneGroup(src, dst string) error {
+	return ErrUnsupported
+}
+
+func (c *Cluster) DelGroup(key string) error {
+	c.mx.Lock()
+	_, found := c.groups[key]
+	delete(c.groups, key)
+	c.mx.Unlock()
+
+	for _, node := range c.all() {
+		if node.DelGroup(key) == nil {
+			found = true
+		}
+	}
+
+	if !found {
+		return &gache.GroupError{Group: key, Err: gache.ErrGroupNotFound}
+	}
+
+	return nil
+}
+
+func (c *Cluster) Flush(deleteGroups bool) {
+	if deleteGroups {
+		c.mx.Lock()
+		c.groups = make(map[string][]gache.GroupOption)
+		c.mx.Unlock()
+	}
+
+	for _, node := range c.all() {
+		node.Flush(deleteGroups)
+	}
+}
+
+func (c *Cluster) DelOlderThan(age time.Duration) int {
+	var n int
+	for _, node := range c.all() {
+		n += node.DelOlderThan(age)
+	}
+
+	return n
+}
+
+func (c *Cluster) GetGroupVal(gkey, vkey string) (interface{}, error) {
+	val, ok := c.group(gkey).Get(vkey)
+	if !ok {
+		return nil, &gache.KeyError{Group: gkey, Key: vkey, Err: gache.ErrKeyNotFound}
+	}
+
+	return val, nil
+}
+
+func (c *Cluster) HasGroupVal(gkey, vkey string) bool {
+	return c.group(gkey).Has(vkey)
+}
+
+func (c *Cluster) SetGroupVal(gkey, vkey string, val interface{}) error {
+	c.group(gkey).Set(vkey, val)
+	return nil
+}
+
+func (c *Cluster) SetGroupValWithTTL(gkey, vkey string, val interface{}, ttl time.Duration) error {
+	c.group(gkey).SetWithTTL(vkey, val, ttl)
+	return nil
+}
+
+func (c *Cluster) Groups() []string {
+	keys := make(map[string]struct{})
+
+	c.mx.RLock()
+	for key := range c.groups {
+		keys[key] = struct{}{}
+	}
+	c.mx.RUnlock()
+
+	for _, node := range c.all() {
+		for _, key := range node.Groups() {
+			keys[key] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func (c *Cluster) TotalLen() int {
+	var n int
+	for _, node := range c.all() {
+		n += node.TotalLen()
+	}
+
+	return n
+}
+
+func (c *Cluster) GetGroupValMulti(gkey string, vkeys []string) (map[string]interface{}, error) {
+	return c.group(gkey).GetMulti(vkeys), nil
+}
+
+func (c *Cluster) SetGroupValMulti(gkey string, vals map[string]interface{}) error {
+	c.group(gkey).SetMulti(vals)
+	return nil
+}
+
+func (c *Cluster) DelGroupValMulti(gkey string, vkeys ...string) error {
+	c.group(gkey).DelMulti(vkeys...)
+	return nil
+}
+
+func (c *Cluster) WrapFills(wrappers ...gache.FillWrapper) {
+	for _, node := range c.all() {
+		node.WrapFills(wrappers...)
+	}
+}
+
+func (c *Cluster) WrapBatchFills(wrappers ...gache.BatchFillWrapper) {
+	for _, node := range c.all() {
+		node.WrapBatchFills(wrappers...)
+	}
+}
+
+func (c *Cluster) Use(mw ...gache.GroupMiddleware) {
+	c.mx.Lock()
+	c.mws = append(c.mws, mw...)
+	c.mx.Unlock()
+}
+
+// RegisterPeers does nothing, as values are owned by nodes of cluster
+func (c *Cluster) RegisterPeers(picker gache.PeerPicker) {}
+
+func (c *Cluster) Subscribe(buffer int) (<-chan gache.Event, func()) {
+	nodes := c.all()
+	chans := make([]<-chan gache.Event, len(nodes))
+	stops := make([]func(), len(nodes))
+	for i, node := range nodes {
+		chans[i], stops[i] = node.Subscribe(buffer)
+	}
+
+	return mergeEvents(chans, stops, buffer)
+}
+
+func (c *Cluster) OnEvicted(f gache.EvictFunc) {
+	for _, node := range c.all() {
+		node.OnEvicted(f)
+	}
+}
+
+func (c *Cluster) OnGroupEvicted(f func(key string)) {
+	for _, node := range c.all() {
+		node.OnGroupEvicted(f)
+	}
+}
+
+func (c *Cluster) OnFillPanic(f gache.FillPanicFunc) {
+	for _, node := range c.all() {
+		node.OnFillPanic(f)
+	}
+}
+
+func (c *Cluster) OnInvalidValue(f gache.InvalidValueFunc) {
+	for _, node := range c.all() {
+		node.OnInvalidValue(f)
+	}
+}
+
+func (c *Cluster) SaveTo(w io.Writer) error {
+	return ErrUnsupported
+}
+
+func (c *Cluster) LoadFrom(r io.Reader) error {
+	return ErrUnsupported
+}
+
+// Close takes all nodes off hash ring and closes them
+// without migrating their values
+func (c *Cluster) Close() error {
+	c.mx.Lock()
+	nodes := c.nodes
+	c.nodes = make(map[string]gache.Cache)
+	c.ring = consistenthash.New(c.replicas, c.hash)
+	c.mx.Unlock()
+
+	var err error
+	for _, node := range nodes {
+		if nerr := node.Close(); err == nil {
+			err = nerr
+		}
+	}
+
+	return err
+}
+
+func (c *Cluster) Stats() gache.Stats {
+	var stats gache.Stats
+	for _, node := range c.all() {
+		stats = addStats(stats, node.Stats())
+	}
+
+	return stats
+}
+
+func (c *Cluster) MemoryUsage() int64 {
+	var n int64
+	for _, node := range c.all() {
+		n += node.MemoryUsage()
+	}
+
+	return n
+}
+
+func (c *Cluster) GroupStats() map[string]gache.Stats {
+	stats := make(map[string]gache.Stats)
+	for _, node := range c.all() {
+		for key, s := range node.GroupStats() {
+			stats[key] = addStats(stats[key], s)
+		}
+	}
+
+	return stats
+}
+
+func (c *Cluster) RefreshPoolStats() gache.RefreshPoolStats {
+	var stats gache.RefreshPoolStats
+	for _, node := range c.all() {
+		s := node.RefreshPoolStats()
+		stats.Workers += s.Workers
+		stats.Queued += s.Queued
+		stats.QueueSize += s.QueueSize
+		stats.Dropped += s.Dropped
+	}
+
+	return stats
+}
+
+func (c *Cluster) Quotas() map[string]gache.Quota {
+	quotas := make(map[string]gache.Quota)
+	for _, node := range c.all() {
+		for key, q := range node.Quotas() {
+			quotas[key] = addQuota(quotas[key], q)
+		}
+	}
+
+	return quotas
+}
+
+func (c *Cluster) Merge(other gache.Cache, policy gache.ConflictPolicy) error {
+	for _, gkey := range append([]string{""}, other.Groups()...) {
+		src := gache.Group(other)
+		if gkey != "" {
+			var ok bool
+			if src, ok = other.Group(gkey); !ok {
+				continue
+			}
+		}
+
+		dst := c.GetOrCreateGroup(gkey)
+		now := time.Now()
+		for key, item := range src.Snapshot().Items() {
+			var ttl time.Duration
+			if !item.Expiration.IsZero() {
+				if ttl = item.Expiration.Sub(now); ttl <= 0 {
+					continue
+				}
+			}
+
+			if policy != gache.ConflictOverwrite {
+				if old, ok := dst.GetItem(key); ok &&
+					(policy == gache.ConflictKeepExisting || !old.Created.Before(item.Created)) {
+					continue
+				}
+			}
+
+			dst.SetWithTTL(key, item.Value, ttl)
+		}
+	}
+
+	return nil
+}
+
+// addStats returns sum of statistics a and b
+func addStats(a, b gache.Stats) gache.Stats {
+	a.Hits += b.Hits
+	a.Misses += b.Misses
+	a.Fills += b.Fills
+	a.FillFailures += b.FillFailures
+	a.Evictions += b.Evictions
+	a.Expirations += b.Expirations
+	a.Rejections += b.Rejections
+	a.Items += b.Items
+	a.Cost += b.Cost
+	a.KeyBytesSaved += b.KeyBytesSaved
+
+	return a
+}
+
+// addQuota returns sum of limits and their utilization a and b
+func addQuota(a, b gache.Quota) gache.Quota {
+	a.MaxEntries += b.MaxEntries
+	a.Entries += b.Entries
+	a.MaxCost += b.MaxCost
+	a.Cost += b.Cost
+
+	return a
+}
+
+// mergeEvents returns channel, which delivers events of chans, and
+// function, which stops them by stops and closes the channel.
+// Events are delivered without blocking, so they are dropped,
+// if receiver falls behind
+func mergeEvents(chans []<-chan gache.Event, stops []func(), buffer int) (<-chan gache.Event, func()) {
+	if buffer < 1 {
+		buffer = 64
+	}
+
+	out := make(chan gache.Event, buffer)
+
+	var wg sync.WaitGroup
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(ch <-chan gache.Event) {
+			defer wg.Done()
+			for ev := range ch {
+				select {
+				case out <- ev:
+				default:
+				}
+			}
+		}(ch)
+	}
+
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			for _, stop := range stops {
+				stop()
+			}
+			wg.Wait()
+			close(out)
+		})
+	}
+}
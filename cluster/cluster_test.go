@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+func TestMigrate(t *testing.T) {
+	nodes := make(map[string]gache.Cache)
+	c := New(func(addr string) (gache.Cache, error) {
+		node := Local(gache.NewCache())
+		nodes[addr] = node
+		return node, nil
+	})
+	defer c.Close()
+
+	if err := c.AddNode("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	g := c.GetOrCreateGroup("g")
+	for _, key := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		g.SetWithTTL(key, key, time.Hour)
+	}
+	c.Set("root", 1)
+
+	if err := c.AddNode("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := nodes["b"].Group("g"); !ok {
+		t.Fatal("expected group created on new node")
+	}
+	if n := nodes["b"].TotalLen(); n == 0 {
+		t.Fatal("expected values migrated to new node")
+	}
+
+	if err := c.RemoveNode("a"); err != nil {
+		t.Fatal(err)
+	}
+	if n := nodes["b"].TotalLen(); n != 9 {
+		t.Fatalf("expected all values on left node, got %d", n)
+	}
+	if ttl, ok := g.TTL("1"); !ok || ttl <= 59*time.Minute {
+		t.Fatalf("expected migrated value to keep TTL, got %v, %v", ttl, ok)
+	}
+	if val, err := c.GetGroupVal("g", "8"); err != nil || val != "8" {
+		t.Fatalf("expected value routed to owner, got %v, %v", val, err)
+	}
+}
+
+func TestNoNodes(t *testing.T) {
+	c := New(func(addr string) (gache.Cache, error) {
+		return gache.NewCache(), nil
+	})
+	defer c.Close()
+
+	if err := c.Add("a", 1); !errors.Is(err, ErrNoNodes) {
+		t.Fatalf("expected ErrNoNodes, got %v", err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected miss without nodes")
+	}
+}
+
+func TestMigrateNotScanner(t *testing.T) {
+	c := New(func(addr string) (gache.Cache, error) {
+		return gache.NewCache(), nil
+	})
+	defer c.Close()
+
+	if err := c.AddNode("a"); err != nil {
+		t.Fatal(err)
+	}
+	c.Set("a", 1)
+
+	if err := c.RemoveNode("a"); !errors.Is(err, ErrNotScanner) {
+		t.Fatalf("expected migration error, got %v", err)
+	}
+}
+
+func TestDelPrefix(t *testing.T) {
+	c := New(func(addr string) (gache.Cache, error) {
+		return gache.NewCache(), nil
+	})
+	defer c.Close()
+
+	if err := c.AddNode("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	g := c.GetOrCreateGroup("g")
+	for _, key := range []string{"user:1", "user:2", "item:1"} {
+		c.Set(key, 1)
+		g.Set(key, 1)
+	}
+
+	if n := g.DelPrefix("user:"); n != 2 {
+		t.Fatalf("expected 2 values of group removed, got %d", n)
+	}
+	g.Set("user:1", 1)
+	if n := c.DelPrefix("user:"); n != 3 {
+		t.Fatalf("expected values of all groups removed, got %d", n)
+	}
+	if n := c.TotalLen(); n != 2 {
+		t.Fatalf("expected unmatched values kept, got %d", n)
+	}
+}
@@ -0,0 +1,571 @@
+package cluster
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+// clusterGroup is gache.Group, which values are
+// spread over groups of nodes with the same key
+type clusterGroup struct {
+	cluster *Cluster
+	key     string
+}
+
+// route returns group of node, which owns value with
+// specified key, and false if cluster has no nodes
+func (g *clusterGroup) route(key string) (gache.Group, bool) {
+	node, ok := g.cluster.owner(g.key, key)
+	if !ok {
+		return nil, false
+	}
+
+	return g.cluster.nodeGroup(node, g.key), true
+}
+
+// split groups keys by groups of nodes, which own them
+func (g *clusterGroup) split(keys []string) map[gache.Group][]string {
+	split := make(map[gache.Group][]string)
+	for _, key := range keys {
+		if ng, ok := g.route(key); ok {
+			split[ng] = append(split[ng], key)
+		}
+	}
+
+	return split
+}
+
+// groups returns groups of all nodes in order of their addresses
+func (g *clusterGroup) groups() []gache.Group {
+	nodes := g.cluster.all()
+	groups := make([]gache.Group, len(nodes))
+	for i, node := range nodes {
+		groups[i] = g.cluster.nodeGroup(node, g.key)
+	}
+
+	return groups
+}
+
+func (g *clusterGroup) Get(key string) (interface{}, bool) {
+	return g.GetCtx(context.Background(), key)
+}
+
+func (g *clusterGroup) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, false
+	}
+
+	return ng.GetCtx(ctx, key)
+}
+
+func (g *clusterGroup) Set(key string, val interface{}) {
+	if ng, ok := g.route(key); ok {
+		ng.Set(key, val)
+	}
+}
+
+func (g *clusterGroup) Touch(key string, ttl time.Duration) bool {
+	ng, ok := g.route(key)
+	return ok && ng.Touch(key, ttl)
+}
+
+func (g *clusterGroup) SetWithCost(key string, val interface{}, cost int64) {
+	if ng, ok := g.route(key); ok {
+		ng.SetWithCost(key, val, cost)
+	}
+}
+
+func (g *clusterGroup) SetWithTTL(key string, val interface{}, ttl time.Duration) {
+	if ng, ok := g.route(key); ok {
+		ng.SetWithTTL(key, val, ttl)
+	}
+}
+
+func (g *clusterGroup) GetStale(key string) (interface{}, bool, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, false, false
+	}
+
+	return ng.GetStale(key)
+}
+
+func (g *clusterGroup) GetItem(key string) (gache.Item, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return gache.Item{}, false
+	}
+
+	return ng.GetItem(key)
+}
+
+func (g *clusterGroup) GetWithExpiration(key string) (interface{}, time.Time, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	return ng.GetWithExpiration(key)
+}
+
+func (g *clusterGroup) TTL(key string) (time.Duration, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return 0, false
+	}
+
+	return ng.TTL(key)
+}
+
+func (g *clusterGroup) Peek(key string) (interface{}, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, false
+	}
+
+	return ng.Peek(key)
+}
+
+func (g *clusterGroup) Has(key string) bool {
+	ng, ok := g.route(key)
+	return ok && ng.Has(key)
+}
+
+func (g *clusterGroup) Add(key string, val interface{}) error {
+	ng, ok := g.route(key)
+	if !ok {
+		return ErrNoNodes
+	}
+
+	return ng.Add(key, val)
+}
+
+func (g *clusterGroup) Replace(key string, val interface{}) error {
+	ng, ok := g.route(key)
+	if !ok {
+		return ErrNoNodes
+	}
+
+	return ng.Replace(key, val)
+}
+
+func (g *clusterGroup) AddWithTTL(key string, val interface{}, ttl time.Duration) error {
+	ng, ok := g.route(key)
+	if !ok {
+		return ErrNoNodes
+	}
+
+	return ng.AddWithTTL(key, val, ttl)
+}
+
+func (g *clusterGroup) ReplaceWithTTL(key string, val interface{}, ttl time.Duration) error {
+	ng, ok := g.route(key)
+	if !ok {
+		return ErrNoNodes
+	}
+
+	return ng.ReplaceWithTTL(key, val, ttl)
+}
+
+func (g *clusterGroup) GetOrSet(key string, val interface{}) (interface{}, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return val, false
+	}
+
+	return ng.GetOrSet(key, val)
+}
+
+func (g *clusterGroup) GetOrCompute(key string, compute func() (interface{}, bool)) (interface{}, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return compute()
+	}
+
+	return ng.GetOrCompute(key, compute)
+}
+
+func (g *clusterGroup) Update(key string, fn func(old interface{}, exists bool) (interface{}, bool)) {
+	if ng, ok := g.route(key); ok {
+		ng.Update(key, fn)
+	}
+}
+
+func (g *clusterGroup) Increment(key string, delta int64) (int64, error) {
+	ng, ok := g.route(key)
+	if !ok {
+		return 0, ErrNoNodes
+	}
+
+	return ng.Increment(key, delta)
+}
+
+func (g *clusterGroup) Decrement(key string, delta int64) (int64, error) {
+	ng, ok := g.route(key)
+	if !ok {
+		return 0, ErrNoNodes
+	}
+
+	return ng.Decrement(key, delta)
+}
+
+func (g *clusterGroup) GetWithVersion(key string) (interface{}, uint64, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return ng.GetWithVersion(key)
+}
+
+func (g *clusterGroup) SetIfVersion(key string, val interface{}, version uint64) bool {
+	ng, ok := g.route(key)
+	return ok && ng.SetIfVersion(key, val, version)
+}
+
+func (g *clusterGroup) Del(key string) {
+	if ng, ok := g.route(key); ok {
+		ng.Del(key)
+	}
+}
+
+func (g *clusterGroup) GetAndDelete(key string) (interface{}, bool) {
+	ng, ok := g.route(key)
+	if !ok {
+		return nil, false
+	}
+
+	return ng.GetAndDelete(key)
+}
+
+func (g *clusterGroup) PopOldest() (string, interface{}, bool) {
+	for _, ng := range g.groups() {
+		if key, val, ok := ng.PopOldest(); ok {
+			return key, val, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (g *clusterGroup) PopRandom() (string, interface{}, bool) {
+	groups := g.groups()
+	for _, i := range rand.Perm(len(groups)) {
+		if key, val, ok := groups[i].PopRandom(); ok {
+			return key, val, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (g *clusterGroup) DelPrefix(prefix string) int {
+	var n int
+	for _, ng := range g.groups() {
+		n += ng.DelPrefix(prefix)
+	}
+
+	return n
+}
+
+func (g *clusterGroup) DelMatch(pattern string) (int, error) {
+	var n int
+	for _, ng := range g.groups() {
+		deleted, err := ng.DelMatch(pattern)
+		if err != nil {
+			return n, err
+		}
+		n += deleted
+	}
+
+	return n, nil
+}
+
+func (g *clusterGroup) DelFunc(match func(key string, val interface{}) bool) int {
+	var n int
+	for _, ng := range g.groups() {
+		n += ng.DelFunc(match)
+	}
+
+	return n
+}
+
+func (g *clusterGroup) DelOlderThan(age time.Duration) int {
+	var n int
+	for _, ng := range g.groups() {
+		n += ng.DelOlderThan(age)
+	}
+
+	return n
+}
+
+func (g *clusterGroup) Watch(key string) (<-chan gache.Event, func()) {
+	ng, ok := g.route(key)
+	if !ok {
+		return mergeEvents(nil, nil, 0)
+	}
+
+	return ng.Watch(key)
+}
+
+func (g *clusterGroup) WatchGroup() (<-chan gache.Event, func()) {
+	groups := g.groups()
+	chans := make([]<-chan gache.Event, len(groups))
+	stops := make([]func(), len(groups))
+	for i, ng := range groups {
+		chans[i], stops[i] = ng.WatchGroup()
+	}
+
+	return mergeEvents(chans, stops, 0)
+}
+
+func (g *clusterGroup) Pin(key string) bool {
+	ng, ok := g.route(key)
+	return ok && ng.Pin(key)
+}
+
+func (g *clusterGroup) Unpin(key string) bool {
+	ng, ok := g.route(key)
+	return ok && ng.Unpin(key)
+}
+
+func (g *clusterGroup) Subgroup(key string, opts ...gache.GroupOption) gache.Group {
+	if g.key != "" {
+		key = g.key + gache.GroupSeparator + key
+	}
+
+	return g.cluster.GetOrCreateGroup(key, opts...)
+}
+
+func (g *clusterGroup) Warm(ctx context.Context, keys []string, opts ...gache.WarmOption) error {
+	for ng, nkeys := range g.split(keys) {
+		if err := ng.Warm(ctx, nkeys, opts...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *clusterGroup) GetMulti(keys []string) map[string]interface{} {
+	vals := make(map[string]interface{}, len(keys))
+	for ng, nkeys := range g.split(keys) {
+		for key, val := range ng.GetMulti(nkeys) {
+			vals[key] = val
+		}
+	}
+
+	return vals
+}
+
+func (g *clusterGroup) SetMulti(vals map[string]interface{}) {
+	for ng, nvals := range g.splitVals(vals) {
+		ng.SetMulti(nvals)
+	}
+}
+
+func (g *clusterGroup) Import(vals map[string]interface{}, ttl time.Duration) {
+	for ng, nvals := range g.splitVals(vals) {
+		ng.Import(nvals, ttl)
+	}
+}
+
+// splitVals groups values by groups of nodes, which own them
+func (g *clusterGroup) splitVals(vals map[string]interface{}) map[gache.Group]map[string]interface{} {
+	split := make(map[gache.Group]map[string]interface{})
+	for key, val := range vals {
+		ng, ok := g.route(key)
+		if !ok {
+			continue
+		}
+
+		if split[ng] == nil {
+			split[ng] = make(map[string]interface{})
+		}
+		split[ng][key] = val
+	}
+
+	return split
+}
+
+func (g *clusterGroup) DelMulti(keys ...string) {
+	for ng, nkeys := range g.split(keys) {
+		ng.DelMulti(nkeys...)
+	}
+}
+
+func (g *clusterGroup) Clear() {
+	for _, ng := range g.groups() {
+		ng.Clear()
+	}
+}
+
+func (g *clusterGroup) Keys() []string {
+	var keys []string
+	for _, ng := range g.groups() {
+		keys = append(keys, ng.Keys()...)
+	}
+
+	return keys
+}
+
+func (g *clusterGroup) Len() int {
+	var n int
+	for _, ng := range g.groups() {
+		n += ng.Len()
+	}
+
+	return n
+}
+
+func (g *clusterGroup) Range(f func(key string, val interface{}) bool) {
+	for _, ng := range g.groups() {
+		stopped := false
+		ng.Range(func(key string, val interface{}) bool {
+			stopped = !f(key, val)
+			return !stopped
+		})
+
+		if stopped {
+			return
+		}
+	}
+}
+
+func (g *clusterGroup) Snapshot() gache.GroupView {
+	return gache.GroupView{}
+}
+
+func (g *clusterGroup) SetExpiration(expiration time.Duration, modes ...gache.ExpirationMode) {
+	for _, ng := range g.groups() {
+		ng.SetExpiration(expiration, modes...)
+	}
+}
+
+func (g *clusterGroup) SetFillFunc(fillFunc gache.FillFunc) {
+	for _, ng := range g.groups() {
+		ng.SetFillFunc(fillFunc)
+	}
+}
+
+func (g *clusterGroup) SetFillFuncCtx(fillFunc gache.FillFuncCtx) {
+	for _, ng := range g.groups() {
+		ng.SetFillFuncCtx(fillFunc)
+	}
+}
+
+func (g *clusterGroup) SetFillFuncTTL(fillFunc gache.FillFuncTTL) {
+	for _, ng := range g.groups() {
+		ng.SetFillFuncTTL(fillFunc)
+	}
+}
+
+func (g *clusterGroup) SetNamedFillFunc(name string) bool {
+	ok := false
+	for _, ng := range g.groups() {
+		ok = ng.SetNamedFillFunc(name) || ok
+	}
+
+	return ok
+}
+
+func (g *clusterGroup) SetBatchFillFunc(batchFillFunc gache.BatchFillFunc) {
+	for _, ng := range g.groups() {
+		ng.SetBatchFillFunc(batchFillFunc)
+	}
+}
+
+func (g *clusterGroup) SetRefreshPolicy(policy gache.RefreshPolicy, staleTTL time.Duration) {
+	for _, ng := range g.groups() {
+		ng.SetRefreshPolicy(policy, staleTTL)
+	}
+}
+
+func (g *clusterGroup) Stats() gache.Stats {
+	var stats gache.Stats
+	for _, ng := range g.groups() {
+		stats = addStats(stats, ng.Stats())
+	}
+
+	return stats
+}
+
+func (g *clusterGroup) MemoryUsage() int64 {
+	var n int64
+	for _, ng := range g.groups() {
+		n += ng.MemoryUsage()
+	}
+
+	return n
+}
+
+func (g *clusterGroup) HotKeys(n int) []gache.HotKey {
+	var hot []gache.HotKey
+	for _, ng := range g.groups() {
+		hot = append(hot, ng.HotKeys(n)...)
+	}
+
+	sort.SliceStable(hot, func(i, j int) bool { return hot[i].Count > hot[j].Count })
+	if n >= 0 && len(hot) > n {
+		hot = hot[:n]
+	}
+
+	return hot
+}
+
+func (g *clusterGroup) Freeze() {
+	for _, ng := range g.groups() {
+		ng.Freeze()
+	}
+}
+
+func (g *clusterGroup) Unfreeze() {
+	for _, ng := range g.groups() {
+		ng.Unfreeze()
+	}
+}
+
+// Frozen reports whether groups of all nodes are frozen
+func (g *clusterGroup) Frozen() bool {
+	groups := g.groups()
+	for _, ng := range groups {
+		if !ng.Frozen() {
+			return false
+		}
+	}
+
+	return len(groups) > 0
+}
+
+// SetQuota splits limits evenly between groups of nodes
+func (g *clusterGroup) SetQuota(maxEntries int, maxCost int64) {
+	groups := g.groups()
+	if len(groups) == 0 {
+		return
+	}
+
+	if maxEntries > 0 {
+		maxEntries = (maxEntries + len(groups) - 1) / len(groups)
+	}
+	if maxCost > 0 {
+		maxCost = (maxCost + int64(len(groups)) - 1) / int64(len(groups))
+	}
+
+	for _, ng := range groups {
+		ng.SetQuota(maxEntries, maxCost)
+	}
+}
+
+func (g *clusterGroup) Quota() gache.Quota {
+	var q gache.Quota
+	for _, ng := range g.groups() {
+		q = addQuota(q, ng.Quota())
+	}
+
+	return q
+}
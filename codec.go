@@ -0,0 +1,50 @@
+package gache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec presents interface of value serializers
+type Codec interface {
+	// Marshal returns encoded representation of v
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into v
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// GobCodec is Codec, which uses encoding/gob.
+// Concrete types stored as interface values
+// must be registered with gob.Register
+type GobCodec struct{}
+
+// Marshal returns gob encoding of v
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes gob encoded data into v
+func (GobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// JSONCodec is Codec, which uses encoding/json.
+// Values stored as interface values are decoded
+// into generic types, e.g. numbers into float64
+type JSONCodec struct{}
+
+// Marshal returns JSON encoding of v
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON encoded data into v
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,144 @@
+package gache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compressor presents interface of compression algorithms
+type Compressor interface {
+	// Compress returns compressed data
+	Compress(data []byte) ([]byte, error)
+	// Decompress returns data decompressed from compressed one
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor is Compressor, which uses compress/gzip
+// with specified compression level. Zero level means
+// gzip.DefaultCompression
+type GzipCompressor struct {
+	Level int
+}
+
+// Compress returns gzip compressed data
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress returns data decompressed from gzip compressed one
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// compressedCodec is Codec, which compresses
+// encoded representation of values
+type compressedCodec struct {
+	codec      Codec
+	compressor Compressor
+}
+
+// CompressedCodec returns Codec, which compresses representation
+// of values, encoded by codec, with compressor, e.g. for smaller
+// snapshots or values sent between peers
+func CompressedCodec(codec Codec, compressor Compressor) Codec {
+	return compressedCodec{codec: codec, compressor: compressor}
+}
+
+func (c compressedCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compressor.Compress(data)
+}
+
+func (c compressedCodec) Unmarshal(data []byte, v interface{}) error {
+	data, err := c.compressor.Decompress(data)
+	if err != nil {
+		return err
+	}
+
+	return c.codec.Unmarshal(data, v)
+}
+
+// compressed presents value of group, which is kept compressed
+type compressed struct {
+	data       []byte
+	str        bool
+	compressor Compressor
+}
+
+// compress returns data compressed by compressor of group, if it
+// is []byte or string of at least threshold size and shrinks on
+// compression. Otherwise data is returned as is
+func (g *group) compress(data interface{}) interface{} {
+	if g.compressor == nil {
+		return data
+	}
+
+	var (
+		b   []byte
+		str bool
+	)
+	switch d := data.(type) {
+	case []byte:
+		b = d
+	case string:
+		b, str = []byte(d), true
+	default:
+		return data
+	}
+
+	if len(b) < g.compressThreshold {
+		return data
+	}
+
+	z, err := g.compressor.Compress(b)
+	if err != nil || len(z) >= len(b) {
+		return data
+	}
+
+	return compressed{data: z, str: str, compressor: g.compressor}
+}
+
+// decompress returns data decompressed from compressed value
+func decompress(c compressed) (interface{}, error) {
+	b, err := c.compressor.Decompress(c.data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptValue, err)
+	}
+
+	if c.str {
+		return string(b), nil
+	}
+
+	return b, nil
+}
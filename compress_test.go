@@ -0,0 +1,58 @@
+package gache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithCompression(GzipCompressor{}, 16))
+	g, _ := c.Group("g")
+
+	long := strings.Repeat("a", 1024)
+	g.Set("str", long)
+	g.Set("bytes", []byte(long))
+	g.Set("short", "a")
+
+	if v, ok := g.Get("str"); !ok || v != long {
+		t.Fatalf("expected decompressed string, got %v, %v", v, ok)
+	}
+	if v, ok := g.Get("bytes"); !ok || !bytes.Equal(v.([]byte), []byte(long)) {
+		t.Fatalf("expected decompressed bytes, got %v, %v", v, ok)
+	}
+	if v, ok := g.Get("short"); !ok || v != "a" {
+		t.Fatalf("expected short value, got %v, %v", v, ok)
+	}
+
+	gr := g.(*group)
+	for key, want := range map[string]bool{"str": true, "bytes": true, "short": false} {
+		s := gr.shard(key)
+		s.mx.RLock()
+		_, ok := s.values[key].data.(compressed)
+		s.mx.RUnlock()
+		if ok != want {
+			t.Fatalf("expected %s compressed %v, got %v", key, want, ok)
+		}
+	}
+}
+
+func TestCompressedCodec(t *testing.T) {
+	codec := CompressedCodec(GobCodec{}, GzipCompressor{Level: 9})
+
+	in := peerEntry{Value: strings.Repeat("a", 1024)}
+	data, err := codec.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out peerEntry
+	if err := codec.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Fatalf("expected %v, got %v", in, out)
+	}
+}
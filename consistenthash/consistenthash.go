@@ -0,0 +1,94 @@
+// Package consistenthash provides ring of nodes,
+// which maps keys to nodes by consistent hashing
+package consistenthash
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// Hash presents type of function, intended for hashing keys
+type Hash func(data []byte) uint32
+
+// Map is ring of nodes. Every node is placed on ring
+// as multiple virtual nodes, so keys are spread evenly
+// and adding or removing node moves minimal number of keys.
+// Map is not safe for concurrent use
+type Map struct {
+	hash     Hash
+	replicas int
+	ring     []uint32
+	nodes    map[uint32]string
+}
+
+// New returns empty ring with specified number of virtual nodes
+// per node and hash function. Nil hash means crc32.ChecksumIEEE
+func New(replicas int, hash Hash) *Map {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if hash == nil {
+		hash = crc32.ChecksumIEEE
+	}
+
+	return &Map{
+		hash:     hash,
+		replicas: replicas,
+		nodes:    make(map[uint32]string),
+	}
+}
+
+// IsEmpty reports whether ring has no nodes
+func (m *Map) IsEmpty() bool {
+	return len(m.ring) == 0
+}
+
+// Add puts nodes on ring
+func (m *Map) Add(nodes ...string) {
+	for _, node := range nodes {
+		for i := 0; i < m.replicas; i++ {
+			h := m.hash([]byte(strconv.Itoa(i) + node))
+			if _, ok := m.nodes[h]; !ok {
+				m.ring = append(m.ring, h)
+			}
+			m.nodes[h] = node
+		}
+	}
+
+	sort.Slice(m.ring, func(i, j int) bool { return m.ring[i] < m.ring[j] })
+}
+
+// Remove takes nodes off ring
+func (m *Map) Remove(nodes ...string) {
+	removed := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		removed[node] = true
+	}
+
+	ring := m.ring[:0]
+	for _, h := range m.ring {
+		if removed[m.nodes[h]] {
+			delete(m.nodes, h)
+			continue
+		}
+		ring = append(ring, h)
+	}
+	m.ring = ring
+}
+
+// Get returns node, which owns specified key,
+// or empty string if ring has no nodes
+func (m *Map) Get(key string) string {
+	if m.IsEmpty() {
+		return ""
+	}
+
+	h := m.hash([]byte(key))
+	i := sort.Search(len(m.ring), func(i int) bool { return m.ring[i] >= h })
+	if i == len(m.ring) {
+		i = 0
+	}
+
+	return m.nodes[m.ring[i]]
+}
@@ -0,0 +1,52 @@
+package consistenthash
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	// hash maps decimal strings to their values,
+	// so placement of virtual nodes is predictable
+	m := New(3, func(data []byte) uint32 {
+		n, _ := strconv.Atoi(string(data))
+		return uint32(n)
+	})
+
+	if m.Get("1") != "" {
+		t.Fatal("expected no node for empty ring")
+	}
+
+	// virtual nodes 2, 12, 22, 4, 14, 24, 6, 16, 26
+	m.Add("6", "4", "2")
+
+	for key, node := range map[string]string{"2": "2", "11": "2", "23": "4", "27": "2"} {
+		if got := m.Get(key); got != node {
+			t.Fatalf("expected node %s for key %s, got %s", node, key, got)
+		}
+	}
+
+	// virtual nodes 8, 18, 28
+	m.Add("8")
+	if got := m.Get("27"); got != "8" {
+		t.Fatalf("expected added node 8 for key 27, got %s", got)
+	}
+
+	m.Remove("8")
+	if got := m.Get("27"); got != "2" {
+		t.Fatalf("expected key 27 back on node 2, got %s", got)
+	}
+}
+
+func TestMapConsistency(t *testing.T) {
+	a, b := New(50, nil), New(50, nil)
+	a.Add("x", "y", "z")
+	b.Add("z", "x", "y")
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		if a.Get(key) != b.Get(key) {
+			t.Fatalf("expected same node for key %s regardless of order", key)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gache
+
+import "reflect"
+
+// Cloner presents type of function, which returns copy of value,
+// so changes of the copy don't affect original value
+type Cloner func(val interface{}) interface{}
+
+// copyOut returns copy of val made by cloner
+// of group, if group copies values on read
+func (g *group) copyOut(val interface{}) interface{} {
+	if g.cloner == nil || val == nil {
+		return val
+	}
+
+	return g.cloner(val)
+}
+
+// deepCopy returns deep copy of val made by reflection. Pointers,
+// maps, slices, arrays, interfaces and exported fields of structs
+// are copied recursively, other data is shared with val.
+// Pointers, which are referenced repeatedly, are copied once
+func deepCopy(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	return copyValue(reflect.ValueOf(val), make(map[copiedPtr]reflect.Value)).Interface()
+}
+
+// copiedPtr identifies pointer, which is already copied
+type copiedPtr struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// copyValue returns deep copy of v. See deepCopy
+func copyValue(v reflect.Value, seen map[copiedPtr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copiedPtr{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := seen[key]; ok {
+			return c
+		}
+
+		c := reflect.New(v.Type().Elem())
+		seen[key] = c
+		c.Elem().Set(copyValue(v.Elem(), seen))
+
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem(), seen))
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(copyValue(iter.Key(), seen), copyValue(iter.Value(), seen))
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(copyValue(v.Field(i), seen))
+			}
+		}
+
+		return c
+	}
+
+	return v
+}
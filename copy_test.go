@@ -0,0 +1,66 @@
+package gache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	type node struct {
+		Name  string
+		Tags  []string
+		Attrs map[string]interface{}
+		Next  *node
+		n     int
+	}
+
+	orig := &node{
+		Name:  "a",
+		Tags:  []string{"x"},
+		Attrs: map[string]interface{}{"list": []int{1}},
+		n:     1,
+	}
+	orig.Next = orig
+
+	c := deepCopy(orig).(*node)
+	if c == orig || c.Next != c {
+		t.Fatal("expected copied pointer, referenced repeatedly, to be copied once")
+	}
+	if !reflect.DeepEqual(c, orig) {
+		t.Fatalf("expected equal copy, got %+v", c)
+	}
+
+	c.Tags[0] = "y"
+	c.Attrs["list"].([]int)[0] = 2
+	if orig.Tags[0] != "x" || orig.Attrs["list"].([]int)[0] != 1 {
+		t.Fatal("expected changes of copy not to affect original")
+	}
+}
+
+func TestCopyOnRead(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithCopyOnRead(nil))
+	g, _ := c.Group("g")
+
+	g.Set("a", []int{1, 2})
+	v, _ := g.Get("a")
+	v.([]int)[0] = 3
+	if v, _ := g.Get("a"); v.([]int)[0] != 1 {
+		t.Fatalf("expected cached value not modified, got %v", v)
+	}
+
+	var clones int
+	c.NewGroup("h", WithCopyOnRead(func(val interface{}) interface{} {
+		clones++
+		return val
+	}))
+	h, _ := c.Group("h")
+	h.Set("a", 1)
+	h.Get("a")
+	h.Peek("a")
+	h.GetMulti([]string{"a"})
+	if clones != 3 {
+		t.Fatalf("expected cloner called on every read, got %d calls", clones)
+	}
+}
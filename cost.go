@@ -0,0 +1,25 @@
+package gache
+
+// Coster presents type of function, intended for
+// computing cost of value, e.g. its size in bytes
+type Coster func(val interface{}) int64
+
+func (g *group) SetWithCost(key string, val interface{}, cost int64) {
+	if g.frozen() {
+		return
+	}
+
+	if g.validate(key, val) != nil {
+		return
+	}
+
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	s.storeWithCost(key, val, now, expiration, cost)
+	s.unlock()
+
+	g.persist(key, val, expiration)
+}
@@ -0,0 +1,51 @@
+package gache
+
+import "testing"
+
+func TestMaxCost(t *testing.T) {
+	c := NewCache(WithMaxCost(10))
+
+	c.SetWithCost("a", 1, 4)
+	c.SetWithCost("b", 2, 4)
+	c.Get("a")
+	c.SetWithCost("c", 3, 4)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected least recently used value to be evicted")
+	}
+	if stats := c.Stats(); stats.Cost != 8 || stats.Evictions != 1 {
+		t.Fatalf("expected cost 8 after single eviction, got %+v", stats)
+	}
+
+	c.SetWithCost("a", 1, 1)
+	if cost := c.Stats().Cost; cost != 5 {
+		t.Fatalf("expected cost of replaced value to be updated, got %d", cost)
+	}
+
+	c.SetWithCost("big", 0, 20)
+	if n := c.Len(); n != 0 {
+		t.Fatalf("expected oversized value to evict everything, got %d values", n)
+	}
+}
+
+func TestCoster(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g", WithMaxCost(10), WithCoster(func(val interface{}) int64 {
+		return int64(len(val.(string)))
+	}))
+	g, _ := c.Group("g")
+
+	g.Set("a", "12345")
+	g.Set("b", "123456")
+	if _, ok := g.Get("a"); ok {
+		t.Fatal("expected value to be evicted by computed cost")
+	}
+	if cost := g.Stats().Cost; cost != 6 {
+		t.Fatalf("expected cost 6, got %d", cost)
+	}
+
+	g.Del("b")
+	if cost := g.Stats().Cost; cost != 0 {
+		t.Fatalf("expected zero cost after deletion, got %d", cost)
+	}
+}
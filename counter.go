@@ -0,0 +1,88 @@
+package gache
+
+func (g *group) Increment(key string, delta int64) (int64, error) {
+	if g.frozen() {
+		return 0, &KeyError{Group: g.key, Key: key, Err: ErrGroupFrozen}
+	}
+
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	v, ok := s.lookup(key, now.UnixNano())
+	if !ok || v.absent() {
+		if err := g.validate(key, delta); err != nil {
+			s.unlock()
+			return 0, &KeyError{Group: g.key, Key: key, Err: err}
+		}
+
+		s.store(key, delta, now, expiration)
+		s.unlock()
+
+		g.persist(key, delta, expiration)
+
+		return delta, nil
+	}
+
+	data, n, ok := add(v.val(), delta)
+	if !ok {
+		s.unlock()
+		return 0, &KeyError{Group: g.key, Key: key, Err: ErrNotInteger}
+	}
+
+	if err := g.validate(key, data); err != nil {
+		s.unlock()
+		return 0, &KeyError{Group: g.key, Key: key, Err: err}
+	}
+
+	s.replace(key, v, data)
+	s.unlock()
+
+	g.persist(key, data, v.remaining(now.UnixNano()))
+
+	return n, nil
+}
+
+func (g *group) Decrement(key string, delta int64) (int64, error) {
+	return g.Increment(key, -delta)
+}
+
+// add returns sum of integer data and delta of the same type
+// as data and as int64, and false if data isn't integer
+func add(data interface{}, delta int64) (interface{}, int64, bool) {
+	switch d := data.(type) {
+	case int:
+		n := d + int(delta)
+		return n, int64(n), true
+	case int8:
+		n := d + int8(delta)
+		return n, int64(n), true
+	case int16:
+		n := d + int16(delta)
+		return n, int64(n), true
+	case int32:
+		n := d + int32(delta)
+		return n, int64(n), true
+	case int64:
+		n := d + delta
+		return n, n, true
+	case uint:
+		n := d + uint(delta)
+		return n, int64(n), true
+	case uint8:
+		n := d + uint8(delta)
+		return n, int64(n), true
+	case uint16:
+		n := d + uint16(delta)
+		return n, int64(n), true
+	case uint32:
+		n := d + uint32(delta)
+		return n, int64(n), true
+	case uint64:
+		n := d + uint64(delta)
+		return n, int64(n), true
+	}
+
+	return nil, 0, false
+}
@@ -0,0 +1,73 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIncrement(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute))
+	defer c.Close()
+
+	if n, err := c.Increment("a", 5); err != nil || n != 5 {
+		t.Fatalf("expected new counter 5, got %d, %v", n, err)
+	}
+	clock.Advance(10 * time.Second)
+	if n, err := c.Decrement("a", 2); err != nil || n != 3 {
+		t.Fatalf("expected counter 3, got %d, %v", n, err)
+	}
+	if ttl, _ := c.TTL("a"); ttl != 50*time.Second {
+		t.Fatalf("expected counter to keep its expiration, got %v", ttl)
+	}
+
+	c.Set("b", uint8(255))
+	if n, err := c.Increment("b", 1); err != nil || n != 0 {
+		t.Fatalf("expected overflow of value type, got %d, %v", n, err)
+	}
+	if v, _ := c.Get("b"); v != uint8(0) {
+		t.Fatalf("expected type of value kept, got %T %v", v, v)
+	}
+
+	c.Set("c", "text")
+	if _, err := c.Increment("c", 1); !errors.Is(err, ErrNotInteger) {
+		t.Fatalf("expected not integer error, got %v", err)
+	}
+}
+
+func TestIncrementValidation(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	errNegative := errors.New("negative")
+	var rejected int
+	c.OnInvalidValue(func(group, key string, val interface{}, err error) {
+		rejected++
+	})
+
+	g := c.GetOrCreateGroup("g", WithValidator(func(key string, val interface{}) error {
+		if n, ok := val.(int64); ok && n < 0 {
+			return errNegative
+		}
+		return nil
+	}))
+
+	if _, err := g.Decrement("a", 1); !errors.Is(err, errNegative) {
+		t.Fatalf("expected rejected new value, got %v", err)
+	}
+	if g.Has("a") {
+		t.Fatal("expected rejected value not stored")
+	}
+
+	g.Increment("a", 2)
+	if _, err := g.Decrement("a", 3); !errors.Is(err, errNegative) {
+		t.Fatalf("expected rejected result, got %v", err)
+	}
+	if v, _ := g.Get("a"); v != int64(2) {
+		t.Fatalf("expected old value kept, got %v", v)
+	}
+	if rejected != 2 {
+		t.Fatalf("expected 2 rejected values, got %d", rejected)
+	}
+}
@@ -0,0 +1,45 @@
+package gache
+
+func (c *cache) SetGroupDefaults(cascade bool, opts ...GroupOption) {
+	c.mx.Lock()
+	c.groupDefaults = opts
+	groups := make([]*group, 0, len(c.groups))
+	for _, g := range c.groups {
+		groups = append(groups, g)
+	}
+	c.mx.Unlock()
+
+	if !cascade {
+		return
+	}
+
+	d := defaultGroup(c, "")
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	for _, g := range groups {
+		g.inherit(d)
+	}
+}
+
+// inherit applies to group settings of group d,
+// which may be changed at runtime and differ from defaults
+func (g *group) inherit(d *group) {
+	if d.expiration != 0 {
+		g.SetExpiration(d.expiration)
+	}
+
+	if d.refreshPolicy != RefreshSync || d.staleTTL != 0 {
+		g.SetRefreshPolicy(d.refreshPolicy, d.staleTTL)
+	}
+
+	g.mx.Lock()
+	if d.fillFunc != nil {
+		g.fillFunc, g.fillName = d.fillFunc, d.fillName
+	}
+	if d.batchFillFunc != nil {
+		g.batchFillFunc = d.batchFillFunc
+	}
+	g.mx.Unlock()
+}
@@ -0,0 +1,44 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupDefaults(t *testing.T) {
+	c := NewCache(WithGroupDefaults(WithExpiration(time.Minute)))
+	defer c.Close()
+
+	a := c.GetOrCreateGroup("a")
+	b := c.GetOrCreateGroup("b", WithExpiration(time.Hour))
+	c.Set("root", 1)
+	a.Set("x", 1)
+	b.Set("x", 1)
+
+	if ttl, _ := c.TTL("root"); ttl != 0 {
+		t.Fatalf("expected root group unaffected by defaults, got %v", ttl)
+	}
+	if ttl, _ := a.TTL("x"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected default expiration, got %v", ttl)
+	}
+	if ttl, _ := b.TTL("x"); ttl <= time.Minute {
+		t.Fatalf("expected own option to override default, got %v", ttl)
+	}
+
+	fill := func(key string) (interface{}, bool) { return "filled", true }
+	c.SetGroupDefaults(false, WithFillFunc(fill))
+	if _, ok := a.Get("y"); ok {
+		t.Fatal("expected existing group unaffected without cascade")
+	}
+	if v, _ := c.GetOrCreateGroup("c").Get("y"); v != "filled" {
+		t.Fatalf("expected new group to inherit defaults, got %v", v)
+	}
+
+	c.SetGroupDefaults(true, WithFillFunc(fill), WithExpiration(time.Second))
+	if v, _ := a.Get("y"); v != "filled" {
+		t.Fatalf("expected fill function cascaded to existing group, got %v", v)
+	}
+	if ttl, _ := a.TTL("y"); ttl > time.Second {
+		t.Fatalf("expected expiration cascaded to existing group, got %v", ttl)
+	}
+}
@@ -0,0 +1,122 @@
+package gache
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// delBatch is number of values, which are checked for removal
+// at once, holding lock of their shard
+const delBatch = 256
+
+func (g *group) DelPrefix(prefix string) int {
+	return g.delFunc(func(key string, _ value) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+func (g *group) DelMatch(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	return g.delFunc(func(key string, _ value) bool {
+		matched, _ := path.Match(pattern, key)
+		return matched
+	}), nil
+}
+
+func (g *group) DelFunc(match func(key string, val interface{}) bool) int {
+	return g.delFunc(func(key string, v value) bool {
+		return !v.absent() && match(key, v.val())
+	})
+}
+
+func (g *group) DelOlderThan(age time.Duration) int {
+	cutoff := g.now().Add(-age).UnixNano()
+	return g.delFunc(func(_ string, v value) bool {
+		return v.created < cutoff
+	})
+}
+
+func (c *cache) DelPrefix(prefix string) int {
+	var n int
+	for _, g := range c.allGroups() {
+		n += g.DelPrefix(prefix)
+	}
+
+	return n
+}
+
+func (c *cache) DelMatch(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, g := range c.allGroups() {
+		deleted, _ := g.DelMatch(pattern)
+		n += deleted
+	}
+
+	return n, nil
+}
+
+func (c *cache) DelOlderThan(age time.Duration) int {
+	var n int
+	for _, g := range c.allGroups() {
+		n += g.DelOlderThan(age)
+	}
+
+	return n
+}
+
+// delFunc removes group values, which satisfy match, and returns
+// number of removed values. Values of shard are checked
+// in batches of delBatch, so other calls aren't blocked for long
+func (g *group) delFunc(match func(key string, v value) bool) int {
+	if g.frozen() {
+		return 0
+	}
+
+	var keys []string
+	for _, s := range g.shards {
+		s.mx.RLock()
+		skeys := make([]string, 0, len(s.values))
+		for key := range s.values {
+			skeys = append(skeys, key)
+		}
+		s.mx.RUnlock()
+
+		for len(skeys) > 0 {
+			n := delBatch
+			if n > len(skeys) {
+				n = len(skeys)
+			}
+
+			s.mx.Lock()
+			for _, key := range skeys[:n] {
+				if v, ok := s.values[key]; ok && match(key, v) {
+					s.remove(key)
+					keys = append(keys, key)
+				}
+			}
+			s.unlock()
+
+			skeys = skeys[n:]
+		}
+	}
+
+	if len(keys) == 0 {
+		return 0
+	}
+
+	for _, key := range keys {
+		g.unpersist(key)
+	}
+
+	g.cache.broadcast(Invalidation{Kind: InvalidateKeys, Group: g.key, Keys: keys})
+
+	return len(keys)
+}
@@ -0,0 +1,126 @@
+package gache
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDelPrefix(t *testing.T) {
+	c := NewCache(WithShards(4))
+	defer c.Close()
+
+	for _, key := range []string{"user:1", "user:2", "user", "item:1"} {
+		c.Set(key, key)
+	}
+
+	if n := c.DelPrefix("user:"); n != 2 {
+		t.Fatalf("expected 2 values removed, got %d", n)
+	}
+	if n := c.Len(); n != 2 {
+		t.Fatalf("expected 2 values left, got %d", n)
+	}
+	if n := c.DelPrefix("missing"); n != 0 {
+		t.Fatalf("expected no values removed, got %d", n)
+	}
+}
+
+func TestDelMatch(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	for _, key := range []string{"user:42:name", "user:42:mail", "user:7:name"} {
+		c.Set(key, key)
+	}
+
+	if n, err := c.DelMatch("user:*:name"); err != nil || n != 2 {
+		t.Fatalf("expected 2 values removed, got %d, %v", n, err)
+	}
+	if _, ok := c.Get("user:42:mail"); !ok {
+		t.Fatal("expected unmatched value kept")
+	}
+
+	if _, err := c.DelMatch("["); !errors.Is(err, path.ErrBadPattern) {
+		t.Fatalf("expected bad pattern error, got %v", err)
+	}
+}
+
+func TestDelFunc(t *testing.T) {
+	c := NewCache(WithShards(2))
+	defer c.Close()
+
+	for i := 0; i < 3*delBatch; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	n := c.DelFunc(func(key string, val interface{}) bool {
+		return val.(int)%2 == 0
+	})
+	if n != 3*delBatch/2 {
+		t.Fatalf("expected %d values removed, got %d", 3*delBatch/2, n)
+	}
+	if _, ok := c.Get("1"); !ok {
+		t.Fatal("expected unmatched value kept")
+	}
+	if _, ok := c.Get("2"); ok {
+		t.Fatal("expected matched value removed")
+	}
+}
+
+func TestDelOlderThan(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	defer c.Close()
+
+	g := c.GetOrCreateGroup("g")
+	c.Set("a", 1)
+	g.Set("a", 1)
+	clock.Advance(time.Hour)
+	c.Set("b", 2)
+	g.Set("b", 2)
+	clock.Advance(time.Minute)
+
+	if n := g.DelOlderThan(30 * time.Minute); n != 1 {
+		t.Fatalf("expected 1 value of group removed, got %d", n)
+	}
+	if n := c.DelOlderThan(30 * time.Minute); n != 1 {
+		t.Fatalf("expected 1 value of root group removed, got %d", n)
+	}
+	if n := c.DelOlderThan(0); n != 2 {
+		t.Fatalf("expected values of all groups removed, got %d", n)
+	}
+}
+
+func TestCacheDelPrefix(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	c.Set("user:1", 1)
+	c.Set("item:1", 1)
+	g := c.GetOrCreateGroup("g")
+	g.Set("user:1", 1)
+	g.Set("user:2", 1)
+
+	if n := c.DelPrefix("user:"); n != 3 {
+		t.Fatalf("expected 3 values removed from all groups, got %d", n)
+	}
+	if !c.Has("item:1") || g.Len() != 0 {
+		t.Fatalf("expected only matching values removed, got %d values", c.TotalLen())
+	}
+
+	c.Set("user:1", 1)
+	g.Set("user:1", 1)
+	g.Set("item:1", 1)
+	if n, err := c.DelMatch("user:*"); err != nil || n != 2 {
+		t.Fatalf("expected 2 values removed from all groups, got %d, %v", n, err)
+	}
+	if !g.Has("item:1") || !c.Has("item:1") {
+		t.Fatal("expected unmatched values kept")
+	}
+
+	if _, err := c.DelMatch("["); !errors.Is(err, path.ErrBadPattern) {
+		t.Fatalf("expected ErrBadPattern, got %v", err)
+	}
+}
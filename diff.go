@@ -0,0 +1,52 @@
+package gache
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Changes presents difference between two views of group.
+// Keys in every list are sorted
+type Changes struct {
+	// Added contains keys of values, which are present
+	// in the second view only
+	Added []string
+	// Removed contains keys of values, which are present
+	// in the first view only
+	Removed []string
+	// Modified contains keys of values, which are present
+	// in both views, but aren't deeply equal
+	Modified []string
+}
+
+// Empty reports whether views have no differences
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// Diff returns changes, which turn values of view a
+// into values of view b. Values are compared with
+// reflect.DeepEqual, metadata of values is ignored
+func Diff(a, b GroupView) Changes {
+	var changes Changes
+	for key, it := range a.items {
+		other, ok := b.items[key]
+		if !ok {
+			changes.Removed = append(changes.Removed, key)
+		} else if !reflect.DeepEqual(it.Value, other.Value) {
+			changes.Modified = append(changes.Modified, key)
+		}
+	}
+
+	for key := range b.items {
+		if _, ok := a.items[key]; !ok {
+			changes.Added = append(changes.Added, key)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Modified)
+
+	return changes
+}
@@ -0,0 +1,30 @@
+package gache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	c.Set("same", []int{1})
+	c.Set("changed", 1)
+	c.Set("removed", 1)
+	a := c.Snapshot()
+
+	if changes := Diff(a, c.Snapshot()); !changes.Empty() {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+
+	c.Set("same", []int{1})
+	c.Set("changed", 2)
+	c.Del("removed")
+	c.Set("added", 1)
+
+	want := Changes{Added: []string{"added"}, Removed: []string{"removed"}, Modified: []string{"changed"}}
+	if changes := Diff(a, c.Snapshot()); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %+v, got %+v", want, changes)
+	}
+}
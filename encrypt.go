@@ -0,0 +1,73 @@
+package gache
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+)
+
+// snapshotMagic starts header of encrypted snapshot,
+// which is followed by format version
+const snapshotMagic = "GACHE"
+
+// snapshotVersion is format version of encrypted snapshots
+const snapshotVersion = 1
+
+// sealSnapshot encrypts data of snapshot with key and returns
+// header, random nonce and ciphertext, authenticating header
+func sealSnapshot(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(snapshotMagic), snapshotVersion)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, len(header)+len(nonce)+len(data)+aead.Overhead())
+	sealed = append(append(sealed, header...), nonce...)
+
+	return aead.Seal(sealed, nonce, data, header), nil
+}
+
+// openSnapshot decrypts data of snapshot sealed by sealSnapshot.
+// Returns ErrBadSnapshot if header or ciphertext is invalid
+func openSnapshot(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := len(snapshotMagic) + 1
+	if len(sealed) < headerSize+aead.NonceSize() ||
+		!bytes.HasPrefix(sealed, []byte(snapshotMagic)) ||
+		sealed[headerSize-1] != snapshotVersion {
+		return nil, ErrBadSnapshot
+	}
+
+	header := sealed[:headerSize]
+	nonce := sealed[headerSize : headerSize+aead.NonceSize()]
+
+	data, err := aead.Open(nil, nonce, sealed[headerSize+aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrBadSnapshot
+	}
+
+	return data, nil
+}
+
+// newAEAD returns AES-GCM cipher with specified key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
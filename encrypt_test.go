@@ -0,0 +1,49 @@
+package gache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptedSnapshot(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	c := NewCache(WithSnapshotEncryption(key))
+	c.Set("pii", "secret-value")
+
+	var buf bytes.Buffer
+	if err := c.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret-value")) {
+		t.Fatal("expected snapshot without plaintext")
+	}
+
+	data := buf.Bytes()
+
+	c = NewCache(WithSnapshotEncryption(key))
+	if err := c.LoadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := c.Get("pii"); v != "secret-value" {
+		t.Fatalf("expected decrypted value, got %v", v)
+	}
+
+	c = NewCache(WithSnapshotEncryption(bytes.Repeat([]byte{2}, 32)))
+	if err := c.LoadFrom(bytes.NewReader(data)); !errors.Is(err, ErrBadSnapshot) {
+		t.Fatalf("expected ErrBadSnapshot for wrong key, got %v", err)
+	}
+
+	tampered := append([]byte(nil), data...)
+	tampered[len(tampered)-1] ^= 1
+	c = NewCache(WithSnapshotEncryption(key))
+	if err := c.LoadFrom(bytes.NewReader(tampered)); !errors.Is(err, ErrBadSnapshot) {
+		t.Fatalf("expected ErrBadSnapshot for tampered snapshot, got %v", err)
+	}
+
+	c = NewCache()
+	if err := c.LoadFrom(bytes.NewReader(data)); err == nil {
+		t.Fatal("expected error for encrypted snapshot without key")
+	}
+}
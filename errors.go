@@ -0,0 +1,81 @@
+package gache
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrGroupExists is returned on creation of group,
+	// which already exists
+	ErrGroupExists = errors.New("gache: group already exists")
+	// ErrGroupNotFound is returned on access to group,
+	// which doesn't exist
+	ErrGroupNotFound = errors.New("gache: group not found")
+	// ErrKeyNotFound is returned on access to value,
+	// which doesn't exist, is expired and can't be filled
+	ErrKeyNotFound = errors.New("gache: key not found")
+	// ErrKeyExists is returned on adding of value,
+	// which already exists and isn't expired
+	ErrKeyExists = errors.New("gache: key already exists")
+	// ErrCacheClosed is returned on use of closed cache
+	ErrCacheClosed = errors.New("gache: cache is closed")
+	// ErrNotInteger is returned on incrementing of value,
+	// which isn't integer
+	ErrNotInteger = errors.New("gache: value is not an integer")
+	// ErrBadSnapshot is returned on loading of encrypted snapshot,
+	// which is malformed, has unknown format version
+	// or fails authentication
+	ErrBadSnapshot = errors.New("gache: bad snapshot")
+	// ErrValueTooLarge is returned on storing of value,
+	// which exceeds maximal value size of group
+	ErrValueTooLarge = errors.New("gache: value is too large")
+	// ErrGroupFrozen is returned on modification of frozen group
+	ErrGroupFrozen = errors.New("gache: group is frozen")
+	// ErrCorruptValue is passed to InvalidValueFunc for value kept
+	// in slab storage or compressed, which can't be read back
+	ErrCorruptValue = errors.New("gache: value can't be unpacked")
+	// ErrFillPanic is passed with EventFillFailed event
+	// for fill, which panicked
+	ErrFillPanic = errors.New("gache: fill panicked")
+	// ErrFillTimeout is passed with EventFillFailed event
+	// for fill, which was abandoned on timeout
+	ErrFillTimeout = errors.New("gache: fill timed out")
+	// ErrBreakerOpen is passed with EventFillFailed event
+	// for fill, which was short-circuited by circuit breaker
+	ErrBreakerOpen = errors.New("gache: circuit breaker is open")
+	// ErrFillRateLimited is passed with EventFillFailed event
+	// for fill, which was rejected by rate limiter
+	ErrFillRateLimited = errors.New("gache: fill rate limit exceeded")
+)
+
+// GroupError presents error of operation with group.
+// It wraps ErrGroupExists or ErrGroupNotFound
+type GroupError struct {
+	Group string
+	Err   error
+}
+
+func (e *GroupError) Error() string {
+	return e.Err.Error() + ": " + strconv.Quote(e.Group)
+}
+
+func (e *GroupError) Unwrap() error {
+	return e.Err
+}
+
+// KeyError presents error of operation with value of group.
+// It wraps ErrKeyNotFound, ErrKeyExists or ErrNotInteger
+type KeyError struct {
+	Group string
+	Key   string
+	Err   error
+}
+
+func (e *KeyError) Error() string {
+	return e.Err.Error() + ": " + strconv.Quote(e.Key) + " in group " + strconv.Quote(e.Group)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,44 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	c := NewCache()
+
+	if err := c.NewGroup("g"); err != nil {
+		t.Fatal(err)
+	}
+
+	var groupErr *GroupError
+	if err := c.NewGroup("g"); !errors.Is(err, ErrGroupExists) || !errors.As(err, &groupErr) || groupErr.Group != "g" {
+		t.Fatalf("expected existing group error, got %v", err)
+	}
+
+	var keyErr *KeyError
+	if _, err := c.GetGroupVal("g", "a"); !errors.Is(err, ErrKeyNotFound) || !errors.As(err, &keyErr) || keyErr.Key != "a" {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+
+	for name, err := range map[string]error{
+		"GetGroupVal":      func() error { _, err := c.GetGroupVal("x", "a"); return err }(),
+		"SetGroupVal":      c.SetGroupVal("x", "a", 1),
+		"DelGroupValMulti": c.DelGroupValMulti("x", "a"),
+		"DelGroup":         c.DelGroup("x"),
+	} {
+		if !errors.Is(err, ErrGroupNotFound) {
+			t.Fatalf("%s: expected missing group error, got %v", name, err)
+		}
+	}
+
+	if err := c.DelGroup("g"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+	if err := c.NewGroup("h"); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected closed cache error, got %v", err)
+	}
+}
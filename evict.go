@@ -0,0 +1,52 @@
+package gache
+
+// EvictFunc presents type of function, intended for
+// handling values removed from cache group.
+// Root group of cache has empty key
+type EvictFunc func(group, key string, val interface{})
+
+// removal presents value removed from group,
+// which is not yet passed to EvictFunc
+type removal struct {
+	key  string
+	data interface{}
+}
+
+func (c *cache) OnEvicted(f EvictFunc) {
+	c.onEvicted.Store(f)
+}
+
+func (c *cache) evictFunc() EvictFunc {
+	f, _ := c.onEvicted.Load().(EvictFunc)
+	return f
+}
+
+// unlock unlocks shard mutex, passes values, removed while
+// it was locked, to EvictFunc of cache and delivers recorded
+// events to watchers and logger
+func (s *shard) unlock() {
+	removed, events, refreshes := s.removed, s.events, s.refreshes
+	s.removed, s.events, s.refreshes = nil, nil, nil
+	s.mx.Unlock()
+
+	for _, j := range refreshes {
+		s.group.cache.startRefresh(j)
+	}
+
+	if len(removed) > 0 {
+		if f := s.group.cache.evictFunc(); f != nil {
+			for _, r := range removed {
+				val, _ := unpack(r.data)
+				f(s.group.key, r.key, val)
+			}
+		}
+	}
+
+	if len(events) > 0 {
+		for i := range events {
+			events[i].Value, _ = unpack(events[i].Value)
+		}
+		s.group.cache.watchers.notify(events)
+		s.group.cache.logEvents(events)
+	}
+}
@@ -0,0 +1,57 @@
+package gache
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestOnEvicted(t *testing.T) {
+	c := NewCache()
+
+	var removed []string
+	c.OnEvicted(func(group, key string, val interface{}) {
+		removed = append(removed, group+"/"+key)
+	})
+
+	c.NewGroup("g", WithMaxEntries(1))
+	c.SetGroupVal("g", "a", 1)
+	c.SetGroupVal("g", "b", 2)
+
+	c.Set("x", 1)
+	c.Del("x")
+	c.SetWithTTL("y", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	c.Get("y")
+
+	c.DelGroup("g")
+
+	want := []string{"g/a", "/x", "/y", "g/b"}
+	if len(removed) != len(want) {
+		t.Fatalf("expected removals %v, got %v", want, removed)
+	}
+	for i := range want {
+		if removed[i] != want[i] {
+			t.Fatalf("expected removals %v, got %v", want, removed)
+		}
+	}
+}
+
+func TestOnEvictedJanitor(t *testing.T) {
+	c := NewCache(WithJanitorInterval(time.Millisecond))
+	defer c.Close()
+
+	removed := make(chan string, 2)
+	c.OnEvicted(func(group, key string, val interface{}) {
+		removed <- key
+	})
+
+	c.SetWithTTL("a", 1, time.Nanosecond)
+	c.SetWithTTL("b", 2, time.Nanosecond)
+
+	keys := []string{<-removed, <-removed}
+	sort.Strings(keys)
+	if keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("expected expired a and b, got %v", keys)
+	}
+}
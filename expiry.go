@@ -0,0 +1,70 @@
+package gache
+
+import "container/heap"
+
+// expiryEntry presents expiring value in expiration index of shard
+type expiryEntry struct {
+	key        string
+	expiration int64
+	index      int
+}
+
+// expiryHeap is min-heap of expiring values by their expiration,
+// which lets janitor find expired values without scanning shard
+type expiryHeap []*expiryEntry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiration < h[j].expiration }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	e := x.(*expiryEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return e
+}
+
+// expire sets expiration of value with specified key
+// and updates expiration index of shard. Pinned value isn't
+// indexed until it is unpinned.
+// Must be called with locked mutex
+func (s *shard) expire(key string, v *value, expiration int64) {
+	v.expiration = expiration
+	if v.pinned {
+		return
+	}
+
+	switch {
+	case expiration == 0 && v.expiry != nil:
+		heap.Remove(&s.expiries, v.expiry.index)
+		v.expiry = nil
+	case expiration == 0:
+	case v.expiry == nil:
+		v.expiry = &expiryEntry{key: key, expiration: expiration}
+		heap.Push(&s.expiries, v.expiry)
+	default:
+		v.expiry.expiration = expiration
+		heap.Fix(&s.expiries, v.expiry.index)
+	}
+}
+
+// unexpire removes value from expiration index of shard.
+// Must be called with locked mutex
+func (s *shard) unexpire(v value) {
+	if v.expiry != nil {
+		heap.Remove(&s.expiries, v.expiry.index)
+	}
+}
@@ -0,0 +1,227 @@
+package gache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock presents clock, which time is moved manually.
+// Its timers and tickers are real ones, tests of them
+// use fakeclock.Clock
+type testClock struct {
+	systemClock
+
+	mx  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Unix(1000, 0)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.now
+}
+
+// Advance moves time of clock forward by d
+func (c *testClock) Advance(d time.Duration) {
+	c.mx.Lock()
+	c.now = c.now.Add(d)
+	c.mx.Unlock()
+}
+
+func TestSetWithTTL(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute))
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+	c.SetWithTTL("d", 4, time.Minute)
+
+	clock.Advance(time.Minute)
+
+	for _, key := range []string{"a", "d"} {
+		if v, ok := c.Get(key); ok {
+			t.Fatalf("expected %s to expire, got %v", key, v)
+		}
+	}
+	for key, want := range map[string]int{"b": 2, "c": 3} {
+		if v, ok := c.Get(key); !ok || v != want {
+			t.Fatalf("expected %s to live with value %d, got %v, %v", key, want, v, ok)
+		}
+	}
+}
+
+func TestSetGroupValWithTTL(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	if err := c.SetGroupValWithTTL("g", "a", 1, time.Hour); err == nil {
+		t.Fatal("expected error for missing group")
+	}
+
+	c.NewGroup("g")
+	if err := c.SetGroupValWithTTL("g", "a", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := c.GetGroupVal("g", "a"); err == nil {
+		t.Fatal("expected group value to expire")
+	}
+}
+
+func TestGetWithExpiration(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Hour), WithFillFunc(func(key string) (interface{}, bool) {
+		return key, true
+	}))
+
+	now := clock.Now()
+	c.SetWithTTL("a", 1, time.Minute)
+	c.SetWithTTL("b", 2, 0)
+
+	if v, exp, ok := c.GetWithExpiration("a"); !ok || v != 1 || !exp.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected value 1 expiring in a minute, got %v, %v, %v", v, exp, ok)
+	}
+	if v, exp, ok := c.GetWithExpiration("b"); !ok || v != 2 || !exp.IsZero() {
+		t.Fatalf("expected value 2 without expiration, got %v, %v, %v", v, exp, ok)
+	}
+	if v, exp, ok := c.GetWithExpiration("c"); !ok || v != "c" || !exp.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected filled value expiring in an hour, got %v, %v, %v", v, exp, ok)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithFillFunc(func(key string) (interface{}, bool) {
+		return key, true
+	}))
+
+	c.SetWithTTL("a", 1, time.Minute)
+	c.Set("b", 2)
+	clock.Advance(time.Second)
+
+	if ttl, ok := c.TTL("a"); !ok || ttl != 59*time.Second {
+		t.Fatalf("expected 59s left, got %v, %v", ttl, ok)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != 0 {
+		t.Fatalf("expected zero TTL of value without expiration, got %v, %v", ttl, ok)
+	}
+	if _, ok := c.TTL("c"); ok {
+		t.Fatal("expected TTL not to fill missing value")
+	}
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	c.NewGroup("g", WithExpiration(time.Minute), WithSlidingExpiration())
+	g, _ := c.Group("g")
+
+	g.Set("a", 1)
+	g.SetWithTTL("b", 2, time.Hour)
+	for i := 0; i < 5; i++ {
+		clock.Advance(45 * time.Second)
+		if _, ok := g.Get("a"); !ok {
+			t.Fatalf("expected accessed value to live after %d reads", i)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := g.Get("a"); ok {
+		t.Fatal("expected idle value to expire")
+	}
+	if ttl, ok := g.TTL("b"); !ok || ttl != time.Hour-time.Minute-225*time.Second {
+		t.Fatalf("expected unread value to keep its expiration, got %v, %v", ttl, ok)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute))
+
+	c.Set("a", 1)
+	clock.Advance(30 * time.Second)
+	if !c.Touch("a", time.Minute) {
+		t.Fatal("expected existing value to be touched")
+	}
+	if c.Touch("missing", time.Minute) {
+		t.Fatal("expected missing value not to be touched")
+	}
+
+	clock.Advance(45 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected touched value to live, got %v, %v", v, ok)
+	}
+
+	clock.Advance(15 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected touched value to expire")
+	}
+	if c.Touch("a", time.Minute) {
+		t.Fatal("expected expired value not to be touched")
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(100*time.Second), WithTTLJitter(0.2))
+	defer c.Close()
+
+	ttls := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprint(i)
+		c.Set(key, i)
+
+		ttl, ok := c.TTL(key)
+		if !ok || ttl < 80*time.Second || ttl > 120*time.Second {
+			t.Fatalf("expected TTL within jitter, got %v, %v", ttl, ok)
+		}
+		ttls[ttl] = true
+	}
+	if len(ttls) < 2 {
+		t.Fatal("expected randomized TTLs")
+	}
+
+	c.SetWithTTL("forever", 1, 0)
+	if ttl, ok := c.TTL("forever"); !ok || ttl != 0 {
+		t.Fatalf("expected value without expiration kept, got %v, %v", ttl, ok)
+	}
+}
+
+func TestSetExpirationApplyToExisting(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1)
+	clock.Advance(time.Minute)
+	c.Set("b", 2)
+
+	c.SetExpiration(10 * time.Minute)
+	if ttl, _ := c.TTL("a"); ttl != 59*time.Minute {
+		t.Fatalf("expected existing value untouched by default, got %v", ttl)
+	}
+
+	c.SetExpiration(10*time.Minute, ApplyToExisting)
+	if ttl, _ := c.TTL("a"); ttl != 9*time.Minute {
+		t.Fatalf("expected expiration counted from store time, got %v", ttl)
+	}
+	if ttl, _ := c.TTL("b"); ttl != 10*time.Minute {
+		t.Fatalf("expected expiration counted from store time, got %v", ttl)
+	}
+
+	clock.Advance(9 * time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected re-stamped value to expire")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("expected re-stamped value to live")
+	}
+}
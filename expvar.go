@@ -0,0 +1,18 @@
+package gache
+
+import "expvar"
+
+// PublishExpvar publishes live statistics of cache under
+// specified name, so they are served by /debug/vars.
+// Published value contains aggregated statistics of cache
+// as "total" and statistics of every group by its key
+// as "groups". Like expvar.Publish, it panics if name
+// is already registered
+func PublishExpvar(name string, c Cache) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]interface{}{
+			"total":  c.Stats(),
+			"groups": c.GroupStats(),
+		}
+	}))
+}
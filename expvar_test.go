@@ -0,0 +1,26 @@
+package gache
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g")
+	c.SetGroupVal("g", "a", 1)
+
+	PublishExpvar("gache_test", c)
+
+	var stats struct {
+		Total  Stats
+		Groups map[string]Stats
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("gache_test").String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total.Items != 1 || stats.Groups["g"].Items != 1 {
+		t.Fatalf("expected published statistics, got %+v", stats)
+	}
+}
@@ -0,0 +1,152 @@
+// Package fakeclock provides gache.Clock, which time is moved
+// manually, so expiration and other time-dependent behavior
+// of cache can be tested without sleeps
+package fakeclock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+// Clock is gache.Clock, which time stands still until Advance
+// or Set is called. Timers and tickers fire, when time reaches
+// their deadlines. Clock is safe for concurrent use
+type Clock struct {
+	mx      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter presents pending timer or ticker
+type waiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+// New returns clock, which shows specified time
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns current time of clock
+func (c *Clock) Now() time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.now
+}
+
+// After returns channel, which receives time of clock,
+// once it is advanced by d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	w := &waiter{deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w.ch
+	}
+
+	c.waiters = append(c.waiters, w)
+
+	return w.ch
+}
+
+// NewTicker returns ticker, which sends time of clock
+// every time it is advanced by period d. As time.Ticker
+// does, it drops ticks for slow receivers
+func (c *Clock) NewTicker(d time.Duration) gache.Ticker {
+	if d <= 0 {
+		panic("fakeclock: non-positive interval for NewTicker")
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	w := &waiter{deadline: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, w)
+
+	return &ticker{clock: c, waiter: w}
+}
+
+// Advance moves time of clock forward by d
+// and fires timers and tickers, which became due
+func (c *Clock) Advance(d time.Duration) {
+	c.mx.Lock()
+	c.set(c.now.Add(d))
+	c.mx.Unlock()
+}
+
+// Set moves time of clock to now and fires timers
+// and tickers, which became due
+func (c *Clock) Set(now time.Time) {
+	c.mx.Lock()
+	c.set(now)
+	c.mx.Unlock()
+}
+
+// Waiters returns number of pending timers and tickers. It helps
+// to wait in tests until goroutine starts waiting for clock
+func (c *Clock) Waiters() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return len(c.waiters)
+}
+
+// set moves time of clock and fires due waiters.
+// Must be called with locked mutex
+func (c *Clock) set(now time.Time) {
+	c.now = now
+
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(now) {
+			waiters = append(waiters, w)
+			continue
+		}
+
+		select {
+		case w.ch <- now:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.deadline.After(now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			waiters = append(waiters, w)
+		}
+	}
+	c.waiters = waiters
+}
+
+// remove drops waiter, so it never fires
+func (c *Clock) remove(w *waiter) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for i, cw := range c.waiters {
+		if cw == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+type ticker struct {
+	clock  *Clock
+	waiter *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+func (t *ticker) Stop() {
+	t.clock.remove(t.waiter)
+}
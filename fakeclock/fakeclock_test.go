@@ -0,0 +1,85 @@
+package fakeclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAfter(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := New(start)
+
+	ch := c.After(time.Minute)
+	c.Advance(59 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("expected timer not to fire before deadline")
+	default:
+	}
+
+	c.Advance(time.Second)
+	select {
+	case now := <-ch:
+		if !now.Equal(start.Add(time.Minute)) {
+			t.Fatalf("expected time of deadline, got %v", now)
+		}
+	default:
+		t.Fatal("expected timer to fire at deadline")
+	}
+
+	if n := c.Waiters(); n != 0 {
+		t.Fatalf("expected no waiters after firing, got %d", n)
+	}
+
+	select {
+	case <-c.After(0):
+	default:
+		t.Fatal("expected zero timer to fire immediately")
+	}
+}
+
+func TestTicker(t *testing.T) {
+	c := New(time.Unix(1000, 0))
+
+	tk := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	<-tk.C()
+
+	// slow receiver misses ticks
+	c.Advance(3 * time.Second)
+	c.Advance(time.Second)
+	<-tk.C()
+	select {
+	case <-tk.C():
+		t.Fatal("expected dropped ticks")
+	default:
+	}
+
+	tk.Stop()
+	if n := c.Waiters(); n != 0 {
+		t.Fatalf("expected no waiters after stop, got %d", n)
+	}
+
+	c.Advance(time.Second)
+	select {
+	case <-tk.C():
+		t.Fatal("expected stopped ticker not to tick")
+	default:
+	}
+}
+
+func TestSet(t *testing.T) {
+	c := New(time.Unix(1000, 0))
+	ch := c.After(time.Hour)
+
+	c.Set(time.Unix(1000, 0).Add(2 * time.Hour))
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected timer to fire after time is set past deadline")
+	}
+
+	if now := c.Now(); !now.Equal(time.Unix(1000+7200, 0)) {
+		t.Fatalf("expected set time, got %v", now)
+	}
+}
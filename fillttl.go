@@ -0,0 +1,45 @@
+package gache
+
+import (
+	"context"
+	"time"
+)
+
+// FillFuncTTL presents type of function, intended for filling
+// group value by key, which also returns live duration of value.
+// Zero or negative ttl means expiration of group
+type FillFuncTTL func(key string) (val interface{}, ttl time.Duration, ok bool)
+
+// withContext adapts filling function with live duration
+// to FillFuncCtx, which reports the duration by SetFillTTL
+func (f FillFuncTTL) withContext() FillFuncCtx {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, key string) (interface{}, bool) {
+		val, ttl, ok := f(key)
+		SetFillTTL(ctx, ttl)
+		return val, ok
+	}
+}
+
+func (g *group) SetFillFuncTTL(fillFunc FillFuncTTL) {
+	g.SetFillFuncCtx(fillFunc.withContext())
+}
+
+type fillTTLKey struct{}
+
+// fillTTL holds live duration of value reported by filling function
+type fillTTL struct {
+	ttl time.Duration
+}
+
+// SetFillTTL sets live duration of value, which is being filled by
+// context-aware filling function called with ctx. Zero or negative
+// ttl means expiration of group. Outside filling function it does nothing
+func SetFillTTL(ctx context.Context, ttl time.Duration) {
+	if t, _ := ctx.Value(fillTTLKey{}).(*fillTTL); t != nil {
+		t.ttl = ttl
+	}
+}
@@ -0,0 +1,46 @@
+package gache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFillFuncTTL(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithExpiration(time.Hour), WithFillFuncTTL(func(key string) (interface{}, time.Duration, bool) {
+		if key == "short" {
+			return key, time.Minute, true
+		}
+		return key, 0, true
+	}))
+	g, _ := c.Group("g")
+
+	g.Get("short")
+	if ttl, ok := g.TTL("short"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected TTL from filling function, got %v, %v", ttl, ok)
+	}
+
+	g.Get("default")
+	if ttl, ok := g.TTL("default"); !ok || ttl <= time.Minute {
+		t.Fatalf("expected expiration of group, got %v, %v", ttl, ok)
+	}
+}
+
+func TestSetFillTTL(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	c.SetFillFuncCtx(func(ctx context.Context, key string) (interface{}, bool) {
+		SetFillTTL(ctx, time.Minute)
+		return key, true
+	})
+	c.Get("a")
+	if ttl, ok := c.TTL("a"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected TTL set by filling function, got %v, %v", ttl, ok)
+	}
+
+	// outside filling function TTL is ignored
+	SetFillTTL(context.Background(), time.Minute)
+}
@@ -0,0 +1,20 @@
+package gache
+
+import "sync/atomic"
+
+func (g *group) Freeze() {
+	atomic.StoreInt32(&g.freeze, 1)
+}
+
+func (g *group) Unfreeze() {
+	atomic.StoreInt32(&g.freeze, 0)
+}
+
+func (g *group) Frozen() bool {
+	return g.frozen()
+}
+
+// frozen reports whether group is frozen
+func (g *group) frozen() bool {
+	return atomic.LoadInt32(&g.freeze) != 0
+}
@@ -0,0 +1,77 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		return key, true
+	}))
+	g, _ := c.Group("g")
+
+	g.Set("a", 1)
+	g.Freeze()
+	if !g.Frozen() {
+		t.Fatal("expected frozen group")
+	}
+
+	g.Set("a", 2)
+	g.Set("b", 2)
+	g.Del("a")
+	g.Clear()
+	if _, err := g.Increment("n", 1); !errors.Is(err, ErrGroupFrozen) {
+		t.Fatalf("expected ErrGroupFrozen, got %v", err)
+	}
+	if err := g.Add("c", 1); !errors.Is(err, ErrGroupFrozen) {
+		t.Fatalf("expected ErrGroupFrozen, got %v", err)
+	}
+	if _, ok := g.GetAndDelete("a"); ok {
+		t.Fatal("expected value not deleted from frozen group")
+	}
+	if v, ok := g.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected value served by frozen group, got %v, %v", v, ok)
+	}
+	if _, ok := g.Get("d"); ok {
+		t.Fatal("expected missing value not filled in frozen group")
+	}
+	if n := g.Len(); n != 1 {
+		t.Fatalf("expected frozen group unchanged, got %d values", n)
+	}
+
+	g.Unfreeze()
+	g.Set("b", 2)
+	if v, ok := g.Get("b"); !ok || v != 2 {
+		t.Fatalf("expected unfrozen group writable, got %v, %v", v, ok)
+	}
+
+	// deleted frozen group is cleared
+	g.Freeze()
+	c.DelGroup("g")
+	if n := g.Len(); n != 0 {
+		t.Fatalf("expected values of deleted frozen group removed, got %d", n)
+	}
+}
+
+func TestFreezeGetMulti(t *testing.T) {
+	l2 := &batchMapStore{mapStore: newMapStore()}
+	l2.vals["/a"] = 1
+
+	var fills int
+	c := NewTieredCache(l2, WithBatchFillFunc(func(keys []string) map[string]interface{} {
+		fills++
+		return nil
+	}))
+	t.Cleanup(func() { c.Close() })
+
+	c.Freeze()
+	if vals := c.GetMulti([]string{"a", "b"}); len(vals) != 0 {
+		t.Fatalf("expected no values in frozen group, got %v", vals)
+	}
+	if l2.batches != 0 || fills != 0 {
+		t.Fatalf("expected frozen group not loaded or filled, got %d loads and %d fills", l2.batches, fills)
+	}
+}
@@ -1,204 +1,1236 @@
 package gache
 
 import (
-	"fmt"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Cache presents interface of cache objects
+// Cache presents interface of cache objects. Methods of Group,
+// called on cache, operate on its root group only, unless cache
+// overrides them, as DelOlderThan does
 type Cache interface {
 	Group
 	// Group returns group with specified key
 	Group(key string) (Group, bool)
-	// NewGroup creates new group with specified key,
-	// item live duration and filling function
-	NewGroup(key string, expiration time.Duration, fillFunc FillFunc) error
-	// DelGroup deletes group with specified key
-	DelGroup(key string)
+	// NewGroup creates new group with specified key and options.
+	// Returns ErrGroupExists if group already exists
+	// and ErrCacheClosed if cache is closed
+	NewGroup(key string, opts ...GroupOption) error
+	// NewGroupWithTTL creates new group with specified key and options
+	// like NewGroup. Group is deleted with its values and subgroups
+	// after ttl passes, unless it was deleted before
+	NewGroupWithTTL(key string, ttl time.Duration, opts ...GroupOption) error
+	// GetOrCreateGroup returns existing group with specified key
+	// or atomically creates it with specified options, which
+	// are ignored for existing group
+	GetOrCreateGroup(key string, opts ...GroupOption) Group
+	// SetGroupDefaults replaces options, which groups created
+	// afterwards are configured with before their own options.
+	// If cascade is true, expiration, filling functions and
+	// refresh policy, changed by opts from their defaults,
+	// are applied to existing groups too. Limits, eviction
+	// policy and other settings of storage affect only new groups
+	SetGroupDefaults(cascade bool, opts ...GroupOption)
+	// RenameGroup atomically replaces group with key newKey,
+	// if it exists, by group with key oldKey, which settings and
+	// unexpired values are moved to it. Subgroups and values in
+	// store of cache aren't moved. Returns ErrGroupNotFound if
+	// group with key oldKey doesn't exist
+	RenameGroup(oldKey, newKey string) error
+	// CloneGroup creates group with key dst with settings and
+	// unexpired values of group with key src, which keep their
+	// remaining live durations. Returns ErrGroupNotFound if group
+	// with key src doesn't exist and ErrGroupExists if group
+	// with key dst already exists
+	CloneGroup(src, dst string) error
+	// DelGroup deletes group with specified key and all its subgroups.
+	// Returns ErrGroupNotFound if neither group nor its subgroups exist
+	// and ErrCacheClosed if cache is closed
+	DelGroup(key string) error
+	// Flush removes values from all groups of cache.
+	// If deleteGroups is true, groups are deleted as well
+	Flush(deleteGroups bool)
+	// DelOlderThan removes from all groups of cache values,
+	// which were stored earlier than age ago,
+	// and returns number of removed values
+	DelOlderThan(age time.Duration) int
+	// DelPrefix removes from all groups of cache values, which
+	// keys start with prefix, and returns number of removed values
+	DelPrefix(prefix string) int
+	// DelMatch removes from all groups of cache values, which keys
+	// match shell pattern, and returns number of removed values.
+	// Returns path.ErrBadPattern if pattern is malformed
+	DelMatch(pattern string) (int, error)
 	// GetGroupVal returns value with specified vkey
-	// from cache group with specified gkey
-	GetGroupVal(gkey, vkey string) (interface{}, bool)
+	// from cache group with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
+	// and ErrKeyNotFound if value isn't found
+	GetGroupVal(gkey, vkey string) (interface{}, error)
+	// HasGroupVal reports whether cache group with specified gkey
+	// has unexpired value with vkey without filling it.
+	// Returns false if group doesn't exist
+	HasGroupVal(gkey, vkey string) bool
 	// SetGroupVal sets value with vkey as item of cache group
-	// with specified gkey
+	// with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
 	SetGroupVal(gkey, vkey string, val interface{}) error
+	// SetGroupValWithTTL sets value with vkey and its own
+	// live duration as item of cache group with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
+	SetGroupValWithTTL(gkey, vkey string, val interface{}, ttl time.Duration) error
+	// Groups returns sorted keys of cache groups
+	Groups() []string
+	// TotalLen returns number of unexpired values
+	// in all groups of cache
+	TotalLen() int
+	// GetGroupValMulti returns values with specified vkeys,
+	// which were found in cache group with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
+	GetGroupValMulti(gkey string, vkeys []string) (map[string]interface{}, error)
+	// SetGroupValMulti sets values as items of cache group
+	// with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
+	SetGroupValMulti(gkey string, vals map[string]interface{}) error
+	// DelGroupValMulti removes values with specified vkeys
+	// from cache group with specified gkey.
+	// Returns ErrGroupNotFound if group doesn't exist
+	DelGroupValMulti(gkey string, vkeys ...string) error
+	// WrapFills adds wrappers, which decorate filling
+	// functions of all cache groups
+	WrapFills(wrappers ...FillWrapper)
+	// WrapBatchFills adds wrappers, which decorate batch
+	// filling functions of all cache groups
+	WrapBatchFills(wrappers ...BatchFillWrapper)
+	// Use adds middlewares, which decorate groups returned by Group,
+	// GetOrCreateGroup and Subgroup methods and are used by methods
+	// accessing values of groups by their keys, e.g. GetGroupVal.
+	// Methods of Group called on cache itself aren't decorated
+	Use(mw ...GroupMiddleware)
+	// RegisterPeers sets pool of cache nodes, which owners
+	// of values are fetched from instead of filling them locally
+	RegisterPeers(picker PeerPicker)
+	// Subscribe returns channel with specified buffer size, which
+	// delivers all events of cache, and function, which stops
+	// subscription and closes the channel. Events are delivered
+	// without blocking, so they are dropped, if receiver falls behind
+	Subscribe(buffer int) (<-chan Event, func())
+	// OnEvicted sets function, which will be called for values
+	// removed from cache groups by expiration, eviction,
+	// explicit deletion or deletion of their group
+	OnEvicted(f EvictFunc)
+	// OnGroupEvicted sets function, which will be called with key
+	// of group deleted on exceeding limit of number of groups
+	OnGroupEvicted(f func(key string))
+	// OnFillPanic sets function, which will be called for panics
+	// of filling functions. Panicking fill is treated as miss
+	OnFillPanic(f FillPanicFunc)
+	// OnInvalidValue sets function, which will be called for values
+	// rejected by validators of groups, e.g. for logging them
+	OnInvalidValue(f InvalidValueFunc)
+	// SaveTo writes snapshot of all groups and their unexpired
+	// values with remaining live durations to w
+	SaveTo(w io.Writer) error
+	// LoadFrom reads snapshot written by SaveTo from r
+	// and puts its values into cache, creating missing groups.
+	// Groups without filling function get the one registered
+	// with name saved in snapshot, see Group.SetNamedFillFunc
+	LoadFrom(r io.Reader) error
+	// Close stops background goroutines of cache, flushes
+	// pending writes to its store and writes final snapshot,
+	// if cache saves snapshots automatically. Afterwards methods, which
+	// return error, return ErrCacheClosed, except SaveTo,
+	// so contents of closed cache may still be saved.
+	// Returns ErrCacheClosed if cache is already closed
+	Close() error
+	// Stats returns statistics of cache,
+	// aggregated over all its groups
+	Stats() Stats
+	// MemoryUsage returns estimated number of bytes
+	// taken by values of all cache groups
+	MemoryUsage() int64
+	// GroupStats returns statistics of every cache group
+	// by its key. Root group has empty key
+	GroupStats() map[string]Stats
+	// RefreshPoolStats returns statistics of refresh pool of cache.
+	// Zero statistics are returned, if cache has no refresh pool
+	RefreshPoolStats() RefreshPoolStats
+	// Quotas returns limits and their utilization of every
+	// cache group by its key. Root group has empty key
+	Quotas() map[string]Quota
+	// Merge stores values of other cache in groups with the same
+	// keys, creating missing groups. Values keep their remaining
+	// live durations, conflicts with existing values are resolved
+	// by policy. Frozen groups aren't changed
+	Merge(other Cache, policy ConflictPolicy) error
 }
 
 // Group presents interface of cache group
 type Group interface {
 	// Get returns value with specified key
 	Get(key string) (interface{}, bool)
+	// GetCtx returns value with specified key,
+	// passing ctx to filling function
+	GetCtx(ctx context.Context, key string) (interface{}, bool)
 	// Set sets value for specified key
 	Set(key string, val interface{})
+	// Touch resets expiration of existing value with specified key
+	// to ttl from now without changing the value.
+	// Zero or negative ttl means value never expires.
+	// Returns false if value is absent or expired
+	Touch(key string, ttl time.Duration) bool
+	// SetWithCost sets value for specified key with its cost,
+	// which is accounted against cost limit of group
+	SetWithCost(key string, val interface{}, cost int64)
+	// SetWithTTL sets value for specified key with its own
+	// live duration, overriding group expiration.
+	// Zero or negative ttl means value never expires
+	SetWithTTL(key string, val interface{}, ttl time.Duration)
+	// GetStale returns value with specified key like Get,
+	// and reports whether returned value is expired one,
+	// served stale or because its refilling failed
+	GetStale(key string) (val interface{}, stale bool, ok bool)
+	// GetItem returns unexpired value with specified key and its
+	// metadata without filling it or accounting access to it
+	GetItem(key string) (Item, bool)
+	// GetWithExpiration returns value with specified key
+	// and its expiration time. Zero time means value never expires
+	GetWithExpiration(key string) (interface{}, time.Time, bool)
+	// TTL returns remaining live duration of value with specified key
+	// without filling it. Zero duration means value never expires
+	TTL(key string) (time.Duration, bool)
+	// Peek returns unexpired value with specified key without
+	// filling it, marking it as recently used
+	// or extending its sliding expiration
+	Peek(key string) (interface{}, bool)
+	// Has reports whether group has unexpired value
+	// with specified key without filling it
+	Has(key string) bool
+	// Add sets value for specified key, if value is absent or expired.
+	// Returns ErrKeyExists otherwise
+	Add(key string, val interface{}) error
+	// Replace sets value for specified key, if unexpired value exists.
+	// Returns ErrKeyNotFound otherwise. Missing value isn't filled
+	Replace(key string, val interface{}) error
+	// AddWithTTL sets value like Add with its own live duration,
+	// so value isn't visible without it. Zero or negative ttl
+	// means value never expires, as for SetWithTTL
+	AddWithTTL(key string, val interface{}, ttl time.Duration) error
+	// ReplaceWithTTL sets value like Replace
+	// with its own live duration like AddWithTTL
+	ReplaceWithTTL(key string, val interface{}, ttl time.Duration) error
+	// GetOrSet returns existing value with specified key
+	// and true, or sets val for the key and returns it with false
+	GetOrSet(key string, val interface{}) (interface{}, bool)
+	// GetOrCompute returns existing value with specified key,
+	// or sets value computed by compute function for the key.
+	// Concurrent calls and Get calls for the same key wait for
+	// single computation. Computing doesn't block access to other
+	// keys, but compute must not access value with the same key.
+	// Returns false if value neither exists nor was computed
+	GetOrCompute(key string, compute func() (interface{}, bool)) (interface{}, bool)
+	// Update atomically replaces value with specified key by value,
+	// returned by fn for existing value, which isn't filled.
+	// If fn returns false, value isn't changed. New value is set
+	// as by Set. Fn is called under lock, so it must not access group
+	Update(key string, fn func(old interface{}, exists bool) (interface{}, bool))
+	// Increment atomically adds delta to integer value with specified
+	// key and returns result. Absent value is created at zero with
+	// expiration of group, existing one keeps its expiration.
+	// Type of existing value is kept, so result may overflow it.
+	// Returns ErrNotInteger if value isn't integer and error of
+	// validation, if result is rejected by group, which keeps old value
+	Increment(key string, delta int64) (int64, error)
+	// Decrement atomically subtracts delta from integer
+	// value with specified key like Increment
+	Decrement(key string, delta int64) (int64, error)
+	// GetWithVersion returns value with specified key like Get
+	// and its version, which changes on every write of the value
+	GetWithVersion(key string) (interface{}, uint64, bool)
+	// SetIfVersion sets value for specified key, if existing value
+	// has specified version, so concurrent writers may update value
+	// without external locking. Returns false if value is absent,
+	// expired or was written since version was obtained
+	SetIfVersion(key string, val interface{}, version uint64) bool
 	// Del removes from group value with specified key
 	Del(key string)
-	// SetExpiration sets live duration for group values
-	SetExpiration(expiration time.Duration)
+	// GetAndDelete removes from group value with specified key
+	// and returns it. Missing value isn't filled
+	GetAndDelete(key string) (interface{}, bool)
+	// PopOldest removes from group value, which was stored
+	// earlier than others, and returns it with its key.
+	// Values stored at the same time are popped in order of keys
+	PopOldest() (string, interface{}, bool)
+	// PopRandom removes from group random value
+	// and returns it with its key
+	PopRandom() (string, interface{}, bool)
+	// DelPrefix removes from group values, which keys start
+	// with prefix, and returns number of removed values
+	DelPrefix(prefix string) int
+	// DelMatch removes from group values, which keys match
+	// shell pattern, e.g. "user:42:*", and returns number of
+	// removed values. Pattern syntax is the one of path.Match.
+	// Returns path.ErrBadPattern if pattern is malformed
+	DelMatch(pattern string) (int, error)
+	// DelFunc removes from group values, for which match returns
+	// true, and returns number of removed values. Values are
+	// checked in batches under lock of their shard, so match must
+	// not call methods of group
+	DelFunc(match func(key string, val interface{}) bool) int
+	// DelOlderThan removes from group values, which were stored
+	// earlier than age ago, and returns number of removed values
+	DelOlderThan(age time.Duration) int
+	// Watch returns channel, which delivers events of value with
+	// specified key, and function, which stops watching and closes
+	// the channel. Events are delivered without blocking, so they
+	// are dropped, if receiver falls behind
+	Watch(key string) (<-chan Event, func())
+	// WatchGroup returns channel, which delivers events of all group
+	// values and of group itself, and function, which stops watching
+	// and closes the channel. Events are delivered as by Watch
+	WatchGroup() (<-chan Event, func())
+	// Pin protects existing value with specified key from eviction
+	// and expiration, so it is removed only by explicit deletion.
+	// Returns false if value is absent or expired
+	Pin(key string) bool
+	// Unpin makes pinned value with specified key evictable again.
+	// Value expires by its expiration, which may already be passed.
+	// Returns false if value is absent or isn't pinned
+	Unpin(key string) bool
+	// Subgroup returns subgroup of group with specified key or
+	// creates it with specified options. Key of subgroup in cache
+	// is path of its ancestors, joined by GroupSeparator, e.g. "a/b",
+	// so it may be accessed by that key. Subgroups of root group
+	// are top-level groups
+	Subgroup(key string, opts ...GroupOption) Group
+	// Warm concurrently loads values with specified keys, which
+	// are missing in group, as Get does, so hot values may be
+	// preloaded. Returns error of ctx, if it is done before
+	// all values are loaded
+	Warm(ctx context.Context, keys []string, opts ...WarmOption) error
+	// GetMulti returns values with specified keys,
+	// which were found or filled in group.
+	// Missing values are filled by batch filling function
+	// at once, if it is set, or by filling function one by one
+	GetMulti(keys []string) map[string]interface{}
+	// SetMulti sets values for their keys
+	SetMulti(vals map[string]interface{})
+	// Import sets values for their keys with live duration ttl,
+	// so group may be bulk-loaded from fixtures.
+	// Zero or negative ttl means values never expire
+	Import(vals map[string]interface{}, ttl time.Duration)
+	// DelMulti removes from group values with specified keys
+	DelMulti(keys ...string)
+	// Clear removes all values from group
+	Clear()
+	// Keys returns keys of unexpired group values
+	Keys() []string
+	// Len returns number of unexpired group values
+	Len() int
+	// Range calls f for snapshot of unexpired group values,
+	// taken before first call. If f returns false, range stops
+	Range(f func(key string, val interface{}) bool)
+	// Snapshot returns immutable view of unexpired group values,
+	// taken at once across all shards of group
+	Snapshot() GroupView
+	// SetExpiration sets live duration for group values.
+	// By default it affects values stored afterwards,
+	// ApplyToExisting mode makes it affect existing values too
+	SetExpiration(expiration time.Duration, modes ...ExpirationMode)
 	// SetFillFunc sets function,
 	// which will be used for filling key value,
 	// if it was expired or not found in group
 	SetFillFunc(fillFunc FillFunc)
+	// SetFillFuncCtx sets context-aware function,
+	// which will be used for filling key value,
+	// if it was expired or not found in group
+	SetFillFuncCtx(fillFunc FillFuncCtx)
+	// SetFillFuncTTL sets function, which will be used for filling
+	// key value with its own live duration, if it was expired
+	// or not found in group
+	SetFillFuncTTL(fillFunc FillFuncTTL)
+	// SetNamedFillFunc sets filling function registered with
+	// specified name by RegisterFillFunc. The name is saved in
+	// snapshots of cache, so group restored from them gets the
+	// function too. Returns false if name isn't registered
+	SetNamedFillFunc(name string) bool
+	// SetBatchFillFunc sets function, which will be used
+	// for filling multiple values missing in GetMulti at once
+	SetBatchFillFunc(batchFillFunc BatchFillFunc)
+	// SetRefreshPolicy sets way of refreshing expired values.
+	// With StaleWhileRevalidate policy expired value is served
+	// during staleTTL after its expiration, zero staleTTL
+	// means no limit
+	SetRefreshPolicy(policy RefreshPolicy, staleTTL time.Duration)
+	// Stats returns statistics of group
+	Stats() Stats
+	// MemoryUsage returns estimated number of bytes taken by
+	// values of group: their keys, bookkeeping data and values
+	// themselves, which sizes are taken from Coster of group,
+	// if it is set, or computed by traversing them
+	MemoryUsage() int64
+	// HotKeys returns up to n most frequently accessed keys of group
+	// in descending order of access counts, which are estimated
+	// by sampling Get calls. Negative n means all tracked keys.
+	// Nil is returned, if group doesn't track hot keys
+	HotKeys(n int) []HotKey
+	// Freeze makes group read-only: methods, which modify values,
+	// don't change them and return ErrGroupFrozen or report failure,
+	// if they return error or result, and missing values aren't
+	// filled. Values are still served and may expire or be evicted
+	Freeze()
+	// Unfreeze makes frozen group writable again
+	Unfreeze()
+	// Frozen reports whether group is frozen
+	Frozen() bool
+	// SetQuota sets limits of number and total cost of group
+	// values, as WithMaxEntries and WithMaxCost do. Values exceeding
+	// new limits are evicted. Zero or negative limit means no limit
+	SetQuota(maxEntries int, maxCost int64)
+	// Quota returns limits of group and their utilization
+	Quota() Quota
 }
 
 // FillFunc presents type of function, intended for
 // filling group value by key
 type FillFunc func(key string) (interface{}, bool)
 
+// FillFuncCtx presents type of function, intended for
+// filling group value by key with respect to context
+type FillFuncCtx func(ctx context.Context, key string) (interface{}, bool)
+
+// withContext adapts legacy filling function to FillFuncCtx
+func (f FillFunc) withContext() FillFuncCtx {
+	if f == nil {
+		return nil
+	}
+
+	return func(_ context.Context, key string) (interface{}, bool) {
+		return f(key)
+	}
+}
+
 type cache struct {
 	*group
-	groups map[string]*group
+	mx                sync.RWMutex
+	groups            map[string]*group
+	janitorInterval   time.Duration
+	pressure          *pressure
+	refreshes         *refreshPool
+	logger            *slog.Logger
+	maxGroups         int
+	onGroupEvicted    atomic.Value
+	codec             Codec
+	clock             Clock
+	onEvicted         atomic.Value
+	onFillPanic       atomic.Value
+	onInvalidValue    atomic.Value
+	watchers          watchers
+	peers             atomic.Value
+	fillWrappers      []FillWrapper
+	batchFillWrappers []BatchFillWrapper
+	middlewares       []GroupMiddleware
+	store             Store
+	storeErrorHandler func(err error)
+	invalidator       Invalidator
+	id                string
+	stop              chan struct{}
+	closeOnce         sync.Once
+	groupDefaults     []GroupOption
+	snapshotKey       []byte
+	autoSnapshot      *autoSnapshot
+	wal               *wal
+	interner          *interner
+
+	snapshotErrorHandler func(err error)
+
+	invalidationErrorHandler func(err error)
 }
 
-// NewCache returns new cache object with specified
-// key live duration and filling function
-func NewCache(expiration time.Duration, fillFunc FillFunc) Cache {
-	if expiration < 0 {
-		expiration = 0
+// NewCache returns new cache object with specified options.
+// Group options configure root group of cache
+func NewCache(opts ...Option) Cache {
+	c := &cache{
+		groups: make(map[string]*group),
+		codec:  GobCodec{},
+		clock:  systemClock{},
+		id:     newInstanceID(),
+		stop:   make(chan struct{}),
 	}
+	c.group = defaultGroup(c, "")
 
-	return &cache{
-		group: &group{
-			values:     make(map[string]value),
-			fillFunc:   fillFunc,
-			expiration: expiration,
-		},
-		groups: make(map[string]*group),
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	c.group.init()
+	c.subscribe()
+
+	if c.autoSnapshot != nil {
+		if err := c.loadSnapshot(c.autoSnapshot.path); err != nil {
+			c.snapshotError(err)
+		}
+		go c.runAutoSnapshot()
+	}
+
+	if c.wal != nil {
+		if err := c.replayWAL(); err != nil {
+			c.snapshotError(err)
+		}
+	}
+
+	if c.janitorInterval > 0 {
+		go c.janitor()
+	}
+
+	if c.pressure != nil {
+		go c.watchPressure()
 	}
+
+	if c.refreshes != nil {
+		for i := 0; i < c.refreshes.workers; i++ {
+			go c.refreshes.run(c.stop)
+		}
+	}
+
+	return c
 }
 
 func (c *cache) Group(key string) (Group, bool) {
-	c.mx.Lock()
+	c.mx.RLock()
 	g, ok := c.groups[key]
-	c.mx.Unlock()
+	c.mx.RUnlock()
+
+	if !ok {
+		return g, false
+	}
+
+	g.markAccess()
+
+	return c.wrapGroup(g), true
+}
+
+func (c *cache) NewGroup(key string, opts ...GroupOption) error {
+	if _, err := c.newGroup(key, opts...); err != nil {
+		return err
+	}
+
+	c.evictGroups()
 
-	return g, ok
+	return nil
 }
 
-func (c *cache) NewGroup(key string, expiration time.Duration, fillFunc FillFunc) error {
+func (c *cache) NewGroupWithTTL(key string, ttl time.Duration, opts ...GroupOption) error {
+	g, err := c.newGroup(key, opts...)
+	if err != nil {
+		return err
+	}
+
+	c.evictGroups()
+
+	go func() {
+		select {
+		case <-c.clock.After(ttl):
+			c.delGroupOf(key, g)
+		case <-c.stop:
+		}
+	}()
+
+	return nil
+}
+
+// newGroup creates and returns new group with specified key
+// and options. Returns ErrGroupExists if group already exists
+// and ErrCacheClosed if cache is closed
+func (c *cache) newGroup(key string, opts ...GroupOption) (*group, error) {
+	if c.closed() {
+		return nil, ErrCacheClosed
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
 	if _, exists := c.groups[key]; exists {
-		return fmt.Errorf("group with key %q already exists", key)
+		return nil, &GroupError{Group: key, Err: ErrGroupExists}
 	}
 
-	if expiration < 0 {
-		expiration = 0
+	g := newGroup(c, key, opts...)
+	c.groups[key] = g
+	c.groupEvent(EventGroupCreated, key)
+
+	return g, nil
+}
+
+func (c *cache) GetOrCreateGroup(key string, opts ...GroupOption) Group {
+	return c.wrapGroup(c.getOrCreateGroup(key, opts...))
+}
+
+// getOrCreateGroup returns group with specified key,
+// creating it with specified options, if it doesn't exist
+func (c *cache) getOrCreateGroup(key string, opts ...GroupOption) *group {
+	c.mx.Lock()
+	if g, exists := c.groups[key]; exists {
+		c.mx.Unlock()
+		g.markAccess()
+		return g
+	}
+
+	g := newGroup(c, key, opts...)
+	c.groups[key] = g
+	c.groupEvent(EventGroupCreated, key)
+	c.mx.Unlock()
+
+	c.evictGroups()
+
+	return g
+}
+
+func (c *cache) DelGroup(key string) error {
+	if c.closed() {
+		return ErrCacheClosed
 	}
 
-	c.groups[key] = &group{
-		values:     make(map[string]value),
-		fillFunc:   fillFunc,
-		expiration: expiration,
+	if !c.delGroup(key) {
+		return &GroupError{Group: key, Err: ErrGroupNotFound}
 	}
 
+	c.logWrite(walRecord{Op: walDelGroup, Group: key})
+
+	c.broadcast(Invalidation{Kind: InvalidateGroup, Group: key})
+
 	return nil
 }
 
-func (c *cache) DelGroup(key string) {
+// delGroup deletes group with specified key and its subgroups
+// without notifying other instances. Returns false if neither
+// group nor its subgroups exist
+func (c *cache) delGroup(key string) bool {
+	return c.delGroupOf(key, nil)
+}
+
+// delGroupOf deletes group with specified key and its subgroups
+// like delGroup, if current group with the key is g or g is nil
+func (c *cache) delGroupOf(key string, g *group) bool {
 	c.mx.Lock()
-	delete(c.groups, key)
+	if g != nil && c.groups[key] != g {
+		c.mx.Unlock()
+		return false
+	}
+
+	var groups []*group
+	for gkey, sg := range c.groups {
+		if gkey == key || isSubgroup(gkey, key) {
+			groups = append(groups, sg)
+			delete(c.groups, gkey)
+		}
+	}
 	c.mx.Unlock()
+
+	for _, g := range groups {
+		g.stopWriter()
+		g.clear()
+		c.groupEvent(EventGroupDeleted, g.key)
+	}
+
+	return len(groups) > 0
 }
 
-func (c *cache) GetGroupVal(gkey, vkey string) (interface{}, bool) {
+func (c *cache) Flush(deleteGroups bool) {
+	c.flush(deleteGroups)
+	c.logWrite(walRecord{Op: walFlush, DeleteGroups: deleteGroups})
+	c.broadcast(Invalidation{Kind: InvalidateAll, DeleteGroups: deleteGroups})
+}
+
+// flush deletes values of all groups
+// without notifying other instances
+func (c *cache) flush(deleteGroups bool) {
 	c.mx.Lock()
-	g, ok := c.groups[gkey]
+	groups := make([]*group, 0, len(c.groups)+1)
+	groups = append(groups, c.group)
+	for _, g := range c.groups {
+		groups = append(groups, g)
+	}
+	if deleteGroups {
+		c.groups = make(map[string]*group)
+	}
 	c.mx.Unlock()
 
+	for i, g := range groups {
+		if deleteGroups && i > 0 {
+			g.stopWriter()
+			g.clear()
+		} else if !g.frozen() {
+			g.clear()
+		}
+		if deleteGroups && i > 0 {
+			c.groupEvent(EventGroupDeleted, g.key)
+		}
+	}
+}
+
+func (c *cache) GetGroupVal(gkey, vkey string) (interface{}, error) {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return nil, err
+	}
+
+	val, ok := c.wrapGroup(g).Get(vkey)
 	if !ok {
-		return nil, false
+		return nil, &KeyError{Group: gkey, Key: vkey, Err: ErrKeyNotFound}
+	}
+
+	return val, nil
+}
+
+func (c *cache) HasGroupVal(gkey, vkey string) bool {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return false
 	}
 
-	return g.Get(vkey)
+	return c.wrapGroup(g).Has(vkey)
 }
 
 func (c *cache) SetGroupVal(gkey, vkey string, val interface{}) error {
-	c.mx.Lock()
-	g, ok := c.groups[gkey]
-	c.mx.Unlock()
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return err
+	}
 
-	if !ok {
-		return fmt.Errorf("group with key %q doesn't exist", gkey)
+	c.wrapGroup(g).Set(vkey, val)
+
+	return nil
+}
+
+func (c *cache) SetGroupValWithTTL(gkey, vkey string, val interface{}, ttl time.Duration) error {
+	g, err := c.lookupGroup(gkey)
+	if err != nil {
+		return err
 	}
 
-	g.Set(vkey, val)
+	c.wrapGroup(g).SetWithTTL(vkey, val, ttl)
 
 	return nil
 }
 
-type value struct {
-	data       interface{}
-	expiration int64
+// lookupGroup returns group with specified key. Returns
+// ErrGroupNotFound if group doesn't exist and ErrCacheClosed
+// if cache is closed
+func (c *cache) lookupGroup(key string) (*group, error) {
+	if c.closed() {
+		return nil, ErrCacheClosed
+	}
+
+	c.mx.RLock()
+	g, ok := c.groups[key]
+	c.mx.RUnlock()
+
+	if !ok {
+		return nil, &GroupError{Group: key, Err: ErrGroupNotFound}
+	}
+
+	g.markAccess()
+
+	return g, nil
+}
+
+// allGroups returns root group of cache followed by all other groups
+func (c *cache) allGroups() []*group {
+	c.mx.RLock()
+	groups := make([]*group, 0, len(c.groups)+1)
+	groups = append(groups, c.group)
+	for _, g := range c.groups {
+		groups = append(groups, g)
+	}
+	c.mx.RUnlock()
+
+	return groups
+}
+
+// closed reports whether cache is closed
+func (c *cache) closed() bool {
+	select {
+	case <-c.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *cache) Close() error {
+	err := ErrCacheClosed
+	c.closeOnce.Do(func() {
+		err = nil
+		close(c.stop)
+
+		for _, g := range c.allGroups() {
+			g.stopWriter()
+		}
+
+		if c.autoSnapshot != nil {
+			<-c.autoSnapshot.done
+			err = c.saveSnapshot(c.autoSnapshot.path)
+		}
+
+		if c.wal != nil {
+			if werr := c.wal.close(); err == nil {
+				err = werr
+			}
+		}
+
+		if c.invalidator != nil {
+			if ierr := c.invalidator.Close(); err == nil {
+				err = ierr
+			}
+		}
+	})
+
+	return err
 }
 
 type group struct {
-	mx         sync.Mutex
-	values     map[string]value
-	fillFunc   FillFunc
-	expiration time.Duration
+	stats      counters
+	cache      *cache
+	key        string
+	mx         sync.RWMutex
+	fillFunc   FillFuncCtx
+	fillName   string
+	codec      Codec
+	compressor Compressor
+	// compressThreshold is minimal size of compressed values
+	compressThreshold int
+	slabSize          int
+	batchFillFunc     BatchFillFunc
+	expiration        time.Duration
+	refreshPolicy     RefreshPolicy
+	staleTTL          time.Duration
+	maxEntries        int
+	maxCost           int64
+	coster            Coster
+	tinyLFU           bool
+	sliding           bool
+	negativeTTL       time.Duration
+	ttlJitter         float64
+	fillTimeout       time.Duration
+	breaker           *breaker
+	batcher           *fillBatcher
+	retry             *retryPolicy
+	limiter           *rateLimiter
+	cloner            Cloner
+	validator         Validator
+	maxValueSize      int64
+	skipOversized     bool
+	hot               *hotKeys
+	staleGrace        time.Duration
+	refreshAhead      float64
+	refreshSlots      chan struct{}
+	writeBehind       *writeBehindConfig
+	writer            *writeBehind
+	shardCount        int
+	shards            []*shard
+	// opts are options, which group was created with
+	opts []GroupOption
+	// accessed is time of last access to group in nanoseconds
+	// since Unix epoch, accounted for limit of number of groups
+	accessed int64
+	// freeze is non-zero, while group is frozen
+	freeze int32
+}
+
+// newGroup returns initialized group configured by default
+// group options of cache and by specified options.
+// Must be called with locked mutex of cache
+func newGroup(c *cache, key string, opts ...GroupOption) *group {
+	opts = append(append([]GroupOption(nil), c.groupDefaults...), opts...)
+
+	g := defaultGroup(c, key)
+	g.opts = opts
+	g.accessed = c.clock.Now().UnixNano()
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	g.init()
+
+	return g
+}
+
+// defaultGroup returns group with default settings,
+// which must be initialized after applying options
+func defaultGroup(c *cache, key string) *group {
+	return &group{
+		cache:      c,
+		key:        key,
+		shardCount: 1,
+	}
+}
+
+// init creates storage of group according to its settings
+func (g *group) init() {
+	maxEntries := g.maxEntries
+	if maxEntries > 0 {
+		maxEntries = (maxEntries + g.shardCount - 1) / g.shardCount
+	}
+
+	maxCost := g.maxCost
+	if maxCost > 0 {
+		maxCost = (maxCost + int64(g.shardCount) - 1) / int64(g.shardCount)
+	}
+
+	g.shards = make([]*shard, g.shardCount)
+	for i := range g.shards {
+		g.shards[i] = newShard(g, maxEntries, maxCost, g.tinyLFU)
+		if g.slabSize > 0 {
+			g.shards[i].slabs = &slabArena{size: g.slabSize}
+		}
+	}
+
+	if g.writeBehind != nil && g.cache.store != nil {
+		g.writer = newWriteBehind(*g.writeBehind)
+		go g.runWriter()
+	}
 }
 
 func (g *group) Get(key string) (interface{}, bool) {
-	g.mx.Lock()
-	v, ok := g.values[key]
-	g.mx.Unlock()
+	return g.GetCtx(context.Background(), key)
+}
 
-	now := time.Now()
-	if ok && (v.expiration == 0 || v.expiration > now.UnixNano()) {
-		return v.data, true
+func (g *group) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	v, ok := g.get(ctx, key)
+	return g.copyOut(v.val()), ok
+}
+
+func (g *group) GetStale(key string) (interface{}, bool, bool) {
+	v, ok := g.get(context.Background(), key)
+	if !ok {
+		return nil, false, false
 	}
 
-	if g.fillFunc == nil {
-		g.mx.Lock()
-		delete(g.values, key)
-		g.mx.Unlock()
-		return nil, false
+	return g.copyOut(v.val()), v.expired(g.now().UnixNano()), true
+}
+
+func (g *group) GetWithExpiration(key string) (interface{}, time.Time, bool) {
+	v, ok := g.get(context.Background(), key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	if v.expiration == 0 {
+		return g.copyOut(v.val()), time.Time{}, true
 	}
 
-	v.data, ok = g.fillFunc(key)
+	return g.copyOut(v.val()), time.Unix(0, v.expiration), true
+}
+
+func (g *group) TTL(key string) (time.Duration, bool) {
+	now := g.now()
+
+	s := g.shard(key)
+	s.mx.RLock()
+	v, ok := s.values[key]
+	s.mx.RUnlock()
+
+	if !ok || v.expired(now.UnixNano()) || v.absent() {
+		return 0, false
+	}
+
+	return v.remaining(now.UnixNano()), true
+}
+
+func (g *group) Peek(key string) (interface{}, bool) {
+	now := g.now().UnixNano()
 
+	s := g.shard(key)
+	s.mx.RLock()
+	v, ok := s.values[key]
+	s.mx.RUnlock()
+
+	if !ok || v.expired(now) || v.absent() {
+		return nil, false
+	}
+
+	data, ok := g.read(key, v)
 	if !ok {
-		g.mx.Lock()
-		delete(g.values, key)
-		g.mx.Unlock()
 		return nil, false
 	}
 
-	if g.expiration != 0 {
-		v.expiration = now.Add(g.expiration).UnixNano()
+	return g.copyOut(data), true
+}
+
+func (g *group) Has(key string) bool {
+	now := g.now().UnixNano()
+
+	s := g.shard(key)
+	s.mx.RLock()
+	v, ok := s.values[key]
+	s.mx.RUnlock()
+
+	return ok && !v.expired(now) && !v.absent()
+}
+
+// get returns unexpired value with specified key,
+// filling it if necessary
+func (g *group) get(ctx context.Context, key string) (value, bool) {
+	now := g.now()
+	g.markAccess()
+
+	if g.hot != nil {
+		g.hot.record(key, now.UnixNano())
 	}
 
-	g.mx.Lock()
-	g.values[key] = v
-	g.mx.Unlock()
+	s := g.shard(key)
+
+	// shard without recency tracking and sliding expiration
+	// serves hits under read lock. Tracking may be started
+	// by SetQuota, so it's checked under the lock as well
+	if !g.sliding {
+		s.mx.RLock()
+		v, ok := s.values[key]
+		ok = ok && s.lru == nil
+		s.mx.RUnlock()
+
+		// value, which can't be unpacked,
+		// is removed by lookup under write lock
+		if ok && !v.expired(now.UnixNano()) {
+			if v, err := v.unpacked(); err == nil {
+				v.access.record(now.UnixNano())
+				atomic.AddUint64(&g.stats.hits, 1)
+				s.refreshAhead(key, v, now)
+				return v, !v.absent()
+			}
+		}
+	}
+
+	staleTTL, serveStale := g.getStaleTTL()
 
-	return v.data, true
+	s.mx.Lock()
+	if serveStale {
+		if v, ok := s.getStale(key, now, staleTTL); ok {
+			s.unlock()
+			return v, true
+		}
+	}
+
+	if v, ok := s.lookup(key, now.UnixNano()); ok {
+		s.unlock()
+		atomic.AddUint64(&g.stats.hits, 1)
+		s.refreshAhead(key, v, now)
+		return v, !v.absent()
+	}
+
+	atomic.AddUint64(&g.stats.misses, 1)
+
+	if s.backingOff(key, now.UnixNano()) {
+		v, ok := s.graceValue(key)
+		s.unlock()
+		return v, ok
+	}
+
+	if c, ok := s.calls[key]; ok {
+		s.unlock()
+		select {
+		case <-c.done:
+			return value{data: c.data, expiration: c.expiration, version: c.version}, c.ok
+		case <-ctx.Done():
+			return value{}, false
+		}
+	}
+
+	g.mx.RLock()
+	fillFunc, expiration := g.getFillFunc(), g.expiration
+	g.mx.RUnlock()
+
+	if fillFunc == nil && g.cache.store == nil && !g.cache.hasPeers() || g.frozen() {
+		s.unlock()
+		return value{}, false
+	}
+
+	c := &call{done: make(chan struct{})}
+	s.calls[key] = c
+	s.unlock()
+
+	s.fill(ctx, key, c, fillFunc, expiration, now)
+
+	return value{data: c.data, expiration: c.expiration, version: c.version}, c.ok
 }
 
 func (g *group) Set(key string, val interface{}) {
-	g.mx.Lock()
+	g.SetWithTTL(key, val, g.getExpiration())
+}
+
+func (g *group) SetWithTTL(key string, val interface{}, ttl time.Duration) {
+	if g.frozen() {
+		return
+	}
+
+	g.markAccess()
 
-	var expiration int64
-	if g.expiration != 0 {
-		expiration = time.Now().Add(g.expiration).UnixNano()
+	if g.validate(key, val) != nil {
+		return
 	}
 
-	g.values[key] = value{
-		data:       val,
-		expiration: expiration,
+	s := g.shard(key)
+	s.mx.Lock()
+	s.store(key, val, g.now(), ttl)
+	s.unlock()
+
+	g.persist(key, val, ttl)
+}
+
+func (g *group) Touch(key string, ttl time.Duration) bool {
+	if g.frozen() {
+		return false
 	}
 
-	g.mx.Unlock()
+	now := g.now()
+
+	s := g.shard(key)
+	s.mx.Lock()
+
+	v, ok := s.values[key]
+	if !ok || v.expired(now.UnixNano()) || v.absent() {
+		s.unlock()
+		return false
+	}
+
+	s.expire(key, &v, expireAt(now, g.jitter(ttl)))
+	v.ttl = ttl
+	s.values[key] = v
+	s.unlock()
+
+	g.cache.logTouch(g.key, key, v.expiration)
+
+	return true
+}
+
+func (g *group) GetOrSet(key string, val interface{}) (interface{}, bool) {
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	if v, ok := s.lookup(key, now.UnixNano()); ok && !v.absent() {
+		s.unlock()
+		atomic.AddUint64(&g.stats.hits, 1)
+		return g.copyOut(v.val()), true
+	}
+
+	atomic.AddUint64(&g.stats.misses, 1)
+	if g.frozen() || g.validate(key, val) != nil {
+		s.unlock()
+		return val, false
+	}
+
+	s.store(key, val, now, expiration)
+	s.unlock()
+
+	g.persist(key, val, expiration)
+
+	return val, false
+}
+
+func (g *group) GetOrCompute(key string, compute func() (interface{}, bool)) (interface{}, bool) {
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	if v, ok := s.lookup(key, now.UnixNano()); ok && !v.absent() {
+		s.unlock()
+		atomic.AddUint64(&g.stats.hits, 1)
+		return g.copyOut(v.val()), true
+	}
+
+	atomic.AddUint64(&g.stats.misses, 1)
+
+	if g.frozen() {
+		s.unlock()
+		return nil, false
+	}
+
+	if c, ok := s.calls[key]; ok {
+		s.unlock()
+		<-c.done
+		return g.copyOut(c.data), c.ok
+	}
+
+	c := &call{done: make(chan struct{})}
+	s.calls[key] = c
+	s.unlock()
+
+	func() {
+		defer s.complete(key, c, expiration, now)
+		c.data, c.ok = compute()
+		g.accept(key, c)
+	}()
+
+	if !c.ok {
+		return nil, false
+	}
+
+	if !c.uncached {
+		g.persist(key, c.data, expiration)
+	}
+
+	return g.copyOut(c.data), true
 }
 
 func (g *group) Del(key string) {
-	g.mx.Lock()
-	delete(g.values, key)
-	g.mx.Unlock()
+	if g.frozen() {
+		return
+	}
+
+	s := g.shard(key)
+	s.mx.Lock()
+	s.remove(key)
+	s.unlock()
+
+	g.discard(key)
+}
+
+// discard removes value with specified key from store
+// of cache and from other instances of cache
+func (g *group) discard(key string) {
+	g.unpersist(key)
+	g.cache.broadcast(Invalidation{Kind: InvalidateKeys, Group: g.key, Keys: []string{key}})
+}
+
+func (g *group) Clear() {
+	g.delFunc(func(string, value) bool { return true })
+}
+
+// clear removes all values from group, even if it is frozen,
+// without writing to store of cache or notifying other instances
+func (g *group) clear() {
+	for _, s := range g.shards {
+		s.mx.Lock()
+		for key := range s.values {
+			s.remove(key)
+		}
+		s.unlock()
+	}
 }
 
-func (g *group) SetExpiration(expiration time.Duration) {
+// ExpirationMode presents way of applying
+// changed expiration of group
+type ExpirationMode int
+
+const (
+	// ApplyToNew makes changed expiration affect only
+	// values stored afterwards. It is default mode
+	ApplyToNew ExpirationMode = iota
+	// ApplyToExisting makes changed expiration affect
+	// existing values as well: they expire after it
+	// passes since they were stored
+	ApplyToExisting
+)
+
+func (g *group) SetExpiration(expiration time.Duration, modes ...ExpirationMode) {
 	if expiration <= 0 {
 		expiration = 0
 	}
@@ -206,10 +1238,98 @@ func (g *group) SetExpiration(expiration time.Duration) {
 	g.mx.Lock()
 	g.expiration = expiration
 	g.mx.Unlock()
+
+	mode := ApplyToNew
+	for _, m := range modes {
+		if m == ApplyToExisting {
+			mode = m
+			g.restamp(expiration)
+			break
+		}
+	}
+
+	g.cache.logWrite(walRecord{Op: walExpiration, Group: g.key, TTL: expiration, Mode: mode})
+}
+
+// restamp recomputes expiration of existing group values
+// as time they were stored plus ttl
+func (g *group) restamp(ttl time.Duration) {
+	for _, s := range g.shards {
+		s.mx.Lock()
+		for key, v := range s.values {
+			if v.absent() {
+				continue
+			}
+
+			s.expire(key, &v, expireAt(time.Unix(0, v.created), g.jitter(ttl)))
+			v.ttl = ttl
+			s.values[key] = v
+		}
+		s.unlock()
+	}
 }
 
 func (g *group) SetFillFunc(fillFunc FillFunc) {
+	g.SetFillFuncCtx(fillFunc.withContext())
+}
+
+func (g *group) SetFillFuncCtx(fillFunc FillFuncCtx) {
 	g.mx.Lock()
 	g.fillFunc = fillFunc
+	g.fillName = ""
 	g.mx.Unlock()
 }
+
+func (g *group) getExpiration() time.Duration {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	return g.expiration
+}
+
+// now returns current time of cache clock
+func (g *group) now() time.Time {
+	return g.cache.clock.Now()
+}
+
+// shard returns shard, which holds value with specified key
+func (g *group) shard(key string) *shard {
+	if len(g.shards) == 1 {
+		return g.shards[0]
+	}
+
+	// inlined FNV-1a, which avoids allocation of hash.Hash32
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+
+	return g.shards[h%uint32(len(g.shards))]
+}
+
+// jitter returns ttl randomly changed by up to jitter fraction
+// of group in both directions. Zero or negative ttl is kept
+func (g *group) jitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 || g.ttlJitter == 0 {
+		return ttl
+	}
+
+	ttl += time.Duration(float64(ttl) * g.ttlJitter * (2*rand.Float64() - 1))
+	if ttl <= 0 {
+		ttl = 1
+	}
+
+	return ttl
+}
+
+// expireAt returns expiration timestamp of value
+// with specified live duration, stored at now.
+// Zero timestamp means value never expires
+func expireAt(now time.Time, ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	return now.Add(ttl).UnixNano()
+}
@@ -0,0 +1,310 @@
+package gache
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const benchKeys = 1024
+
+// lockModes presents group configurations, which lookups
+// take read lock (default) or exclusive lock (recency tracking
+// or sliding expiration), as every lookup did before read locks
+// were introduced
+var lockModes = []struct {
+	name string
+	opts []GroupOption
+}{
+	{"RLock", nil},
+	{"Lock", []GroupOption{WithMaxEntries(2 * benchKeys)}},
+	{"LockSliding", []GroupOption{WithSlidingExpiration()}},
+}
+
+func TestMaxEntries(t *testing.T) {
+	c := NewCache()
+	if err := c.NewGroup("g", WithMaxEntries(3)); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := c.Group("g")
+
+	for i := 0; i < 3; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+
+	g.Get("0")
+	g.Set("3", 3)
+
+	if _, ok := g.Get("1"); ok {
+		t.Fatal("expected least recently used value to be evicted")
+	}
+	for _, key := range []string{"0", "2", "3"} {
+		if _, ok := g.Get(key); !ok {
+			t.Fatalf("expected value %s to stay", key)
+		}
+	}
+
+	g.Set("2", 20)
+	g.Set("4", 4)
+	if _, ok := g.Get("0"); ok {
+		t.Fatal("expected overwrite to mark value as recently used")
+	}
+	if v, ok := g.Get("2"); !ok || v != 20 {
+		t.Fatalf("expected overwritten value 20, got %v, %v", v, ok)
+	}
+}
+
+func TestUnboundedGroup(t *testing.T) {
+	c := NewCache()
+
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	for i := 0; i < 100; i++ {
+		if _, ok := c.Get(strconv.Itoa(i)); !ok {
+			t.Fatalf("expected value %d in unbounded group", i)
+		}
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	c := NewCache()
+
+	if v, loaded := c.GetOrSet("a", 1); loaded || v != 1 {
+		t.Fatalf("expected value 1 to be set, got %v, %v", v, loaded)
+	}
+	if v, loaded := c.GetOrSet("a", 2); !loaded || v != 1 {
+		t.Fatalf("expected existing value 1, got %v, %v", v, loaded)
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	c := NewCache()
+
+	calls := 0
+	compute := func() (interface{}, bool) {
+		calls++
+		return calls, true
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, ok := c.GetOrCompute("a", compute); !ok || v != 1 {
+			t.Fatalf("expected computed value 1, got %v, %v", v, ok)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected single computation, got %d", calls)
+	}
+
+	if v, ok := c.GetOrCompute("b", func() (interface{}, bool) { return nil, false }); ok {
+		t.Fatalf("expected failed computation, got %v", v)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected failed computation not to be stored")
+	}
+}
+
+func TestGetOrComputeConcurrent(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	var calls int32
+	release := make(chan struct{})
+	compute := func() (interface{}, bool) {
+		atomic.AddInt32(&calls, 1)
+		// computing doesn't lock other keys
+		c.Set("other", 1)
+		<-release
+		return "computed", true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := c.GetOrCompute("a", compute); !ok || v != "computed" {
+				t.Errorf("expected computed value, got %v, %v", v, ok)
+			}
+		}()
+	}
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) > 0 })
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected single computation, got %d", n)
+	}
+}
+
+func TestGetOrCreateGroup(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	groups := make(chan Group, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(groups); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			groups <- c.GetOrCreateGroup("g", WithExpiration(time.Minute))
+		}()
+	}
+	wg.Wait()
+	close(groups)
+
+	first := <-groups
+	for g := range groups {
+		if g != first {
+			t.Fatal("expected single group created concurrently")
+		}
+	}
+
+	g := c.GetOrCreateGroup("g", WithExpiration(time.Hour))
+	g.Set("a", 1)
+	if ttl, _ := g.TTL("a"); ttl > time.Minute {
+		t.Fatalf("expected options of existing group kept, got TTL %v", ttl)
+	}
+}
+
+func newBenchGroup(b *testing.B, opts ...GroupOption) (Cache, Group) {
+	c := NewCache()
+	b.Cleanup(func() { c.Close() })
+
+	c.NewGroup("bench", opts...)
+	g, _ := c.Group("bench")
+	for i := 0; i < benchKeys; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+
+	return c, g
+}
+
+func benchKeyList() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	return keys
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	keys := benchKeyList()
+	for _, mode := range lockModes {
+		b.Run(mode.name, func(b *testing.B) {
+			_, g := newBenchGroup(b, mode.opts...)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					g.Get(keys[i%benchKeys])
+					i++
+				}
+			})
+		})
+	}
+
+	b.Run("GetGroupVal", func(b *testing.B) {
+		c, _ := newBenchGroup(b)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				c.GetGroupVal("bench", keys[i%benchKeys])
+				i++
+			}
+		})
+	})
+}
+
+func BenchmarkMixed(b *testing.B) {
+	keys := benchKeyList()
+	for _, mode := range lockModes {
+		for _, writes := range []int{1, 10, 50} {
+			name := mode.name + "/writes=" + strconv.Itoa(writes) + "%"
+			b.Run(name, func(b *testing.B) {
+				_, g := newBenchGroup(b, mode.opts...)
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						key := keys[i%benchKeys]
+						if i%100 < writes {
+							g.Set(key, i)
+						} else {
+							g.Get(key)
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
+
+func BenchmarkSetParallel(b *testing.B) {
+	for _, interning := range []bool{false, true} {
+		name := "Interning=" + strconv.FormatBool(interning)
+		b.Run(name, func(b *testing.B) {
+			var opts []Option
+			if interning {
+				opts = append(opts, WithKeyInterning())
+			}
+
+			c := NewCache(opts...)
+			b.Cleanup(func() { c.Close() })
+
+			groups := make([]Group, 8)
+			for i := range groups {
+				groups[i] = c.GetOrCreateGroup("bench" + strconv.Itoa(i))
+			}
+
+			keys := benchKeyList()
+			var next uint32
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				g := groups[atomic.AddUint32(&next, 1)%uint32(len(groups))]
+				i := 0
+				for pb.Next() {
+					g.Set(keys[i%benchKeys], i)
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestClearAndFlush(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("a", WithShards(4))
+	c.NewGroup("b")
+
+	c.Set("x", 1)
+	for i := 0; i < 10; i++ {
+		c.SetGroupVal("a", strconv.Itoa(i), i)
+	}
+	c.SetGroupVal("b", "y", 2)
+
+	a, _ := c.Group("a")
+	a.Clear()
+	if n := a.Len(); n != 0 {
+		t.Fatalf("expected cleared group, got %d values", n)
+	}
+	if n := c.TotalLen(); n != 2 {
+		t.Fatalf("expected other groups untouched, got %d values", n)
+	}
+
+	c.Flush(false)
+	if n := c.TotalLen(); n != 0 || len(c.Groups()) != 2 {
+		t.Fatalf("expected flushed values and kept groups, got %d values, groups %v", n, c.Groups())
+	}
+
+	c.Flush(true)
+	if groups := c.Groups(); len(groups) != 0 {
+		t.Fatalf("expected deleted groups, got %v", groups)
+	}
+}
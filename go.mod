@@ -0,0 +1,3 @@
+module github.com/kcasctiv/gache
+
+go 1.21
@@ -0,0 +1,419 @@
+// Package gossip replicates values of designated groups of
+// gache.Cache between nodes, which form memberlist cluster
+package gossip
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/kcasctiv/gache"
+)
+
+const (
+	defaultTombstoneTTL = time.Minute
+	defaultEventBuffer  = 1024
+	leaveTimeout        = 5 * time.Second
+)
+
+// Replicator replicates values of designated groups of cache.
+// Values stored and deleted on one node are broadcast to other
+// nodes asynchronously, and nodes periodically exchange their
+// full state, so missed updates are repaired. Conflicting updates
+// are resolved by last write wins: update with later timestamp
+// is kept, ties are broken by node names.
+//
+// Local changes are observed as events of cache, so changes,
+// which receiver of events falls behind, aren't replicated until
+// the value is changed again. Expired and evicted values aren't
+// replicated, deletions are kept as tombstones for tombstone TTL.
+// Replicator keeps serialized copy of every replicated value
+type Replicator struct {
+	cache   gache.Cache
+	groups  map[string]bool
+	codec   gache.Codec
+	name    string
+	members *memberlist.Memberlist
+	queue   *memberlist.TransmitLimitedQueue
+
+	conf         *memberlist.Config
+	tombstoneTTL time.Duration
+	eventBuffer  int
+
+	mx    sync.Mutex
+	state map[entryKey]update
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option presents type of function, intended for
+// configuring replicator on creation
+type Option func(*Replicator)
+
+// WithConfig sets configuration of memberlist, e.g. name of node
+// and address it binds to. Delegate of configuration is replaced
+// by replicator. Default is memberlist.DefaultLANConfig
+func WithConfig(conf *memberlist.Config) Option {
+	return func(r *Replicator) {
+		r.conf = conf
+	}
+}
+
+// WithCodec sets codec, which serializes values and messages.
+// Concrete types of values must be registered, if codec
+// requires it. Default is gache.GobCodec
+func WithCodec(codec gache.Codec) Option {
+	return func(r *Replicator) {
+		r.codec = codec
+	}
+}
+
+// WithTombstoneTTL sets duration, during which deletions are
+// kept, so they override older values of other nodes. Default is 1m
+func WithTombstoneTTL(ttl time.Duration) Option {
+	return func(r *Replicator) {
+		r.tombstoneTTL = ttl
+	}
+}
+
+// WithEventBuffer sets size of buffer of events of cache,
+// which are observed by replicator. Default is 1024
+func WithEventBuffer(n int) Option {
+	return func(r *Replicator) {
+		r.eventBuffer = n
+	}
+}
+
+// New starts replication of groups of cache with specified keys.
+// Empty key means root group of cache. Replicator joins other
+// nodes by Join call
+func New(cache gache.Cache, groups []string, opts ...Option) (*Replicator, error) {
+	r := &Replicator{
+		cache:        cache,
+		groups:       make(map[string]bool, len(groups)),
+		codec:        gache.GobCodec{},
+		tombstoneTTL: defaultTombstoneTTL,
+		eventBuffer:  defaultEventBuffer,
+		state:        make(map[entryKey]update),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for _, key := range groups {
+		r.groups[key] = true
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.conf == nil {
+		r.conf = memberlist.DefaultLANConfig()
+	}
+	r.conf.Delegate = delegate{r}
+	r.name = r.conf.Name
+
+	members, err := memberlist.Create(r.conf)
+	if err != nil {
+		return nil, err
+	}
+	r.members = members
+
+	r.queue = &memberlist.TransmitLimitedQueue{
+		NumNodes:       members.NumMembers,
+		RetransmitMult: 3,
+	}
+
+	events, cancel := cache.Subscribe(r.eventBuffer)
+	go r.run(events, cancel)
+
+	return r, nil
+}
+
+// Join joins cluster, which nodes with specified addresses
+// belong to, and returns number of nodes contacted
+func (r *Replicator) Join(addrs ...string) (int, error) {
+	return r.members.Join(addrs)
+}
+
+// Close stops replication and leaves cluster
+func (r *Replicator) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+
+		err = r.members.Leave(leaveTimeout)
+		if serr := r.members.Shutdown(); err == nil {
+			err = serr
+		}
+	})
+
+	return err
+}
+
+// run observes events of cache and periodically
+// removes outdated tombstones, until replicator is closed
+func (r *Replicator) run(events <-chan gache.Event, cancel func()) {
+	defer close(r.done)
+	defer cancel()
+
+	ticker := time.NewTicker(r.tombstoneTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			r.observe(ev)
+		case now := <-ticker.C:
+			r.prune(now)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// entryKey presents key of value in cache
+type entryKey struct {
+	group string
+	key   string
+}
+
+// update presents replicated state of value. Expires is time,
+// when value expires, in unix nanoseconds, zero means value
+// never expires. TS is time of update in unix nanoseconds
+type update struct {
+	Group   string
+	Key     string
+	Value   []byte
+	Deleted bool
+	Expires int64
+	TS      int64
+	Node    string
+
+	// applied is value, which was stored by applying update,
+	// until event of storing it is observed
+	applied interface{}
+}
+
+// newer reports whether u wins conflict with other
+func (u update) newer(other update) bool {
+	return u.TS > other.TS || u.TS == other.TS && u.Node > other.Node
+}
+
+// message presents batch of updates sent between nodes
+type message struct {
+	Updates []update
+}
+
+// entry wraps value, so codec keeps its concrete type
+type entry struct {
+	Value interface{}
+}
+
+// group returns replicated group with specified key
+func (r *Replicator) group(key string) gache.Group {
+	if key == "" {
+		return r.cache
+	}
+
+	return r.cache.GetOrCreateGroup(key)
+}
+
+// observe records change of value of replicated group, which
+// was made locally, and broadcasts it to other nodes. Changes
+// made by applying updates of other nodes are skipped
+func (r *Replicator) observe(ev gache.Event) {
+	if !r.groups[ev.Group] || ev.Type != gache.EventSet && ev.Type != gache.EventDel {
+		return
+	}
+
+	key := entryKey{group: ev.Group, key: ev.Key}
+	if r.echoed(key, ev) {
+		return
+	}
+
+	u := update{Group: ev.Group, Key: ev.Key, Deleted: ev.Type == gache.EventDel, Node: r.name}
+	if !u.Deleted {
+		data, err := r.codec.Marshal(&entry{Value: ev.Value})
+		if err != nil {
+			return
+		}
+		u.Value = data
+
+		if ttl, ok := r.group(ev.Group).TTL(ev.Key); ok && ttl > 0 {
+			u.Expires = time.Now().Add(ttl).UnixNano()
+		}
+	}
+
+	u.TS = time.Now().UnixNano()
+
+	r.mx.Lock()
+	if old, ok := r.state[key]; ok {
+		if old.Deleted == u.Deleted && bytes.Equal(old.Value, u.Value) {
+			r.mx.Unlock()
+			return
+		}
+
+		if u.TS <= old.TS {
+			u.TS = old.TS + 1
+		}
+	} else if u.Deleted {
+		r.mx.Unlock()
+		return
+	}
+	r.state[key] = u
+	r.mx.Unlock()
+
+	r.broadcast(u)
+}
+
+// echoed reports whether ev is event of storing value,
+// which was applied from update of other node
+func (r *Replicator) echoed(key entryKey, ev gache.Event) bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	u, ok := r.state[key]
+	if !ok || u.applied == nil || ev.Type != gache.EventSet || !reflect.DeepEqual(u.applied, ev.Value) {
+		return false
+	}
+
+	u.applied = nil
+	r.state[key] = u
+
+	return true
+}
+
+// broadcast queues update for sending to other nodes.
+// Queued update of the same value is replaced
+func (r *Replicator) broadcast(u update) {
+	data, err := r.codec.Marshal(&message{Updates: []update{u}})
+	if err != nil {
+		return
+	}
+
+	r.queue.QueueBroadcast(&broadcast{key: entryKey{group: u.Group, key: u.Key}, msg: data})
+}
+
+// apply applies updates received from other nodes,
+// which win conflicts with known state of their values
+func (r *Replicator) apply(updates []update) {
+	now := time.Now()
+	for _, u := range updates {
+		if !r.groups[u.Group] || u.Expires != 0 && u.Expires <= now.UnixNano() {
+			continue
+		}
+
+		var e entry
+		if !u.Deleted {
+			if err := r.codec.Unmarshal(u.Value, &e); err != nil {
+				continue
+			}
+			u.applied = e.Value
+		}
+
+		key := entryKey{group: u.Group, key: u.Key}
+
+		r.mx.Lock()
+		if old, ok := r.state[key]; ok && !u.newer(old) {
+			r.mx.Unlock()
+			continue
+		}
+		r.state[key] = u
+		r.mx.Unlock()
+
+		g := r.group(u.Group)
+		if u.Deleted {
+			g.Del(u.Key)
+			continue
+		}
+
+		var ttl time.Duration
+		if u.Expires != 0 {
+			ttl = time.Duration(u.Expires - now.UnixNano())
+		}
+		g.SetWithTTL(u.Key, e.Value, ttl)
+	}
+}
+
+// prune forgets tombstones older than tombstone TTL
+// and values, which expired before now
+func (r *Replicator) prune(now time.Time) {
+	r.mx.Lock()
+	for key, u := range r.state {
+		if u.Deleted && u.TS+int64(r.tombstoneTTL) <= now.UnixNano() ||
+			!u.Deleted && u.Expires != 0 && u.Expires <= now.UnixNano() {
+			delete(r.state, key)
+		}
+	}
+	r.mx.Unlock()
+}
+
+// broadcast is memberlist.Broadcast of update of value
+type broadcast struct {
+	key entryKey
+	msg []byte
+}
+
+func (b *broadcast) Invalidates(other memberlist.Broadcast) bool {
+	o, ok := other.(*broadcast)
+	return ok && o.key == b.key
+}
+
+func (b *broadcast) Message() []byte {
+	return b.msg
+}
+
+func (b *broadcast) Finished() {}
+
+// delegate is memberlist.Delegate of replicator
+type delegate struct {
+	r *Replicator
+}
+
+func (d delegate) NodeMeta(limit int) []byte {
+	return nil
+}
+
+func (d delegate) NotifyMsg(data []byte) {
+	var msg message
+	if err := d.r.codec.Unmarshal(data, &msg); err != nil {
+		return
+	}
+
+	d.r.apply(msg.Updates)
+}
+
+func (d delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return d.r.queue.GetBroadcasts(overhead, limit)
+}
+
+// LocalState returns full state of replicated values,
+// which is merged by other nodes for anti-entropy
+func (d delegate) LocalState(join bool) []byte {
+	d.r.mx.Lock()
+	msg := message{Updates: make([]update, 0, len(d.r.state))}
+	for _, u := range d.r.state {
+		msg.Updates = append(msg.Updates, u)
+	}
+	d.r.mx.Unlock()
+
+	data, err := d.r.codec.Marshal(&msg)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (d delegate) MergeRemoteState(data []byte, join bool) {
+	d.NotifyMsg(data)
+}
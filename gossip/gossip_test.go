@@ -0,0 +1,94 @@
+package gossip
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/kcasctiv/gache"
+)
+
+func newTestReplicator(t *testing.T, name string, cache gache.Cache) *Replicator {
+	t.Helper()
+
+	conf := memberlist.DefaultLocalConfig()
+	conf.Name = name
+	conf.BindAddr = "127.0.0.1"
+	conf.BindPort = 0
+	conf.AdvertiseAddr = ""
+	conf.Logger = log.New(io.Discard, "", 0)
+
+	r, err := New(cache, []string{"", "g"}, WithConfig(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+
+	return r
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplicator(t *testing.T) {
+	c1, c2 := gache.NewCache(), gache.NewCache()
+	t.Cleanup(func() { c1.Close() })
+	t.Cleanup(func() { c2.Close() })
+	g1 := c1.GetOrCreateGroup("g")
+	g2 := c2.GetOrCreateGroup("g")
+
+	r1 := newTestReplicator(t, "a", c1)
+	r2 := newTestReplicator(t, "b", c2)
+
+	c1.Set("early", 1)
+	waitFor(t, func() bool {
+		r1.mx.Lock()
+		defer r1.mx.Unlock()
+		return len(r1.state) == 1
+	})
+
+	node := r1.members.LocalNode()
+	if _, err := r2.Join(fmt.Sprintf("%s:%d", node.Addr, node.Port)); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		val, ok := c2.Get("early")
+		return ok && val == 1
+	})
+
+	g1.Set("a", "x")
+	waitFor(t, func() bool {
+		val, ok := g2.Get("a")
+		return ok && val == "x"
+	})
+
+	g2.Set("a", "y")
+	waitFor(t, func() bool {
+		val, ok := g1.Get("a")
+		return ok && val == "y"
+	})
+
+	g1.Del("a")
+	waitFor(t, func() bool {
+		return !g2.Has("a")
+	})
+
+	c1.GetOrCreateGroup("other").Set("a", 1)
+	time.Sleep(100 * time.Millisecond)
+	if g, ok := c2.Group("other"); ok && g.Has("a") {
+		t.Fatal("expected value of not replicated group kept local")
+	}
+}
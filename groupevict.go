@@ -0,0 +1,46 @@
+package gache
+
+import "sync/atomic"
+
+func (c *cache) OnGroupEvicted(f func(key string)) {
+	c.onGroupEvicted.Store(f)
+}
+
+// markAccess accounts access to group, if number of groups is limited
+func (g *group) markAccess() {
+	if g.cache.maxGroups > 0 {
+		atomic.StoreInt64(&g.accessed, g.cache.clock.Now().UnixNano())
+	}
+}
+
+// evictGroups deletes least recently accessed groups,
+// while number of groups exceeds limit of cache
+func (c *cache) evictGroups() {
+	if c.maxGroups <= 0 {
+		return
+	}
+
+	for {
+		c.mx.RLock()
+		if len(c.groups) <= c.maxGroups {
+			c.mx.RUnlock()
+			return
+		}
+
+		var lru *group
+		for _, g := range c.groups {
+			if lru == nil || atomic.LoadInt64(&g.accessed) < atomic.LoadInt64(&lru.accessed) {
+				lru = g
+			}
+		}
+		c.mx.RUnlock()
+
+		if !c.delGroupOf(lru.key, lru) {
+			continue
+		}
+
+		if f, _ := c.onGroupEvicted.Load().(func(key string)); f != nil {
+			f(lru.key)
+		}
+	}
+}
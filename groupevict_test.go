@@ -0,0 +1,43 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxGroups(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithMaxGroups(2))
+	t.Cleanup(func() { c.Close() })
+
+	var evicted []string
+	c.OnGroupEvicted(func(key string) {
+		evicted = append(evicted, key)
+	})
+
+	c.NewGroup("a")
+	clock.Advance(time.Second)
+	c.NewGroup("b")
+	clock.Advance(time.Second)
+
+	// access makes group a recently used
+	c.GetGroupVal("a", "x")
+	clock.Advance(time.Second)
+
+	c.NewGroup("c")
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Fatalf("expected least recently accessed group b evicted, got %v", evicted)
+	}
+	if _, ok := c.Group("b"); ok {
+		t.Fatal("expected evicted group deleted")
+	}
+
+	clock.Advance(time.Second)
+	c.GetOrCreateGroup("d")
+	if len(evicted) != 2 || evicted[1] != "a" {
+		t.Fatalf("expected group a evicted, got %v", evicted)
+	}
+	if groups := c.Groups(); len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %v", groups)
+	}
+}
@@ -0,0 +1,28 @@
+package gache
+
+// GroupMiddleware presents type of function, intended for
+// decorating cache groups, e.g. for collecting metrics,
+// tracing, authorization or validation. Returned group
+// usually embeds next and overrides some of its methods
+type GroupMiddleware func(next Group) Group
+
+func (c *cache) Use(mw ...GroupMiddleware) {
+	c.mx.Lock()
+	c.middlewares = append(c.middlewares[:len(c.middlewares):len(c.middlewares)], mw...)
+	c.mx.Unlock()
+}
+
+// wrapGroup decorates group by middlewares of cache.
+// First added middleware is outermost
+func (c *cache) wrapGroup(g *group) Group {
+	c.mx.RLock()
+	middlewares := c.middlewares
+	c.mx.RUnlock()
+
+	var wrapped Group = g
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		wrapped = middlewares[i](wrapped)
+	}
+
+	return wrapped
+}
@@ -0,0 +1,53 @@
+package gache
+
+import (
+	"strings"
+	"testing"
+)
+
+// prefixGroup is middleware's group, which prefixes values with name
+type prefixGroup struct {
+	Group
+	name string
+}
+
+func (g prefixGroup) Get(key string) (interface{}, bool) {
+	v, ok := g.Group.Get(key)
+	if !ok {
+		return v, ok
+	}
+	return g.name + v.(string), true
+}
+
+func prefixer(name string) GroupMiddleware {
+	return func(next Group) Group {
+		return prefixGroup{Group: next, name: name}
+	}
+}
+
+func TestUse(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g")
+	c.Use(prefixer("outer:"), prefixer("inner:"))
+
+	if err := c.SetGroupVal("g", "a", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.GetGroupVal("g", "a"); err != nil || v != "outer:inner:v" {
+		t.Fatalf("expected value decorated by middlewares in order, got %v, %v", v, err)
+	}
+
+	g, _ := c.Group("g")
+	if v, _ := g.Get("a"); v != "outer:inner:v" {
+		t.Fatalf("expected decorated group, got %v", v)
+	}
+	if v, _ := c.GetOrCreateGroup("h").Get("a"); v != nil {
+		t.Fatalf("expected no value of new group, got %v", v)
+	}
+
+	c.Set("a", "v")
+	if v, _ := c.Get("a"); strings.Contains(v.(string), ":") {
+		t.Fatalf("expected root group methods not decorated, got %v", v)
+	}
+}
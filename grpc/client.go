@@ -0,0 +1,526 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"google.golang.org/grpc"
+)
+
+// watchBuffer is default capacity of channels, which deliver events
+const watchBuffer = 64
+
+// ErrUnsupported is returned by operations of Client,
+// which cache service doesn't provide
+var ErrUnsupported = errors.New("grpc: operation isn't supported by remote cache")
+
+// Client is gache.Cache, which is served by remote cache over gRPC.
+// Calls are spread over pool of connections. Deadline of context
+// passed to GetCtx and Warm is propagated to server, other calls
+// are limited by call timeout.
+//
+// Values are serialized by codec, so their concrete types are kept
+// between Go clients. Values stored by other clients, which can't be
+// decoded, are returned as byte slices. Operations, which cache service
+// doesn't provide, return ErrUnsupported, if they return error,
+// otherwise they have no effect, return zero values and pass
+// ErrUnsupported to error handler of client. Errors of calls, which
+// methods can't return, are passed to the handler too. Values
+// are read, changed and removed by separate calls, so GetOrSet,
+// GetOrCompute, Update, GetAndDelete and DelFunc aren't atomic.
+// Keys, Len, Range and DelFunc list values of remote group without
+// filling them. Non-positive live duration of values means expiration
+// of remote group is used. Group options are ignored. Group middlewares
+// are applied to groups returned by client
+type Client struct {
+	*remoteGroup
+
+	conns    []*grpc.ClientConn
+	stubs    []CacheClient
+	next     uint32
+	codec    gache.Codec
+	timeout  time.Duration
+	poolSize int
+	dialOpts []grpc.DialOption
+	onError  func(err error)
+
+	mx  sync.RWMutex
+	mws []gache.GroupMiddleware
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// ClientOption presents type of function, intended for
+// configuring client on creation
+type ClientOption func(*Client)
+
+// WithClientCodec sets codec, which serializes values. It must
+// be the same as codec of server to decode values stored on server
+// by other means. Concrete types of values must be registered,
+// if codec requires it. Default is gache.GobCodec
+func WithClientCodec(codec gache.Codec) ClientOption {
+	return func(c *Client) {
+		c.codec = codec
+	}
+}
+
+// WithPoolSize sets number of connections to server. Default is 4
+func WithPoolSize(n int) ClientOption {
+	return func(c *Client) {
+		if n < 1 {
+			n = 1
+		}
+		c.poolSize = n
+	}
+}
+
+// WithCallTimeout limits duration of calls, which don't take
+// context or take context without deadline. Zero duration
+// means calls aren't limited. Default is 5 seconds
+func WithCallTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithDialOptions adds options of connections to server,
+// e.g. transport credentials
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(c *Client) {
+		c.dialOpts = append(c.dialOpts, opts...)
+	}
+}
+
+// WithErrorHandler sets function, which handles errors of calls,
+// which methods of client can't return, and ErrUnsupported for
+// operations, which cache service doesn't provide. By default
+// they are ignored
+func WithErrorHandler(handler func(err error)) ClientOption {
+	return func(c *Client) {
+		c.onError = handler
+	}
+}
+
+// NewClient returns client of cache served at target
+func NewClient(target string, opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		codec:    gache.GobCodec{},
+		timeout:  5 * time.Second,
+		poolSize: 4,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for i := 0; i < c.poolSize; i++ {
+		conn, err := grpc.NewClient(target, c.dialOpts...)
+		if err != nil {
+			for _, conn := range c.conns {
+				conn.Close()
+			}
+			return nil, err
+		}
+		c.conns = append(c.conns, conn)
+		c.stubs = append(c.stubs, NewCacheClient(conn))
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.remoteGroup = &remoteGroup{client: c}
+
+	return c, nil
+}
+
+// stub returns client stub of next connection of pool
+func (c *Client) stub() CacheClient {
+	return c.stubs[atomic.AddUint32(&c.next, 1)%uint32(len(c.stubs))]
+}
+
+// report passes error of call to error handler, if both are set
+func (c *Client) report(err error) {
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// unsupported reports operation, which cache service doesn't provide
+func (c *Client) unsupported(op string) {
+	c.report(fmt.Errorf("%w: %s", ErrUnsupported, op))
+}
+
+// len returns number of values of group with specified key
+// or of all groups on server
+func (c *Client) len(group string, all bool) (int, error) {
+	ctx, cancel, err := c.callContext(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	resp, err := c.stub().Len(ctx, &LenRequest{Group: group, All: all})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(resp.Count), nil
+}
+
+// callContext returns context of call, which is limited
+// by call timeout, if ctx has no deadline.
+// Returns ErrCacheClosed if client is closed
+func (c *Client) callContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if c.ctx.Err() != nil {
+		return nil, nil, gache.ErrCacheClosed
+	}
+
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		return ctx, cancel, nil
+	}
+
+	return ctx, func() {}, nil
+}
+
+// watch returns channel, which delivers events streamed by server
+// for req, and function, which stops streaming and closes the channel.
+// Events, which receiver falls behind, are dropped
+func (c *Client) watch(req *WatchRequest, buffer int) (<-chan gache.Event, func()) {
+	ch := make(chan gache.Event, buffer)
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	stream, err := c.stub().Watch(ctx, req)
+	if err != nil {
+		cancel()
+		close(ch)
+		return ch, func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+
+		for {
+			ev, err := stream.Recv()
+			if err != nil {
+				return
+			}
+
+			event := gache.Event{Type: gache.EventType(ev.Type), Group: ev.Group, Key: ev.Key}
+			if ev.Value != nil {
+				event.Value = c.decode(ev.Value)
+			}
+
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}()
+
+	return ch, func() {
+		cancel()
+		<-done
+	}
+}
+
+// entry wraps value, so codec keeps its concrete type
+type entry struct {
+	Value interface{}
+}
+
+// encode returns representation of val sent to server
+func (c *Client) encode(val interface{}) ([]byte, error) {
+	return c.codec.Marshal(&entry{Value: val})
+}
+
+// decode returns value represented by data. Data,
+// which can't be decoded, is returned as is
+func (c *Client) decode(data []byte) interface{} {
+	var e entry
+	if err := c.codec.Unmarshal(data, &e); err != nil {
+		return data
+	}
+
+	return e.Value
+}
+
+// group returns remote group with specified key,
+// wrapped by middlewares of client
+func (c *Client) group(key string) gache.Group {
+	var g gache.Group = &remoteGroup{client: c, key: key}
+	if key == "" {
+		g = c.remoteGroup
+	}
+
+	c.mx.RLock()
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		g = c.mws[i](g)
+	}
+	c.mx.RUnlock()
+
+	return g
+}
+
+// direct returns remote group with specified key, which isn't
+// wrapped, if client has no middlewares
+func (c *Client) direct(key string) (*remoteGroup, bool) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	if len(c.mws) > 0 {
+		return nil, false
+	}
+
+	if key == "" {
+		return c.remoteGroup, true
+	}
+
+	return &remoteGroup{client: c, key: key}, true
+}
+
+// Group returns remote group with specified key.
+// Existence of group isn't checked
+func (c *Client) Group(key string) (gache.Group, bool) {
+	return c.group(key), true
+}
+
+// NewGroup does nothing, as remote groups are created on first store
+func (c *Client) NewGroup(key string, opts ...gache.GroupOption) error {
+	return nil
+}
+
+func (c *Client) NewGroupWithTTL(key string, ttl time.Duration, opts ...gache.GroupOption) error {
+	return ErrUnsupported
+}
+
+func (c *Client) GetOrCreateGroup(key string, opts ...gache.GroupOption) gache.Group {
+	return c.group(key)
+}
+
+func (c *Client) SetGroupDefaults(cascade bool, opts ...gache.GroupOption) {
+	c.unsupported("SetGroupDefaults")
+}
+
+func (c *Client) RenameGroup(oldKey, newKey string) error {
+	return ErrUnsupported
+}
+
+func (c *Client) CloneGroup(src, dst string) error {
+	return ErrUnsupported
+}
+
+func (c *Client) DelGroup(key string) error {
+	return ErrUnsupported
+}
+
+func (c *Client) Flush(deleteGroups bool) {
+	ctx, cancel, err := c.callContext(context.Background())
+	if err != nil {
+		c.report(err)
+		return
+	}
+	defer cancel()
+
+	_, err = c.stub().Flush(ctx, &FlushRequest{DeleteGroups: deleteGroups})
+	c.report(err)
+}
+
+func (c *Client) GetGroupVal(gkey, vkey string) (interface{}, error) {
+	val, ok := c.group(gkey).Get(vkey)
+	if !ok {
+		return nil, &gache.KeyError{Group: gkey, Key: vkey, Err: gache.ErrKeyNotFound}
+	}
+
+	return val, nil
+}
+
+func (c *Client) HasGroupVal(gkey, vkey string) bool {
+	return c.group(gkey).Has(vkey)
+}
+
+// SetGroupVal returns error of call, unless client has middlewares,
+// which may change storing, so error is passed to error handler
+func (c *Client) SetGroupVal(gkey, vkey string, val interface{}) error {
+	return c.SetGroupValWithTTL(gkey, vkey, val, 0)
+}
+
+// SetGroupValWithTTL returns error of call like SetGroupVal
+func (c *Client) SetGroupValWithTTL(gkey, vkey string, val interface{}, ttl time.Duration) error {
+	if g, ok := c.direct(gkey); ok {
+		return g.set(context.Background(), vkey, val, ttl)
+	}
+
+	c.group(gkey).SetWithTTL(vkey, val, ttl)
+
+	return nil
+}
+
+func (c *Client) Groups() []string {
+	keys, err := c.GroupKeys()
+	c.report(err)
+
+	return keys
+}
+
+// GroupKeys returns keys of groups on server like Groups,
+// but returns error of call instead of reporting it
+func (c *Client) GroupKeys() ([]string, error) {
+	ctx, cancel, err := c.callContext(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	resp, err := c.stub().Groups(ctx, &GroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Keys, nil
+}
+
+// Scan calls f for unexpired values of group with specified key
+// and their remaining live durations, until f returns false.
+// Zero duration means value doesn't expire. Values aren't filled
+// and aren't affected by middlewares of client. Returns error of call
+func (c *Client) Scan(gkey string, f func(key string, val interface{}, ttl time.Duration) bool) error {
+	return (&remoteGroup{client: c, key: gkey}).scan(f)
+}
+
+func (c *Client) TotalLen() int {
+	n, err := c.len("", true)
+	c.report(err)
+
+	return n
+}
+
+// GetGroupValMulti returns error of call like SetGroupVal
+func (c *Client) GetGroupValMulti(gkey string, vkeys []string) (map[string]interface{}, error) {
+	if g, ok := c.direct(gkey); ok {
+		return g.getMulti(context.Background(), vkeys)
+	}
+
+	return c.group(gkey).GetMulti(vkeys), nil
+}
+
+// SetGroupValMulti returns error of the first failed call like SetGroupVal
+func (c *Client) SetGroupValMulti(gkey string, vals map[string]interface{}) error {
+	if g, ok := c.direct(gkey); ok {
+		for key, val := range vals {
+			if err := g.set(context.Background(), key, val, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	c.group(gkey).SetMulti(vals)
+
+	return nil
+}
+
+// DelGroupValMulti returns error of call like SetGroupVal
+func (c *Client) DelGroupValMulti(gkey string, vkeys ...string) error {
+	if g, ok := c.direct(gkey); ok {
+		return g.del(context.Background(), vkeys...)
+	}
+
+	c.group(gkey).DelMulti(vkeys...)
+
+	return nil
+}
+
+func (c *Client) WrapFills(wrappers ...gache.FillWrapper) {
+	c.unsupported("WrapFills")
+}
+
+func (c *Client) WrapBatchFills(wrappers ...gache.BatchFillWrapper) {
+	c.unsupported("WrapBatchFills")
+}
+
+func (c *Client) Use(mw ...gache.GroupMiddleware) {
+	c.mx.Lock()
+	c.mws = append(c.mws, mw...)
+	c.mx.Unlock()
+}
+
+func (c *Client) RegisterPeers(picker gache.PeerPicker) {
+	c.unsupported("RegisterPeers")
+}
+
+func (c *Client) Subscribe(buffer int) (<-chan gache.Event, func()) {
+	if buffer < 1 {
+		buffer = watchBuffer
+	}
+
+	return c.watch(&WatchRequest{All: true}, buffer)
+}
+
+func (c *Client) OnEvicted(f gache.EvictFunc) {
+	c.unsupported("OnEvicted")
+}
+
+func (c *Client) OnGroupEvicted(f func(key string)) {
+	c.unsupported("OnGroupEvicted")
+}
+
+func (c *Client) OnFillPanic(f gache.FillPanicFunc) {
+	c.unsupported("OnFillPanic")
+}
+
+func (c *Client) OnInvalidValue(f gache.InvalidValueFunc) {
+	c.unsupported("OnInvalidValue")
+}
+
+func (c *Client) SaveTo(w io.Writer) error {
+	return ErrUnsupported
+}
+
+func (c *Client) LoadFrom(r io.Reader) error {
+	return ErrUnsupported
+}
+
+// Close stops watching of events and closes connections to server
+func (c *Client) Close() error {
+	err := gache.ErrCacheClosed
+	c.closeOnce.Do(func() {
+		err = nil
+		c.cancel()
+
+		for _, conn := range c.conns {
+			if cerr := conn.Close(); err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return err
+}
+
+func (c *Client) GroupStats() map[string]gache.Stats {
+	c.unsupported("GroupStats")
+	return nil
+}
+
+func (c *Client) RefreshPoolStats() gache.RefreshPoolStats {
+	c.unsupported("RefreshPoolStats")
+	return gache.RefreshPoolStats{}
+}
+
+func (c *Client) Quotas() map[string]gache.Quota {
+	c.unsupported("Quotas")
+	return nil
+}
+
+func (c *Client) Merge(other gache.Cache, policy gache.ConflictPolicy) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,543 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+// remoteGroup is gache.Group, which is served
+// by group of remote cache with the same key
+type remoteGroup struct {
+	client *Client
+	key    string
+}
+
+// get returns value with specified key from server with its
+// remaining live duration. Peeked value isn't filled by server
+func (g *remoteGroup) get(ctx context.Context, key string, peek bool) (interface{}, time.Duration, bool, error) {
+	ctx, cancel, err := g.client.callContext(ctx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer cancel()
+
+	resp, err := g.client.stub().Get(ctx, &GetRequest{Group: g.key, Key: key, Peek: peek})
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if !resp.Found {
+		return nil, 0, false, nil
+	}
+
+	return g.client.decode(resp.Value), time.Duration(resp.Ttl), true, nil
+}
+
+// set stores value for specified key on server
+func (g *remoteGroup) set(ctx context.Context, key string, val interface{}, ttl time.Duration) error {
+	data, err := g.client.encode(val)
+	if err != nil {
+		return err
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	ctx, cancel, err := g.client.callContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	req := &SetRequest{Group: g.key, Key: key, Value: data, Ttl: int64(ttl)}
+	_, err = g.client.stub().Set(ctx, req)
+
+	return err
+}
+
+// getMulti returns found values with specified keys from server
+func (g *remoteGroup) getMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	ctx, cancel, err := g.client.callContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	resp, err := g.client.stub().GetMulti(ctx, &GetMultiRequest{Group: g.key, Keys: keys})
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string]interface{}, len(resp.Values))
+	for key, data := range resp.Values {
+		vals[key] = g.client.decode(data)
+	}
+
+	return vals, nil
+}
+
+// del removes values with specified keys on server
+func (g *remoteGroup) del(ctx context.Context, keys ...string) error {
+	ctx, cancel, err := g.client.callContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	_, err = g.client.stub().Del(ctx, &DelRequest{Group: g.key, Keys: keys})
+
+	return err
+}
+
+// delPrefix removes values with keys starting with prefix
+// on server and returns number of removed values
+func (g *remoteGroup) delPrefix(prefix string) (int, error) {
+	ctx, cancel, err := g.client.callContext(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+
+	resp, err := g.client.stub().DelPrefix(ctx, &DelPrefixRequest{Group: g.key, Prefix: prefix})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(resp.Count), nil
+}
+
+// scan calls f for every unexpired value of group on server
+// in order of streaming, until f returns false
+func (g *remoteGroup) scan(f func(key string, val interface{}, ttl time.Duration) bool) error {
+	if g.client.ctx.Err() != nil {
+		return gache.ErrCacheClosed
+	}
+
+	ctx, cancel := context.WithCancel(g.client.ctx)
+	defer cancel()
+
+	stream, err := g.client.stub().Scan(ctx, &ScanRequest{Group: g.key})
+	if err != nil {
+		return err
+	}
+
+	for {
+		item, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if !f(item.Key, g.client.decode(item.Value), time.Duration(item.Ttl)) {
+			return nil
+		}
+	}
+}
+
+func (g *remoteGroup) Get(key string) (interface{}, bool) {
+	return g.GetCtx(context.Background(), key)
+}
+
+func (g *remoteGroup) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	val, _, ok, err := g.get(ctx, key, false)
+	g.client.report(err)
+
+	return val, ok
+}
+
+func (g *remoteGroup) Set(key string, val interface{}) {
+	g.client.report(g.set(context.Background(), key, val, 0))
+}
+
+func (g *remoteGroup) Touch(key string, ttl time.Duration) bool {
+	ctx, cancel, err := g.client.callContext(context.Background())
+	if err != nil {
+		g.client.report(err)
+		return false
+	}
+	defer cancel()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	resp, err := g.client.stub().Touch(ctx, &TouchRequest{Group: g.key, Key: key, Ttl: int64(ttl)})
+	if err != nil {
+		g.client.report(err)
+		return false
+	}
+
+	return resp.Found
+}
+
+// SetWithCost stores value without cost,
+// as cache service doesn't accept it
+func (g *remoteGroup) SetWithCost(key string, val interface{}, cost int64) {
+	g.Set(key, val)
+}
+
+func (g *remoteGroup) SetWithTTL(key string, val interface{}, ttl time.Duration) {
+	g.client.report(g.set(context.Background(), key, val, ttl))
+}
+
+func (g *remoteGroup) GetStale(key string) (interface{}, bool, bool) {
+	val, ok := g.Get(key)
+	return val, false, ok
+}
+
+// GetItem returns value with its expiration time and without
+// other metadata, which cache service doesn't provide
+func (g *remoteGroup) GetItem(key string) (gache.Item, bool) {
+	val, exp, ok := g.GetWithExpiration(key)
+	if !ok {
+		return gache.Item{}, false
+	}
+
+	return gache.Item{Value: val, Expiration: exp}, true
+}
+
+func (g *remoteGroup) GetWithExpiration(key string) (interface{}, time.Time, bool) {
+	val, ttl, ok, err := g.get(context.Background(), key, false)
+	g.client.report(err)
+	if !ok || ttl == 0 {
+		return val, time.Time{}, ok
+	}
+
+	return val, time.Now().Add(ttl), true
+}
+
+func (g *remoteGroup) TTL(key string) (time.Duration, bool) {
+	_, ttl, ok, err := g.get(context.Background(), key, true)
+	g.client.report(err)
+
+	return ttl, ok
+}
+
+func (g *remoteGroup) Peek(key string) (interface{}, bool) {
+	val, _, ok, err := g.get(context.Background(), key, true)
+	g.client.report(err)
+
+	return val, ok
+}
+
+func (g *remoteGroup) Has(key string) bool {
+	_, ok := g.Peek(key)
+	return ok
+}
+
+func (g *remoteGroup) Add(key string, val interface{}) error {
+	return ErrUnsupported
+}
+
+func (g *remoteGroup) Replace(key string, val interface{}) error {
+	return ErrUnsupported
+}
+
+func (g *remoteGroup) AddWithTTL(key string, val interface{}, ttl time.Duration) error {
+	return ErrUnsupported
+}
+
+func (g *remoteGroup) ReplaceWithTTL(key string, val interface{}, ttl time.Duration) error {
+	return ErrUnsupported
+}
+
+func (g *remoteGroup) GetOrSet(key string, val interface{}) (interface{}, bool) {
+	if old, ok := g.Get(key); ok {
+		return old, true
+	}
+
+	g.Set(key, val)
+
+	return val, false
+}
+
+func (g *remoteGroup) GetOrCompute(key string, compute func() (interface{}, bool)) (interface{}, bool) {
+	if val, ok := g.Get(key); ok {
+		return val, true
+	}
+
+	val, ok := compute()
+	if ok {
+		g.Set(key, val)
+	}
+
+	return val, ok
+}
+
+func (g *remoteGroup) Update(key string, fn func(old interface{}, exists bool) (interface{}, bool)) {
+	old, exists := g.Get(key)
+	if val, keep := fn(old, exists); keep {
+		g.Set(key, val)
+	} else if exists {
+		g.Del(key)
+	}
+}
+
+func (g *remoteGroup) Increment(key string, delta int64) (int64, error) {
+	return 0, ErrUnsupported
+}
+
+func (g *remoteGroup) Decrement(key string, delta int64) (int64, error) {
+	return 0, ErrUnsupported
+}
+
+// GetWithVersion returns value with zero version,
+// as cache service doesn't provide versions
+func (g *remoteGroup) GetWithVersion(key string) (interface{}, uint64, bool) {
+	val, ok := g.Get(key)
+	return val, 0, ok
+}
+
+func (g *remoteGroup) SetIfVersion(key string, val interface{}, version uint64) bool {
+	g.client.unsupported("SetIfVersion")
+	return false
+}
+
+func (g *remoteGroup) Del(key string) {
+	g.DelMulti(key)
+}
+
+func (g *remoteGroup) GetAndDelete(key string) (interface{}, bool) {
+	val, ok := g.Get(key)
+	if ok {
+		g.Del(key)
+	}
+
+	return val, ok
+}
+
+func (g *remoteGroup) PopOldest() (string, interface{}, bool) {
+	g.client.unsupported("PopOldest")
+	return "", nil, false
+}
+
+func (g *remoteGroup) PopRandom() (string, interface{}, bool) {
+	g.client.unsupported("PopRandom")
+	return "", nil, false
+}
+
+func (g *remoteGroup) DelPrefix(prefix string) int {
+	n, err := g.delPrefix(prefix)
+	g.client.report(err)
+
+	return n
+}
+
+func (g *remoteGroup) DelMatch(pattern string) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// DelFunc lists values of remote group and removes matching
+// ones, so values changed in between may be removed
+func (g *remoteGroup) DelFunc(match func(key string, val interface{}) bool) int {
+	var keys []string
+	err := g.scan(func(key string, val interface{}, ttl time.Duration) bool {
+		if match(key, val) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	if err != nil {
+		g.client.report(err)
+		return 0
+	}
+
+	if len(keys) == 0 {
+		return 0
+	}
+
+	if err := g.del(context.Background(), keys...); err != nil {
+		g.client.report(err)
+		return 0
+	}
+
+	return len(keys)
+}
+
+func (g *remoteGroup) DelOlderThan(age time.Duration) int {
+	g.client.unsupported("DelOlderThan")
+	return 0
+}
+
+func (g *remoteGroup) Watch(key string) (<-chan gache.Event, func()) {
+	return g.client.watch(&WatchRequest{Group: g.key, Key: key}, watchBuffer)
+}
+
+func (g *remoteGroup) WatchGroup() (<-chan gache.Event, func()) {
+	return g.client.watch(&WatchRequest{Group: g.key}, watchBuffer)
+}
+
+func (g *remoteGroup) Pin(key string) bool {
+	g.client.unsupported("Pin")
+	return false
+}
+
+func (g *remoteGroup) Unpin(key string) bool {
+	g.client.unsupported("Unpin")
+	return false
+}
+
+func (g *remoteGroup) Subgroup(key string, opts ...gache.GroupOption) gache.Group {
+	if g.key != "" {
+		key = g.key + gache.GroupSeparator + key
+	}
+
+	return g.client.group(key)
+}
+
+// Warm makes server load values with specified keys,
+// so they are filled by remote group
+func (g *remoteGroup) Warm(ctx context.Context, keys []string, opts ...gache.WarmOption) error {
+	_, err := g.getMulti(ctx, keys)
+	return err
+}
+
+func (g *remoteGroup) GetMulti(keys []string) map[string]interface{} {
+	vals, err := g.getMulti(context.Background(), keys)
+	if err != nil {
+		g.client.report(err)
+		return map[string]interface{}{}
+	}
+
+	return vals
+}
+
+func (g *remoteGroup) SetMulti(vals map[string]interface{}) {
+	g.Import(vals, 0)
+}
+
+func (g *remoteGroup) Import(vals map[string]interface{}, ttl time.Duration) {
+	for key, val := range vals {
+		if err := g.set(context.Background(), key, val, ttl); err != nil {
+			g.client.report(err)
+			return
+		}
+	}
+}
+
+func (g *remoteGroup) DelMulti(keys ...string) {
+	g.client.report(g.del(context.Background(), keys...))
+}
+
+func (g *remoteGroup) Clear() {
+	g.DelPrefix("")
+}
+
+func (g *remoteGroup) Keys() []string {
+	var keys []string
+	g.client.report(g.scan(func(key string, val interface{}, ttl time.Duration) bool {
+		keys = append(keys, key)
+		return true
+	}))
+
+	return keys
+}
+
+func (g *remoteGroup) Len() int {
+	n, err := g.client.len(g.key, false)
+	g.client.report(err)
+
+	return n
+}
+
+// Range calls f for values listed by server,
+// which are read before the first call of f
+func (g *remoteGroup) Range(f func(key string, val interface{}) bool) {
+	var (
+		keys []string
+		vals []interface{}
+	)
+	err := g.scan(func(key string, val interface{}, ttl time.Duration) bool {
+		keys = append(keys, key)
+		vals = append(vals, val)
+		return true
+	})
+	if err != nil {
+		g.client.report(err)
+		return
+	}
+
+	for i, key := range keys {
+		if !f(key, vals[i]) {
+			return
+		}
+	}
+}
+
+// Snapshot returns empty view, as views can't be built
+// from values of remote group. Use Scan to list them
+func (g *remoteGroup) Snapshot() gache.GroupView {
+	g.client.unsupported("Snapshot")
+	return gache.GroupView{}
+}
+
+func (g *remoteGroup) SetExpiration(expiration time.Duration, modes ...gache.ExpirationMode) {
+	g.client.unsupported("SetExpiration")
+}
+
+func (g *remoteGroup) SetFillFunc(fillFunc gache.FillFunc) {
+	g.client.unsupported("SetFillFunc")
+}
+
+func (g *remoteGroup) SetFillFuncCtx(fillFunc gache.FillFuncCtx) {
+	g.client.unsupported("SetFillFuncCtx")
+}
+
+func (g *remoteGroup) SetFillFuncTTL(fillFunc gache.FillFuncTTL) {
+	g.client.unsupported("SetFillFuncTTL")
+}
+
+func (g *remoteGroup) SetNamedFillFunc(name string) bool {
+	g.client.unsupported("SetNamedFillFunc")
+	return false
+}
+
+func (g *remoteGroup) SetBatchFillFunc(batchFillFunc gache.BatchFillFunc) {
+	g.client.unsupported("SetBatchFillFunc")
+}
+
+func (g *remoteGroup) SetRefreshPolicy(policy gache.RefreshPolicy, staleTTL time.Duration) {
+	g.client.unsupported("SetRefreshPolicy")
+}
+
+func (g *remoteGroup) Stats() gache.Stats {
+	g.client.unsupported("Stats")
+	return gache.Stats{}
+}
+
+func (g *remoteGroup) MemoryUsage() int64 {
+	g.client.unsupported("MemoryUsage")
+	return 0
+}
+
+func (g *remoteGroup) HotKeys(n int) []gache.HotKey {
+	g.client.unsupported("HotKeys")
+	return nil
+}
+
+func (g *remoteGroup) Freeze() {
+	g.client.unsupported("Freeze")
+}
+
+func (g *remoteGroup) Unfreeze() {
+	g.client.unsupported("Unfreeze")
+}
+
+func (g *remoteGroup) Frozen() bool {
+	return false
+}
+
+func (g *remoteGroup) SetQuota(maxEntries int, maxCost int64) {
+	g.client.unsupported("SetQuota")
+}
+
+func (g *remoteGroup) Quota() gache.Quota {
+	g.client.unsupported("Quota")
+	return gache.Quota{}
+}
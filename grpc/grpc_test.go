@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/kcasctiv/gache/cluster"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var _ cluster.Scanner = (*Client)(nil)
+
+// newTestConn serves cache over in-memory connection
+// and returns stub of cache service connected to it
+func newTestConn(t *testing.T, cache gache.Cache) CacheClient {
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	NewServer(cache).Register(srv)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	}
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewCacheClient(conn)
+}
+
+// newTestClient serves cache over in-memory connection
+// and returns client connected to it
+func newTestClient(t *testing.T, cache gache.Cache, opts ...ClientOption) *Client {
+	l := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	NewServer(cache).Register(srv)
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	}
+	opts = append([]ClientOption{WithDialOptions(
+		grpc.WithContextDialer(dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)}, opts...)
+
+	c, err := NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func TestServer(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	stub := newTestConn(t, cache)
+	ctx := context.Background()
+
+	if _, err := stub.Set(ctx, &SetRequest{Group: "g", Key: "a", Value: []byte("v"), Ttl: int64(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, ok := cache.GetOrCreateGroup("g").TTL("a"); !ok || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected value stored with TTL, got %v, %v", ttl, ok)
+	}
+
+	get, err := stub.Get(ctx, &GetRequest{Group: "g", Key: "a"})
+	if err != nil || !get.Found || string(get.Value) != "v" {
+		t.Fatalf("expected stored value, got %v, %v", get, err)
+	}
+
+	cache.Set("n", 1)
+	multi, err := stub.GetMulti(ctx, &GetMultiRequest{Keys: []string{"n", "missing"}})
+	if err != nil || len(multi.Values) != 1 {
+		t.Fatalf("expected single value, got %v, %v", multi, err)
+	}
+	var e entry
+	if err := (gache.GobCodec{}).Unmarshal(multi.Values["n"], &e); err != nil || e.Value != 1 {
+		t.Fatalf("expected value encoded by codec, got %v, %v", e.Value, err)
+	}
+
+	if _, err := stub.Del(ctx, &DelRequest{Group: "g", Keys: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if get, err := stub.Get(ctx, &GetRequest{Group: "g", Key: "a"}); err != nil || get.Found {
+		t.Fatalf("expected deleted value not found, got %v, %v", get, err)
+	}
+
+	if _, err := stub.Set(ctx, &SetRequest{}); err == nil {
+		t.Fatal("expected error for empty key")
+	}
+}
+
+func TestClient(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	c := newTestClient(t, cache)
+
+	c.Set("a", 1)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected 1, got %v, %v", v, ok)
+	}
+
+	g, _ := c.Group("g")
+	g.SetWithTTL("b", "x", time.Minute)
+	if v, err := cache.GetGroupVal("g", "b"); err != nil || string(v.([]byte)) == "" {
+		t.Fatalf("expected value stored on server, got %v, %v", v, err)
+	}
+
+	vals := g.GetMulti([]string{"b", "c"})
+	if len(vals) != 1 || vals["b"] != "x" {
+		t.Fatalf("expected only value of b, got %v", vals)
+	}
+
+	g.Del("b")
+	if _, ok := g.Get("b"); ok {
+		t.Fatal("expected deleted value")
+	}
+
+	cache.Set("raw", "text")
+	if v, _ := c.Get("raw"); string(v.([]byte)) != "text" {
+		t.Fatalf("expected raw bytes of server value, got %v", v)
+	}
+
+	cache.Set("num", 42)
+	if v, _ := c.Get("num"); v != 42 {
+		t.Fatalf("expected server value with concrete type, got %#v", v)
+	}
+}
+
+func TestClientTTL(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	c := newTestClient(t, cache)
+
+	c.SetWithTTL("a", 1, 1500*time.Microsecond+time.Hour)
+	ttl, ok := cache.TTL("a")
+	if !ok || ttl <= time.Hour || ttl > time.Hour+1500*time.Microsecond {
+		t.Fatalf("expected TTL with sub-millisecond precision, got %v, %v", ttl, ok)
+	}
+
+	if ttl, ok := c.TTL("a"); !ok || ttl <= 59*time.Minute {
+		t.Fatalf("expected remote TTL, got %v, %v", ttl, ok)
+	}
+
+	if !c.Touch("a", time.Minute) {
+		t.Fatal("expected touched value")
+	}
+	if ttl, ok := cache.TTL("a"); !ok || ttl > time.Minute {
+		t.Fatalf("expected touched TTL, got %v, %v", ttl, ok)
+	}
+	if c.Touch("missing", time.Minute) {
+		t.Fatal("expected missing value not to be touched")
+	}
+}
+
+func TestClientPeek(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	c := newTestClient(t, cache)
+
+	var fills int
+	g := cache.GetOrCreateGroup("g", gache.WithFillFunc(func(key string) (interface{}, bool) {
+		fills++
+		return "filled", true
+	}))
+	rg, _ := c.Group("g")
+
+	if _, ok := rg.Peek("a"); ok || fills != 0 {
+		t.Fatalf("expected peek without fill, got %v fills", fills)
+	}
+	if rg.Has("a") || fills != 0 {
+		t.Fatalf("expected has without fill, got %v fills", fills)
+	}
+	if v, ok := rg.Get("a"); !ok || string(v.([]byte)) != "filled" || fills != 1 {
+		t.Fatalf("expected filled value, got %v, %v, %d fills", v, ok, fills)
+	}
+	if !g.Has("a") {
+		t.Fatal("expected filled value stored on server")
+	}
+}
+
+func TestClientScan(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	c := newTestClient(t, cache)
+
+	g, _ := c.Group("g")
+	g.Set("a:1", 1)
+	g.Set("a:2", 2)
+	g.Set("b:1", 3)
+
+	keys := g.Keys()
+	sort.Strings(keys)
+	if len(keys) != 3 || keys[0] != "a:1" || keys[2] != "b:1" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if n := g.Len(); n != 3 {
+		t.Fatalf("expected 3 values, got %d", n)
+	}
+
+	sum := 0
+	g.Range(func(key string, val interface{}) bool {
+		sum += val.(int)
+		return true
+	})
+	if sum != 6 {
+		t.Fatalf("expected sum of values 6, got %d", sum)
+	}
+
+	if n := g.DelPrefix("a:"); n != 2 {
+		t.Fatalf("expected 2 deleted values, got %d", n)
+	}
+	if n := g.Len(); n != 1 {
+		t.Fatalf("expected 1 value left, got %d", n)
+	}
+
+	c.Set("root", 1)
+	groups := c.Groups()
+	if len(groups) != 1 || groups[0] != "g" {
+		t.Fatalf("expected group g, got %v", groups)
+	}
+	if n := c.TotalLen(); n != 2 {
+		t.Fatalf("expected 2 values in cache, got %d", n)
+	}
+
+	c.Clear()
+	if n := cache.TotalLen(); n != 1 || !g.Has("b:1") {
+		t.Fatalf("expected only root group cleared, got %d values", n)
+	}
+
+	c.Flush(true)
+	if n := cache.TotalLen(); n != 0 {
+		t.Fatalf("expected flushed cache, got %d values", n)
+	}
+	if _, ok := cache.Group("g"); ok {
+		t.Fatal("expected deleted group")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+
+	var (
+		mx   sync.Mutex
+		errs []error
+	)
+	c := newTestClient(t, cache, WithErrorHandler(func(err error) {
+		mx.Lock()
+		errs = append(errs, err)
+		mx.Unlock()
+	}))
+
+	c.Pin("a")
+	if len(errs) != 1 || !errors.Is(errs[0], ErrUnsupported) {
+		t.Fatalf("expected unsupported operation reported, got %v", errs)
+	}
+	if err := c.RenameGroup("g", "h"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected unsupported operation, got %v", err)
+	}
+
+	c.Close()
+	c.Set("a", 1)
+	if len(errs) != 2 || !errors.Is(errs[1], gache.ErrCacheClosed) {
+		t.Fatalf("expected failed call reported, got %v", errs)
+	}
+	if err := c.SetGroupVal("g", "a", 1); !errors.Is(err, gache.ErrCacheClosed) {
+		t.Fatalf("expected error of call, got %v", err)
+	}
+}
+
+func TestClientWatch(t *testing.T) {
+	cache := gache.NewCache()
+	defer cache.Close()
+	c := newTestClient(t, cache)
+
+	events, stop := c.Subscribe(8)
+	defer stop()
+
+	// stream is established asynchronously
+	deadline := time.After(time.Second)
+	for {
+		cache.Set("a", "v")
+		select {
+		case ev := <-events:
+			if ev.Type != gache.EventSet || ev.Key != "a" {
+				t.Fatalf("unexpected event %+v", ev)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("event wasn't delivered")
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package grpc serves gache.Cache as gRPC service defined
+// in cache.proto, so other services and non-Go processes
+// can use gache node as shared cache. Client implements
+// gache.Cache over the service, so local cache may be
+// replaced with remote one
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cache.proto
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements cache service backed by gache.Cache.
+// Values stored by clients are kept as byte slices and
+// returned as is. Strings are returned as their bytes,
+// other values are serialized by codec the same way
+// as Client does, so Go clients with the same codec
+// get them with their concrete types. Groups are
+// created on first store or watch
+type Server struct {
+	UnimplementedCacheServer
+
+	cache gache.Cache
+	codec gache.Codec
+}
+
+// Option presents type of function, intended for
+// configuring server on creation
+type Option func(*Server)
+
+// WithCodec sets codec, which serializes values, which aren't
+// byte slices or strings. Default is gache.GobCodec, as for Client
+func WithCodec(codec gache.Codec) Option {
+	return func(s *Server) {
+		s.codec = codec
+	}
+}
+
+// NewServer returns server of specified cache
+func NewServer(cache gache.Cache, opts ...Option) *Server {
+	s := &Server{
+		cache: cache,
+		codec: gache.GobCodec{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Register registers cache service on gRPC server r
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	RegisterCacheServer(r, s)
+}
+
+// Get returns value with specified key and its remaining
+// live duration. Peeked value isn't filled
+func (s *Server) Get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	g, ok := s.group(req.Group)
+	if !ok {
+		return &GetResponse{}, nil
+	}
+
+	var val interface{}
+	if req.Peek {
+		val, ok = g.Peek(req.Key)
+	} else {
+		val, ok = g.GetCtx(ctx, req.Key)
+	}
+	if !ok {
+		return &GetResponse{}, nil
+	}
+
+	data, err := s.encode(val)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode value: %v", err)
+	}
+
+	ttl, _ := g.TTL(req.Key)
+
+	return &GetResponse{Value: data, Found: true, Ttl: int64(ttl)}, nil
+}
+
+// Set stores value for specified key
+func (s *Server) Set(ctx context.Context, req *SetRequest) (*SetResponse, error) {
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+
+	g := s.groupOrCreate(req.Group)
+
+	val := req.Value
+	if val == nil {
+		val = []byte{}
+	}
+
+	if req.Ttl > 0 {
+		g.SetWithTTL(req.Key, val, time.Duration(req.Ttl))
+	} else {
+		g.Set(req.Key, val)
+	}
+
+	return &SetResponse{}, nil
+}
+
+// Del removes values with specified keys
+func (s *Server) Del(ctx context.Context, req *DelRequest) (*DelResponse, error) {
+	if g, ok := s.group(req.Group); ok {
+		g.DelMulti(req.Keys...)
+	}
+
+	return &DelResponse{}, nil
+}
+
+// GetMulti returns found values with specified keys
+func (s *Server) GetMulti(ctx context.Context, req *GetMultiRequest) (*GetMultiResponse, error) {
+	g, ok := s.group(req.Group)
+	if !ok {
+		return &GetMultiResponse{}, nil
+	}
+
+	vals := g.GetMulti(req.Keys)
+	resp := &GetMultiResponse{Values: make(map[string][]byte, len(vals))}
+	for key, val := range vals {
+		data, err := s.encode(val)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encode value %q: %v", key, err)
+		}
+		resp.Values[key] = data
+	}
+
+	return resp, nil
+}
+
+// Touch changes live duration of value with specified key
+func (s *Server) Touch(ctx context.Context, req *TouchRequest) (*TouchResponse, error) {
+	g, ok := s.group(req.Group)
+	if !ok {
+		return &TouchResponse{}, nil
+	}
+
+	return &TouchResponse{Found: g.Touch(req.Key, time.Duration(req.Ttl))}, nil
+}
+
+// DelPrefix removes values with keys starting with specified prefix
+func (s *Server) DelPrefix(ctx context.Context, req *DelPrefixRequest) (*DelPrefixResponse, error) {
+	g, ok := s.group(req.Group)
+	if !ok {
+		return &DelPrefixResponse{}, nil
+	}
+
+	if req.Group == "" {
+		// DelPrefix of cache removes values of all groups,
+		// so values of root group are matched by DelFunc
+		n := g.DelFunc(func(key string, _ interface{}) bool {
+			return strings.HasPrefix(key, req.Prefix)
+		})
+		return &DelPrefixResponse{Count: int64(n)}, nil
+	}
+
+	return &DelPrefixResponse{Count: int64(g.DelPrefix(req.Prefix))}, nil
+}
+
+// Len returns number of values of group or of whole cache
+func (s *Server) Len(ctx context.Context, req *LenRequest) (*LenResponse, error) {
+	if req.All {
+		return &LenResponse{Count: int64(s.cache.TotalLen())}, nil
+	}
+
+	g, ok := s.group(req.Group)
+	if !ok {
+		return &LenResponse{}, nil
+	}
+
+	return &LenResponse{Count: int64(g.Len())}, nil
+}
+
+// Groups returns keys of cache groups
+func (s *Server) Groups(ctx context.Context, req *GroupsRequest) (*GroupsResponse, error) {
+	return &GroupsResponse{Keys: s.cache.Groups()}, nil
+}
+
+// Flush removes values from all groups of cache
+func (s *Server) Flush(ctx context.Context, req *FlushRequest) (*FlushResponse, error) {
+	s.cache.Flush(req.DeleteGroups)
+	return &FlushResponse{}, nil
+}
+
+// Scan sends unexpired values of group with their remaining live
+// durations to stream. Values are taken from snapshot of group,
+// so they aren't filled and changes made during scan aren't sent
+func (s *Server) Scan(req *ScanRequest, stream grpc.ServerStreamingServer[Item]) error {
+	g, ok := s.group(req.Group)
+	if !ok {
+		return nil
+	}
+
+	view := g.Snapshot()
+	for key, item := range view.Items() {
+		data, err := s.encode(item.Value)
+		if err != nil {
+			return status.Errorf(codes.Internal, "encode value %q: %v", key, err)
+		}
+
+		var ttl time.Duration
+		if !item.Expiration.IsZero() {
+			if ttl = item.Expiration.Sub(view.Taken()); ttl <= 0 {
+				continue
+			}
+		}
+
+		if err := stream.Send(&Item{Key: key, Value: data, Ttl: int64(ttl)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Watch sends events to stream, until client cancels it or
+// cache is closed. Events, which client falls behind, are dropped.
+// Events with values, which can't be encoded, are sent without them
+func (s *Server) Watch(req *WatchRequest, stream grpc.ServerStreamingServer[Event]) error {
+	var (
+		events <-chan gache.Event
+		cancel func()
+	)
+	if req.All {
+		events, cancel = s.cache.Subscribe(0)
+	} else {
+		g := s.groupOrCreate(req.Group)
+		if req.Key != "" {
+			events, cancel = g.Watch(req.Key)
+		} else {
+			events, cancel = g.WatchGroup()
+		}
+	}
+	defer cancel()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			msg := &Event{Type: EventType(ev.Type), Group: ev.Group, Key: ev.Key}
+			if ev.Value != nil {
+				msg.Value, _ = s.encode(ev.Value)
+			}
+
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// group returns group with specified key,
+// empty key means root group of cache
+func (s *Server) group(key string) (gache.Group, bool) {
+	if key == "" {
+		return s.cache, true
+	}
+
+	return s.cache.Group(key)
+}
+
+// groupOrCreate returns group with specified key, creating it,
+// if it doesn't exist. Empty key means root group of cache
+func (s *Server) groupOrCreate(key string) gache.Group {
+	if key == "" {
+		return s.cache
+	}
+
+	return s.cache.GetOrCreateGroup(key)
+}
+
+// encode returns representation of val sent to clients
+func (s *Server) encode(val interface{}) ([]byte, error) {
+	switch v := val.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+
+	return s.codec.Marshal(&entry{Value: val})
+}
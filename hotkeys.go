@@ -0,0 +1,178 @@
+package gache
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HotKey presents frequently accessed key of group
+type HotKey struct {
+	// Key is key itself
+	Key string
+	// Count is estimated number of accesses to key
+	Count uint64
+	// LastAccess is time of last sampled access to key
+	LastAccess time.Time
+}
+
+// hotKeysPerStripe is minimal number of keys tracked by stripe
+// of hotKeys, so small trackers are exact Space-Saving summaries
+const hotKeysPerStripe = 64
+
+// maxHotKeyStripes limits number of stripes of hotKeys
+const maxHotKeyStripes = 16
+
+// hotKeys tracks most frequently accessed keys of group by
+// Space-Saving algorithm over sampled accesses. Counts of keys
+// are overestimated by at most count of evicted key. Keys are
+// partitioned into stripes by their hashes, so accesses to
+// different keys rarely contend for the same mutex
+type hotKeys struct {
+	every    uint64
+	accesses uint64
+	stripes  []*hotKeyStripe
+}
+
+// hotKeyStripe tracks keys of single partition of hotKeys.
+// Its keys are kept in min-heap by count, so least frequent
+// key is replaced without scanning stripe
+type hotKeyStripe struct {
+	capacity int
+	mx       sync.Mutex
+	keys     map[string]*hotKey
+	heap     hotKeyHeap
+}
+
+type hotKey struct {
+	key   string
+	count uint64
+	last  int64
+	index int
+}
+
+// hotKeyHeap is min-heap of tracked keys by their counts
+type hotKeyHeap []*hotKey
+
+func (h hotKeyHeap) Len() int           { return len(h) }
+func (h hotKeyHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+
+func (h hotKeyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *hotKeyHeap) Push(x interface{}) {
+	k := x.(*hotKey)
+	k.index = len(*h)
+	*h = append(*h, k)
+}
+
+func (h *hotKeyHeap) Pop() interface{} {
+	old := *h
+	k := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return k
+}
+
+func newHotKeys(capacity, every int) *hotKeys {
+	if every < 1 {
+		every = 1
+	}
+
+	n := min(max(capacity/hotKeysPerStripe, 1), maxHotKeyStripes)
+	h := &hotKeys{
+		every:   uint64(every),
+		stripes: make([]*hotKeyStripe, n),
+	}
+	for i := range h.stripes {
+		// first stripes take remainder of capacity
+		size := capacity / n
+		if i < capacity%n {
+			size++
+		}
+		h.stripes[i] = &hotKeyStripe{
+			capacity: size,
+			keys:     make(map[string]*hotKey, size),
+		}
+	}
+
+	return h
+}
+
+// record accounts access to key at now, if it is sampled
+func (h *hotKeys) record(key string, now int64) {
+	if atomic.AddUint64(&h.accesses, 1)%h.every != 0 {
+		return
+	}
+
+	s := h.stripes[0]
+	if len(h.stripes) > 1 {
+		s = h.stripes[hashKey(key)%uint64(len(h.stripes))]
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if k, ok := s.keys[key]; ok {
+		k.count += h.every
+		k.last = now
+		heap.Fix(&s.heap, k.index)
+		return
+	}
+
+	if len(s.keys) < s.capacity {
+		k := &hotKey{key: key, count: h.every, last: now}
+		s.keys[key] = k
+		heap.Push(&s.heap, k)
+		return
+	}
+
+	// least frequent key is replaced by accessed one,
+	// which inherits its count
+	k := s.heap[0]
+	delete(s.keys, k.key)
+	k.key = key
+	k.count += h.every
+	k.last = now
+	s.keys[key] = k
+	heap.Fix(&s.heap, 0)
+}
+
+// top returns n most frequently accessed keys
+func (h *hotKeys) top(n int) []HotKey {
+	var keys []HotKey
+	for _, s := range h.stripes {
+		s.mx.Lock()
+		for _, k := range s.keys {
+			keys = append(keys, HotKey{Key: k.key, Count: k.count, LastAccess: time.Unix(0, k.last)})
+		}
+		s.mx.Unlock()
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Count != keys[j].Count {
+			return keys[i].Count > keys[j].Count
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	if n >= 0 && n < len(keys) {
+		keys = keys[:n]
+	}
+
+	return keys
+}
+
+func (g *group) HotKeys(n int) []HotKey {
+	if g.hot == nil {
+		return nil
+	}
+
+	return g.hot.top(n)
+}
@@ -0,0 +1,107 @@
+package gache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHotKeys(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithHotKeyTracking(2, 1))
+	g, _ := c.Group("g")
+
+	for _, key := range []string{"a", "a", "a", "b", "c"} {
+		g.Get(key)
+	}
+
+	// c replaces b with its count
+	keys := g.HotKeys(-1)
+	if len(keys) != 2 || keys[0].Key != "a" || keys[0].Count != 3 || keys[1].Key != "c" || keys[1].Count != 2 {
+		t.Fatalf("expected a and c hot keys, got %+v", keys)
+	}
+	if keys := g.HotKeys(1); len(keys) != 1 || keys[0].Key != "a" {
+		t.Fatalf("expected the hottest key, got %+v", keys)
+	}
+
+	if keys := c.HotKeys(-1); keys != nil {
+		t.Fatalf("expected no hot keys without tracking, got %+v", keys)
+	}
+}
+
+func TestHotKeysSampling(t *testing.T) {
+	h := newHotKeys(10, 4)
+	for i := 0; i < 8; i++ {
+		h.record("a", 1)
+	}
+
+	if keys := h.top(-1); len(keys) != 1 || keys[0].Count != 8 {
+		t.Fatalf("expected sampled count scaled to 8, got %+v", keys)
+	}
+}
+
+func TestAdminHotKeys(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithHotKeyTracking(10, 1))
+	c.GetGroupVal("g", "a")
+
+	h := AdminHandler(c)
+	for _, tc := range []struct {
+		url  string
+		code int
+		body string
+	}{
+		{"/hotkeys?group=g&n=1", http.StatusOK, `"Key":"a","Count":1`},
+		{"/hotkeys", http.StatusOK, "[]"},
+		{"/hotkeys?group=g&n=x", http.StatusBadRequest, "invalid n"},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if rec.Code != tc.code || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Fatalf("expected %d %s for %s, got %d %s", tc.code, tc.body, tc.url, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestHotKeysReplacement(t *testing.T) {
+	h := newHotKeys(3, 1)
+	for _, key := range []string{"a", "a", "a", "b", "c", "c", "d", "d", "d"} {
+		h.record(key, 1)
+	}
+
+	// d replaces least frequent b with its count
+	keys := h.top(-1)
+	if len(keys) != 3 || keys[0].Key != "d" || keys[0].Count != 4 || keys[1].Key != "a" || keys[2].Key != "c" {
+		t.Fatalf("expected d, a and c hot keys, got %+v", keys)
+	}
+}
+
+func TestHotKeysStripes(t *testing.T) {
+	h := newHotKeys(1024, 1)
+	if len(h.stripes) != maxHotKeyStripes {
+		t.Fatalf("expected %d stripes, got %d", maxHotKeyStripes, len(h.stripes))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				h.record("hot", 1)
+				h.record(strconv.Itoa(i*1000+j), 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	keys := h.top(-1)
+	if len(keys) > 1024 || keys[0].Key != "hot" || keys[0].Count != 8000 {
+		t.Fatalf("expected hot key tracked exactly within capacity, got %d keys, %+v", len(keys), keys[0])
+	}
+}
@@ -0,0 +1,285 @@
+// Package httpcache provides http.RoundTripper, which caches
+// responses to GET requests in gache group
+package httpcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+// XFromCache is header, which is set to "1"
+// in responses served from cache
+const XFromCache = "X-From-Cache"
+
+// Transport is http.RoundTripper, which caches responses to GET
+// requests in group by their URLs. Freshness is taken from max-age
+// directive of Cache-Control or from Expires header, and stale
+// responses with ETag or Last-Modified are revalidated by conditional
+// requests. Group may be shared by clients, so responses marked
+// by private directive aren't stored, as well as no-store ones
+type Transport struct {
+	group     gache.Group
+	transport http.RoundTripper
+}
+
+// Option presents type of function, intended for
+// configuring transport on creation
+type Option func(*Transport)
+
+// WithTransport sets underlying transport, which performs
+// requests. Default is http.DefaultTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.transport = transport
+	}
+}
+
+// New returns transport, which caches responses in specified group
+func New(g gache.Group, opts ...Option) *Transport {
+	t := &Transport{
+		group:     g,
+		transport: http.DefaultTransport,
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Client returns HTTP client, which uses transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// entry presents cached response
+type entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Vary holds values of request headers,
+	// listed in Vary header of response
+	Vary    map[string]string
+	Expires time.Time
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.transport.RoundTrip(req)
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	now := time.Now()
+
+	var cached *entry
+	if val, ok := t.group.Get(key); ok {
+		if e, ok := val.(*entry); ok && e.matches(req) {
+			cached = e
+		}
+	}
+
+	if cached != nil {
+		if _, noCache := reqCC["no-cache"]; !noCache && now.Before(cached.Expires) {
+			return cached.response(req), nil
+		}
+
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-None-Match", etag)
+		} else if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-Modified-Since", lastModified)
+		} else {
+			cached = nil
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		e := *cached
+		e.Header = cached.Header.Clone()
+		for name, values := range resp.Header {
+			e.Header[name] = values
+		}
+		e.Expires = expires(e.Header, now)
+		t.group.Set(key, &e)
+
+		return e.response(req), nil
+	}
+
+	if !cacheable(resp, now) {
+		t.group.Del(key)
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.group.Set(key, &entry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Vary:       varyValues(resp.Header, req),
+		Expires:    expires(resp.Header, now),
+	})
+
+	return resp, nil
+}
+
+// matches reports whether request headers, listed in Vary
+// header of cached response, are equal to stored ones
+func (e *entry) matches(req *http.Request) bool {
+	for name, val := range e.Vary {
+		if req.Header.Get(name) != val {
+			return false
+		}
+	}
+
+	return true
+}
+
+// response returns new response for req with cached data
+func (e *entry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	header.Set(XFromCache, "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable reports whether response may be stored: it has
+// cacheable status, isn't forbidden to store or private,
+// and either has freshness lifetime or may be revalidated
+func cacheable(resp *http.Response, now time.Time) bool {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return false
+	}
+
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+	if resp.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	return expires(resp.Header, now).After(now) ||
+		resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// expires returns time, when response with specified headers,
+// received at now, becomes stale
+func expires(header http.Header, now time.Time) time.Time {
+	cc := parseCacheControl(header)
+	if _, ok := cc["no-cache"]; ok {
+		return now
+	}
+
+	if maxAge, ok := cc["max-age"]; ok {
+		seconds, err := strconv.ParseInt(maxAge, 10, 64)
+		if err != nil {
+			return now
+		}
+
+		age, _ := strconv.ParseInt(header.Get("Age"), 10, 64)
+		return now.Add(time.Duration(seconds-age) * time.Second)
+	}
+
+	if exp := header.Get("Expires"); exp != "" {
+		expTime, err := http.ParseTime(exp)
+		if err != nil {
+			return now
+		}
+
+		// Expires is relative to Date of origin server,
+		// so clock skew between hosts doesn't matter
+		if date, err := http.ParseTime(header.Get("Date")); err == nil {
+			return now.Add(expTime.Sub(date))
+		}
+
+		return expTime
+	}
+
+	return now
+}
+
+// varyValues returns values of request headers,
+// listed in Vary header of response
+func varyValues(header http.Header, req *http.Request) map[string]string {
+	var vals map[string]string
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			if vals == nil {
+				vals = make(map[string]string)
+			}
+			vals[name] = req.Header.Get(name)
+		}
+	}
+
+	return vals
+}
+
+// parseCacheControl returns directives of Cache-Control
+// header with their values
+func parseCacheControl(header http.Header) map[string]string {
+	cc := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, val := directive, ""
+			if i := strings.IndexByte(directive, '='); i >= 0 {
+				name, val = directive[:i], directive[i+1:]
+			}
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+
+	return cc
+}
@@ -0,0 +1,184 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kcasctiv/gache"
+)
+
+// origin presents test server, which counts requests
+type origin struct {
+	*httptest.Server
+	requests int32
+}
+
+func newOrigin(t *testing.T, handler http.HandlerFunc) *origin {
+	o := &origin{}
+	o.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&o.requests, 1)
+		handler(w, r)
+	}))
+	t.Cleanup(o.Close)
+
+	return o
+}
+
+func (o *origin) count() int {
+	return int(atomic.LoadInt32(&o.requests))
+}
+
+func newClient(t *testing.T) *http.Client {
+	c := gache.NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	return New(c).Client()
+}
+
+// fetch performs request and returns body and whether
+// response was served from cache
+func fetch(t *testing.T, client *http.Client, method, url string) (string, bool) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body), resp.Header.Get(XFromCache) == "1"
+}
+
+func TestFreshResponse(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "fresh")
+	})
+	client := newClient(t)
+
+	if body, cached := fetch(t, client, http.MethodGet, o.URL); body != "fresh" || cached {
+		t.Fatalf("expected response from origin, got %q, %v", body, cached)
+	}
+	if body, cached := fetch(t, client, http.MethodGet, o.URL); body != "fresh" || !cached {
+		t.Fatalf("expected response from cache, got %q, %v", body, cached)
+	}
+	if n := o.count(); n != 1 {
+		t.Fatalf("expected 1 request to origin, got %d", n)
+	}
+}
+
+func TestRevalidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string
+		condition string
+		value     string
+	}{
+		{"etag", "ETag", "If-None-Match", `"v1"`},
+		{"last modified", "Last-Modified", "If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(tt.validator, tt.value)
+				if r.Header.Get(tt.condition) == tt.value {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				io.WriteString(w, "body")
+			})
+			client := newClient(t)
+
+			if body, cached := fetch(t, client, http.MethodGet, o.URL); body != "body" || cached {
+				t.Fatalf("expected response from origin, got %q, %v", body, cached)
+			}
+			// stale response is revalidated and served from cache
+			if body, cached := fetch(t, client, http.MethodGet, o.URL); body != "body" || !cached {
+				t.Fatalf("expected revalidated response from cache, got %q, %v", body, cached)
+			}
+			if n := o.count(); n != 2 {
+				t.Fatalf("expected 2 requests to origin, got %d", n)
+			}
+		})
+	}
+}
+
+func TestNoStore(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/no-store") {
+			w.Header().Set("Cache-Control", "no-store")
+		} else if strings.HasSuffix(r.URL.Path, "/private") {
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		io.WriteString(w, r.URL.Path)
+	})
+	client := newClient(t)
+
+	fetch(t, client, http.MethodGet, o.URL+"/no-store")
+	if _, cached := fetch(t, client, http.MethodGet, o.URL+"/no-store"); cached {
+		t.Fatal("expected no-store response to bypass cache")
+	}
+
+	fetch(t, client, http.MethodGet, o.URL+"/private")
+	if _, cached := fetch(t, client, http.MethodGet, o.URL+"/private"); cached {
+		t.Fatal("expected private response to bypass cache")
+	}
+
+	// no-store of request bypasses cache as well
+	req, _ := http.NewRequest(http.MethodGet, o.URL+"/fresh", nil)
+	req.Header.Set("Cache-Control", "no-store")
+	for i := 0; i < 2; i++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.Header.Get(XFromCache) != "" {
+			t.Fatal("expected no-store request to bypass cache")
+		}
+	}
+
+	if n := o.count(); n != 6 {
+		t.Fatalf("expected 6 requests to origin, got %d", n)
+	}
+}
+
+func TestNonGET(t *testing.T) {
+	o := newOrigin(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, r.Method)
+	})
+	client := newClient(t)
+
+	for i := 0; i < 2; i++ {
+		if body, cached := fetch(t, client, http.MethodPost, o.URL); body != http.MethodPost || cached {
+			t.Fatalf("expected POST to pass through, got %q, %v", body, cached)
+		}
+	}
+	if n := o.count(); n != 2 {
+		t.Fatalf("expected 2 requests to origin, got %d", n)
+	}
+
+	// non-GET requests don't see cached responses either
+	fetch(t, client, http.MethodGet, o.URL)
+	if body, cached := fetch(t, client, http.MethodPut, o.URL); body != http.MethodPut || cached {
+		t.Fatalf("expected PUT to pass through, got %q, %v", body, cached)
+	}
+}
@@ -0,0 +1,251 @@
+package gache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/kcasctiv/gache/consistenthash"
+)
+
+const (
+	defaultPoolPath     = "/_gache/"
+	defaultPoolReplicas = 50
+	// missingGroupHeader marks response for group, which
+	// doesn't exist on peer, so it isn't taken for missing value
+	missingGroupHeader = "X-Gache-Missing-Group"
+)
+
+// HTTPPool is PeerPicker, which distributes values between cache
+// nodes by consistent hashing of their keys and fetches values
+// from owner nodes over HTTP. It is http.Handler as well, which
+// serves values of local cache to peers
+type HTTPPool struct {
+	self     string
+	path     string
+	replicas int
+	codec    Codec
+	client   *http.Client
+	cache    Cache
+
+	mx    sync.RWMutex
+	ring  *consistenthash.Map
+	peers map[string]*httpPeer
+}
+
+// HTTPPoolOption presents type of function, intended for
+// configuring HTTP pool on creation
+type HTTPPoolOption func(*HTTPPool)
+
+// WithPoolPath sets path, which pool is served at.
+// Default is "/_gache/"
+func WithPoolPath(path string) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.path = path
+	}
+}
+
+// WithPoolReplicas sets number of virtual nodes
+// per node on hash ring. Default is 50
+func WithPoolReplicas(replicas int) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.replicas = replicas
+	}
+}
+
+// WithPoolCodec sets codec, which serializes values
+// sent between peers, unless their group has its own codec.
+// Default is GobCodec
+func WithPoolCodec(codec Codec) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.codec = codec
+	}
+}
+
+// WithPoolClient sets HTTP client, which is used
+// for requests to peers. Default is http.DefaultClient
+func WithPoolClient(client *http.Client) HTTPPoolOption {
+	return func(p *HTTPPool) {
+		p.client = client
+	}
+}
+
+// NewHTTPPool returns pool of cache nodes, where self is base URL
+// of current node, e.g. "http://10.0.0.1:8080", and registers it
+// as peer picker of cache. Pool must be served at its path
+// on every node
+func NewHTTPPool(self string, c Cache, opts ...HTTPPoolOption) *HTTPPool {
+	p := &HTTPPool{
+		self:     self,
+		path:     defaultPoolPath,
+		replicas: defaultPoolReplicas,
+		codec:    GobCodec{},
+		client:   http.DefaultClient,
+		cache:    c,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.Set()
+	c.RegisterPeers(p)
+
+	return p
+}
+
+// Set replaces list of pool nodes with specified base URLs.
+// It should contain URL of current node
+func (p *HTTPPool) Set(peers ...string) {
+	ring := consistenthash.New(p.replicas, nil)
+	ring.Add(peers...)
+
+	httpPeers := make(map[string]*httpPeer, len(peers))
+	for _, peer := range peers {
+		httpPeers[peer] = &httpPeer{pool: p, url: peer + p.path}
+	}
+
+	p.mx.Lock()
+	p.ring = ring
+	p.peers = httpPeers
+	p.mx.Unlock()
+}
+
+// PickPeer implements PeerPicker
+func (p *HTTPPool) PickPeer(group, key string) (Peer, bool) {
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	owner := p.ring.Get(group + "/" + key)
+	if owner == "" || owner == p.self {
+		return nil, false
+	}
+
+	return p.peers[owner], true
+}
+
+// ServeHTTP serves values of local cache to peers.
+// Value is selected by "group" and "key" query parameters.
+// Peers fill values of groups, which don't exist on node, locally
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	gkey, key := q.Get("group"), q.Get("key")
+
+	g, ok := p.group(gkey)
+	if !ok {
+		w.Header().Set(missingGroupHeader, "1")
+		http.Error(w, fmt.Sprintf("group with key %q doesn't exist", gkey), http.StatusNotFound)
+		return
+	}
+
+	val, ok := g.GetCtx(withoutPeers(r.Context()), key)
+	if !ok {
+		http.Error(w, fmt.Sprintf("value with key %q not found", key), http.StatusNotFound)
+		return
+	}
+
+	data, err := p.codecOf(gkey).Marshal(&peerEntry{Value: val})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(data)
+}
+
+// group returns group of local cache with specified key.
+// Empty key means root group
+func (p *HTTPPool) group(key string) (Group, bool) {
+	if key == "" {
+		return p.cache, true
+	}
+
+	return p.cache.Group(key)
+}
+
+// codecOf returns codec of group with specified key,
+// if it is set, or codec of pool. Key is used instead of group
+// itself, since groups may be decorated by middlewares
+func (p *HTTPPool) codecOf(gkey string) Codec {
+	var codec Codec
+	if c, ok := p.cache.(*cache); ok {
+		c.mx.RLock()
+		g, ok := c.groups[gkey]
+		c.mx.RUnlock()
+
+		if gkey == "" {
+			g, ok = c.group, true
+		}
+		if ok {
+			codec = g.codec
+		}
+	}
+
+	if codec == nil {
+		return p.codec
+	}
+
+	return codec
+}
+
+// peerEntry wraps value, so codec keeps its concrete type
+type peerEntry struct {
+	Value interface{}
+}
+
+type httpPeer struct {
+	pool *HTTPPool
+	url  string
+}
+
+func (h *httpPeer) Get(ctx context.Context, group, key string) (interface{}, bool, error) {
+	u := h.url + "?" + url.Values{"group": {group}, "key": {key}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := h.pool.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	// group may be not created on peer yet,
+	// so value is filled locally
+	if resp.StatusCode == http.StatusNotFound && resp.Header.Get(missingGroupHeader) != "" {
+		return nil, false, &GroupError{Group: group, Err: ErrGroupNotFound}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+
+	var body bytes.Buffer
+	if _, err := io.Copy(&body, resp.Body); err != nil {
+		return nil, false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("peer %s returned %s: %s", h.url, resp.Status, bytes.TrimSpace(body.Bytes()))
+	}
+
+	codec := h.pool.codecOf(group)
+
+	var e peerEntry
+	if err := codec.Unmarshal(body.Bytes(), &e); err != nil {
+		return nil, false, err
+	}
+
+	return e.Value, true, nil
+}
@@ -0,0 +1,129 @@
+package gache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// newPoolNode returns cache, which fills values of group g
+// with its name, served by HTTP pool
+func newPoolNode(t *testing.T, name string) (Cache, *HTTPPool, string) {
+	var pool *HTTPPool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pool.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		return name, true
+	}))
+	pool = NewHTTPPool(srv.URL, c)
+
+	return c, pool, srv.URL
+}
+
+func TestHTTPPool(t *testing.T) {
+	a, poolA, urlA := newPoolNode(t, "a")
+	b, poolB, urlB := newPoolNode(t, "b")
+	poolA.Set(urlA, urlB)
+	poolB.Set(urlA, urlB)
+
+	owners := make(map[interface{}]int)
+	for i := 0; i < 20; i++ {
+		key := strconv.Itoa(i)
+
+		want := "a"
+		if _, remote := poolA.PickPeer("g", key); remote {
+			want = "b"
+		}
+
+		v, err := a.GetGroupVal("g", key)
+		if err != nil || v != want {
+			t.Fatalf("expected value of %s filled by owner %s, got %v, %v", key, want, v, err)
+		}
+		if v, err := b.GetGroupVal("g", key); err != nil || v != want {
+			t.Fatalf("expected same owner %s on other node, got %v, %v", want, v, err)
+		}
+		owners[v]++
+	}
+
+	if len(owners) != 2 {
+		t.Fatalf("expected keys spread between nodes, got %v", owners)
+	}
+}
+
+func TestHTTPPoolUnavailablePeer(t *testing.T) {
+	a, poolA, urlA := newPoolNode(t, "a")
+	poolA.Set(urlA, "http://127.0.0.1:1")
+
+	for i := 0; i < 20; i++ {
+		if v, err := a.GetGroupVal("g", strconv.Itoa(i)); err != nil || v != "a" {
+			t.Fatalf("expected local fill when peer fails, got %v, %v", v, err)
+		}
+	}
+}
+
+func TestHTTPPoolGroupCodec(t *testing.T) {
+	type point struct{ X, Y int }
+
+	var caches []Cache
+	var pools []*HTTPPool
+	var urls []string
+	for i := 0; i < 2; i++ {
+		c, pool, url := newPoolNode(t, strconv.Itoa(i))
+		c.NewGroup("j", WithGroupCodec(JSONCodec{}), WithFillFunc(func(key string) (interface{}, bool) {
+			return point{1, 2}, true
+		}))
+		caches, pools, urls = append(caches, c), append(pools, pool), append(urls, url)
+	}
+	for _, pool := range pools {
+		pool.Set(urls...)
+	}
+
+	for i := 0; i < 20; i++ {
+		key := strconv.Itoa(i)
+		var want interface{} = point{1, 2}
+		if _, remote := pools[0].PickPeer("j", key); remote {
+			// unregistered type can't be sent with gob,
+			// so JSON codec of group decodes it generically
+			want = map[string]interface{}{"X": 1.0, "Y": 2.0}
+		}
+
+		v, err := caches[0].GetGroupVal("j", key)
+		if err != nil || !reflect.DeepEqual(v, want) {
+			t.Fatalf("expected %v for %s, got %v, %v", want, key, v, err)
+		}
+	}
+}
+
+func TestHTTPPoolMissingGroup(t *testing.T) {
+	owner := NewCache()
+	defer owner.Close()
+	srv := httptest.NewServer(NewHTTPPool("owner", owner))
+	defer srv.Close()
+
+	c := NewCache()
+	defer c.Close()
+	pool := NewHTTPPool("self", c)
+	pool.Set(srv.URL)
+
+	g := c.GetOrCreateGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		return "local", true
+	}))
+	if v, ok := g.Get("a"); !ok || v != "local" {
+		t.Fatalf("expected local fill for group missing on owner, got %v, %v", v, ok)
+	}
+
+	owner.GetOrCreateGroup("h")
+	h := c.GetOrCreateGroup("h", WithFillFunc(func(key string) (interface{}, bool) {
+		return "local", true
+	}))
+	if v, ok := h.Get("a"); ok {
+		t.Fatalf("expected miss of value missing on owner, got %v", v)
+	}
+}
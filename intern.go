@@ -0,0 +1,98 @@
+package gache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// internShards is number of shards of interner, so writes
+// to different groups rarely contend for the same lock
+const internShards = 64
+
+// interner presents set of keys of values,
+// which are shared by all cache groups
+type interner struct {
+	shards [internShards]internShard
+	bytes  int64
+}
+
+// internShard presents part of interned keys,
+// guarded by its own mutex
+type internShard struct {
+	mx   sync.Mutex
+	keys map[string]*internedKey
+}
+
+// internedKey presents shared key and number of its uses
+type internedKey struct {
+	key  string
+	refs int
+}
+
+func newInterner() *interner {
+	in := &interner{}
+	for i := range in.shards {
+		in.shards[i].keys = make(map[string]*internedKey)
+	}
+
+	return in
+}
+
+// shard returns shard of interner, which holds key
+func (in *interner) shard(key string) *internShard {
+	return &in.shards[hashKey(key)%internShards]
+}
+
+// intern returns shared copy of key and accounts its use.
+// Nil interner returns key as is
+func (in *interner) intern(key string) string {
+	if in == nil {
+		return key
+	}
+
+	s := in.shard(key)
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if k, ok := s.keys[key]; ok {
+		k.refs++
+		atomic.AddInt64(&in.bytes, int64(len(key)))
+		return k.key
+	}
+
+	s.keys[key] = &internedKey{key: key, refs: 1}
+
+	return key
+}
+
+// release accounts end of use of key, forgetting it,
+// when it isn't used anymore
+func (in *interner) release(key string) {
+	if in == nil {
+		return
+	}
+
+	s := in.shard(key)
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	k, ok := s.keys[key]
+	if !ok {
+		return
+	}
+
+	if k.refs--; k.refs == 0 {
+		delete(s.keys, key)
+	} else {
+		atomic.AddInt64(&in.bytes, -int64(len(key)))
+	}
+}
+
+// saved returns number of bytes saved by sharing keys
+func (in *interner) saved() int64 {
+	if in == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&in.bytes)
+}
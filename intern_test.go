@@ -0,0 +1,43 @@
+package gache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeyInterning(t *testing.T) {
+	c := NewCache(WithKeyInterning())
+	t.Cleanup(func() { c.Close() })
+
+	key := strings.Repeat("k", 10)
+	for _, name := range []string{"a", "b", "c"} {
+		c.GetOrCreateGroup(name).Set(key, name)
+	}
+	if saved := c.Stats().KeyBytesSaved; saved != 20 {
+		t.Fatalf("expected 20 bytes saved, got %d", saved)
+	}
+
+	a, _ := c.Group("a")
+	a.Set(key, "again")
+	if saved := c.Stats().KeyBytesSaved; saved != 20 {
+		t.Fatalf("expected overwriting to keep savings, got %d", saved)
+	}
+
+	a.Del(key)
+	if saved := c.Stats().KeyBytesSaved; saved != 10 {
+		t.Fatalf("expected 10 bytes saved after deletion, got %d", saved)
+	}
+
+	if err := c.RenameGroup("b", "d"); err != nil {
+		t.Fatal(err)
+	}
+	if saved := c.Stats().KeyBytesSaved; saved != 10 {
+		t.Fatalf("expected renaming to keep savings, got %d", saved)
+	}
+
+	c.DelGroup("d")
+	c.DelGroup("c")
+	if saved := c.Stats().KeyBytesSaved; saved != 0 {
+		t.Fatalf("expected no savings without values, got %d", saved)
+	}
+}
@@ -0,0 +1,116 @@
+package gache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// InvalidationKind presents kind of invalidation message
+type InvalidationKind int
+
+const (
+	// InvalidateKeys means values with specified keys
+	// were deleted from group
+	InvalidateKeys InvalidationKind = iota
+	// InvalidateGroup means group was deleted
+	InvalidateGroup
+	// InvalidateAll means cache was flushed
+	InvalidateAll
+)
+
+// Invalidation presents message about values deleted
+// on one instance of cache, which other instances
+// apply locally
+type Invalidation struct {
+	// Source is ID of instance, which sent message
+	Source string `json:"source"`
+	// Kind is kind of message
+	Kind InvalidationKind `json:"kind"`
+	// Group is key of group, which values or itself were deleted
+	Group string `json:"group,omitempty"`
+	// Keys are keys of deleted values
+	Keys []string `json:"keys,omitempty"`
+	// DeleteGroups reports whether flush deleted groups
+	DeleteGroups bool `json:"delete_groups,omitempty"`
+}
+
+// Invalidator presents transport, e.g. Redis pub/sub or NATS,
+// which broadcasts invalidation messages between instances
+// of cache
+type Invalidator interface {
+	// Publish sends message to all subscribed instances
+	Publish(ctx context.Context, inv Invalidation) error
+	// Subscribe starts passing received messages to handler
+	Subscribe(handler func(inv Invalidation)) error
+	// Close stops receiving messages and releases resources
+	Close() error
+}
+
+// newInstanceID returns random ID, which distinguishes
+// messages of instance from messages of others
+func newInstanceID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// subscribe starts applying invalidation messages of other instances
+func (c *cache) subscribe() {
+	if c.invalidator == nil {
+		return
+	}
+
+	if err := c.invalidator.Subscribe(c.invalidate); err != nil {
+		c.invalidationError(err)
+	}
+}
+
+// broadcast sends invalidation message to other instances,
+// if invalidator is set
+func (c *cache) broadcast(inv Invalidation) {
+	if c.invalidator == nil {
+		return
+	}
+
+	inv.Source = c.id
+	if err := c.invalidator.Publish(context.Background(), inv); err != nil {
+		c.invalidationError(err)
+	}
+}
+
+// invalidate applies invalidation message of other instance.
+// Values are deleted only from memory, because sender already
+// deleted them from store of cache
+func (c *cache) invalidate(inv Invalidation) {
+	if inv.Source == c.id {
+		return
+	}
+
+	switch inv.Kind {
+	case InvalidateKeys:
+		g := c.group
+		if inv.Group != "" {
+			c.mx.RLock()
+			g = c.groups[inv.Group]
+			c.mx.RUnlock()
+		}
+
+		if g != nil {
+			g.remove(inv.Keys...)
+		}
+	case InvalidateGroup:
+		c.delGroup(inv.Group)
+	case InvalidateAll:
+		c.flush(inv.DeleteGroups)
+	}
+}
+
+// invalidationError passes error of invalidator
+// to handler of cache, if it is set
+func (c *cache) invalidationError(err error) {
+	if c.invalidationErrorHandler != nil {
+		c.invalidationErrorHandler(err)
+	}
+}
@@ -0,0 +1,107 @@
+package gache
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// bus presents in-memory transport of invalidation messages
+type bus struct {
+	mx       sync.Mutex
+	handlers []func(inv Invalidation)
+}
+
+// invalidator returns Invalidator, connected to bus
+func (b *bus) invalidator() Invalidator {
+	return &busInvalidator{bus: b}
+}
+
+type busInvalidator struct {
+	bus *bus
+}
+
+func (i *busInvalidator) Publish(_ context.Context, inv Invalidation) error {
+	i.bus.mx.Lock()
+	handlers := i.bus.handlers
+	i.bus.mx.Unlock()
+
+	for _, handler := range handlers {
+		handler(inv)
+	}
+
+	return nil
+}
+
+func (i *busInvalidator) Subscribe(handler func(inv Invalidation)) error {
+	i.bus.mx.Lock()
+	i.bus.handlers = append(i.bus.handlers, handler)
+	i.bus.mx.Unlock()
+
+	return nil
+}
+
+func (i *busInvalidator) Close() error {
+	return nil
+}
+
+func TestInvalidation(t *testing.T) {
+	var b bus
+	a := NewCache(WithInvalidator(b.invalidator()))
+	defer a.Close()
+	other := NewCache(WithInvalidator(b.invalidator()))
+	defer other.Close()
+
+	for _, c := range []Cache{a, other} {
+		c.Set("x", 1)
+		c.Set("y", 2)
+		c.NewGroup("g")
+		c.SetGroupVal("g", "z", 3)
+		c.NewGroup("h")
+	}
+
+	a.Del("x")
+	if _, ok := other.Get("x"); ok {
+		t.Fatal("expected deleted value invalidated on other instance")
+	}
+	if _, ok := other.Get("y"); !ok {
+		t.Fatal("expected other values to stay")
+	}
+
+	a.DelGroupValMulti("g", "z")
+	if _, err := other.GetGroupVal("g", "z"); err == nil {
+		t.Fatal("expected deleted group value invalidated on other instance")
+	}
+
+	a.DelGroup("h")
+	if _, ok := other.Group("h"); ok {
+		t.Fatal("expected deleted group invalidated on other instance")
+	}
+
+	a.Flush(true)
+	if groups := other.Groups(); len(groups) != 0 || other.TotalLen() != 0 {
+		t.Fatalf("expected flushed other instance, got groups %v and %d values", groups, other.TotalLen())
+	}
+}
+
+func TestClearInvalidation(t *testing.T) {
+	var b bus
+	l2 := newMapStore()
+	a := NewCache(WithInvalidator(b.invalidator()), WithStore(l2))
+	defer a.Close()
+	other := NewCache(WithInvalidator(b.invalidator()))
+	defer other.Close()
+
+	for _, c := range []Cache{a, other} {
+		c.GetOrCreateGroup("g").Set("x", 1)
+	}
+
+	g, _ := a.Group("g")
+	g.Clear()
+	if _, ok := l2.value("g/x"); ok {
+		t.Fatal("expected cleared value removed from store")
+	}
+	if _, err := other.GetGroupVal("g", "x"); err == nil {
+		t.Fatal("expected cleared value invalidated on other instance")
+	}
+}
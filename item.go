@@ -0,0 +1,67 @@
+package gache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Item presents group value with its metadata
+type Item struct {
+	// Value is value itself
+	Value interface{}
+	// Created is time, when value was stored
+	Created time.Time
+	// Expiration is time, when value expires.
+	// Zero time means value never expires
+	Expiration time.Time
+	// LastAccess is time of last access to value.
+	// Zero time means value wasn't accessed
+	LastAccess time.Time
+	// AccessCount is number of accesses to value
+	AccessCount uint64
+}
+
+// access holds statistics of accesses to value. It is shared by
+// copies of value, so hits under read lock may update it atomically
+type access struct {
+	last  int64
+	count uint64
+}
+
+// record accounts access to value at now
+func (a *access) record(now int64) {
+	atomic.StoreInt64(&a.last, now)
+	atomic.AddUint64(&a.count, 1)
+}
+
+func (g *group) GetItem(key string) (Item, bool) {
+	now := g.now()
+
+	s := g.shard(key)
+	s.mx.RLock()
+	v, ok := s.values[key]
+	s.mx.RUnlock()
+
+	if !ok || v.expired(now.UnixNano()) || v.absent() {
+		return Item{}, false
+	}
+
+	data, ok := g.read(key, v)
+	if !ok {
+		return Item{}, false
+	}
+
+	item := Item{
+		Value:       g.copyOut(data),
+		Created:     time.Unix(0, v.created),
+		AccessCount: atomic.LoadUint64(&v.access.count),
+	}
+	if v.expiration != 0 {
+		item.Expiration = time.Unix(0, v.expiration)
+	}
+	if last := atomic.LoadInt64(&v.access.last); last != 0 {
+		item.LastAccess = time.Unix(0, last)
+	}
+
+	return item, true
+}
@@ -0,0 +1,41 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetItem(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Hour))
+	defer c.Close()
+
+	created := clock.Now()
+	c.Set("a", 1)
+
+	item, ok := c.GetItem("a")
+	if !ok {
+		t.Fatal("expected item")
+	}
+	expected := Item{Value: 1, Created: created, Expiration: created.Add(time.Hour)}
+	if item != expected {
+		t.Fatalf("expected %+v, got %+v", expected, item)
+	}
+
+	clock.Advance(time.Minute)
+	c.Get("a")
+	c.Get("a")
+
+	item, _ = c.GetItem("a")
+	if item.AccessCount != 2 || !item.LastAccess.Equal(created.Add(time.Minute)) {
+		t.Fatalf("expected accesses accounted, got %+v", item)
+	}
+
+	clock.Advance(time.Hour)
+	if _, ok := c.GetItem("a"); ok {
+		t.Fatal("expected no item for expired value")
+	}
+	if _, ok := c.GetItem("b"); ok {
+		t.Fatal("expected no item for missing value")
+	}
+}
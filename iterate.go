@@ -0,0 +1,80 @@
+package gache
+
+import (
+	"sort"
+	"time"
+)
+
+func (g *group) Keys() []string {
+	var keys []string
+	g.each(g.now(), func(key string, _ value) {
+		keys = append(keys, key)
+	})
+
+	return keys
+}
+
+func (g *group) Len() int {
+	var n int
+	g.each(g.now(), func(string, value) {
+		n++
+	})
+
+	return n
+}
+
+func (g *group) Range(f func(key string, val interface{}) bool) {
+	var (
+		keys []string
+		vals []interface{}
+	)
+	g.each(g.now(), func(key string, v value) {
+		if data, ok := g.read(key, v); ok {
+			keys = append(keys, key)
+			vals = append(vals, g.copyOut(data))
+		}
+	})
+
+	for i, key := range keys {
+		if !f(key, vals[i]) {
+			return
+		}
+	}
+}
+
+// each calls f for every value of group, which is unexpired at now.
+// f is called with locked shard mutex, so it must not
+// call methods of the group
+func (g *group) each(now time.Time, f func(key string, v value)) {
+	for _, s := range g.shards {
+		s.mx.RLock()
+		for key, v := range s.values {
+			if !v.expired(now.UnixNano()) && !v.absent() {
+				f(key, v)
+			}
+		}
+		s.mx.RUnlock()
+	}
+}
+
+func (c *cache) Groups() []string {
+	c.mx.RLock()
+	keys := make([]string, 0, len(c.groups))
+	for key := range c.groups {
+		keys = append(keys, key)
+	}
+	c.mx.RUnlock()
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c *cache) TotalLen() int {
+	var n int
+	for _, g := range c.allGroups() {
+		n += g.Len()
+	}
+
+	return n
+}
@@ -0,0 +1,48 @@
+package gache
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestIteration(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("b")
+	c.NewGroup("a", WithShards(4))
+	g, _ := c.Group("a")
+
+	for i := 0; i < 10; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+	g.SetWithTTL("expired", 0, time.Nanosecond)
+	c.Set("root", 1)
+	time.Sleep(time.Millisecond)
+
+	keys := g.Keys()
+	sort.Strings(keys)
+	if len(keys) != 10 || keys[0] != "0" || keys[9] != "9" {
+		t.Fatalf("expected unexpired keys 0-9, got %v", keys)
+	}
+	if n := g.Len(); n != 10 {
+		t.Fatalf("expected 10 values, got %d", n)
+	}
+	if n := c.TotalLen(); n != 11 {
+		t.Fatalf("expected 11 values in cache, got %d", n)
+	}
+	if groups := c.Groups(); len(groups) != 2 || groups[0] != "a" || groups[1] != "b" {
+		t.Fatalf("expected sorted groups [a b], got %v", groups)
+	}
+
+	calls := 0
+	g.Range(func(key string, val interface{}) bool {
+		// range works on snapshot, so group may be modified
+		g.Del(key)
+		calls++
+		return calls < 5
+	})
+	if calls != 5 || g.Len() != 5 {
+		t.Fatalf("expected range to stop after 5 values, got %d calls and %d left", calls, g.Len())
+	}
+}
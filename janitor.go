@@ -0,0 +1,75 @@
+package gache
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// janitor periodically removes expired values
+// from all groups until cache is closed
+func (c *cache) janitor() {
+	ticker := c.clock.NewTicker(c.janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.deleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *cache) deleteExpired() {
+	start := c.clock.Now()
+
+	var n int
+	for _, g := range c.allGroups() {
+		n += g.deleteExpired()
+	}
+
+	if c.logger != nil {
+		c.logger.Debug("gache: expired values removed",
+			slog.Int("count", n), slog.Duration("duration", c.clock.Now().Sub(start)))
+	}
+}
+
+// deleteExpired removes expired values of group, taking them from
+// expiration indexes of shards, and returns number of removed values.
+// Values, which still may be served stale or on fill failure, are kept
+func (g *group) deleteExpired() int {
+	now := g.now().UnixNano()
+	staleTTL, serveStale := g.getStaleTTL()
+	if serveStale && staleTTL == 0 {
+		return 0
+	}
+
+	var keep time.Duration
+	if serveStale {
+		keep = staleTTL
+	}
+	if g.staleGrace > keep {
+		keep = g.staleGrace
+	}
+
+	var n int
+	for _, s := range g.shards {
+		s.mx.Lock()
+		for len(s.expiries) > 0 {
+			e := s.expiries[0]
+			if e.expiration+int64(keep) > now {
+				break
+			}
+
+			s.removeAs(e.key, EventExpire)
+			atomic.AddUint64(&g.stats.expirations, 1)
+			n++
+		}
+		s.forgetRetries(now)
+		s.unlock()
+	}
+
+	return n
+}
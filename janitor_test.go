@@ -0,0 +1,104 @@
+package gache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJanitor(t *testing.T) {
+	c := NewCache(WithExpiration(10*time.Millisecond), WithJanitorInterval(time.Millisecond))
+	defer c.Close()
+
+	c.Set("a", 1)
+	if err := c.NewGroup("g"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetGroupVal("g", "b", 2)
+
+	root := c.(*cache).group
+	waitFor(t, func() bool { return root.Stats().Items == 0 })
+
+	if _, err := c.GetGroupVal("g", "b"); err != nil {
+		t.Fatal("expected value without expiration to stay")
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	c := NewCache(WithJanitorInterval(time.Millisecond))
+	c.Close()
+	c.Close()
+}
+
+// waitFor waits until cond is true, as background
+// goroutines work asynchronously
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition wasn't met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithJanitorInterval(0))
+	defer c.Close()
+	root := c.(*cache).group
+
+	for i, ttl := range []time.Duration{3, 1, 0, 2, 4} {
+		c.SetWithTTL(fmt.Sprint(i), i, ttl*time.Second)
+	}
+	c.Touch("4", 0)
+
+	clock.Advance(2 * time.Second)
+	c.(*cache).deleteExpired()
+
+	for key, stay := range map[string]bool{"0": true, "1": false, "2": true, "3": false, "4": true} {
+		_, ok := root.shards[0].values[key]
+		if ok != stay {
+			t.Fatalf("expected %q kept %v, got %v", key, stay, ok)
+		}
+	}
+	if n := len(root.shards[0].expiries); n != 1 {
+		t.Fatalf("expected 1 value in expiration index, got %d", n)
+	}
+
+	clock.Advance(time.Hour)
+	c.(*cache).deleteExpired()
+	if n := root.Stats().Expirations; n != 3 {
+		t.Fatalf("expected 3 expirations, got %d", n)
+	}
+}
+
+func TestClosedCache(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g")
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected closed cache error on second close, got %v", err)
+	}
+
+	if err := c.SetGroupVal("g", "a", 1); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected closed cache error, got %v", err)
+	}
+	if err := c.DelGroup("g"); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected closed cache error, got %v", err)
+	}
+	if err := c.LoadFrom(strings.NewReader("")); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("expected closed cache error, got %v", err)
+	}
+	if err := c.SaveTo(io.Discard); err != nil {
+		t.Fatalf("expected closed cache to be saved, got %v", err)
+	}
+}
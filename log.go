@@ -0,0 +1,43 @@
+package gache
+
+import "log/slog"
+
+// logged reports whether events of specified type are logged
+func (c *cache) logged(typ EventType) bool {
+	if c.logger == nil {
+		return false
+	}
+
+	switch typ {
+	case EventEvict, EventFillFailed, EventGroupCreated, EventGroupDeleted:
+		return true
+	}
+
+	return false
+}
+
+// logEvents writes logged events to logger of cache
+func (c *cache) logEvents(events []Event) {
+	if c.logger == nil {
+		return
+	}
+
+	for _, e := range events {
+		switch e.Type {
+		case EventGroupCreated:
+			c.logger.Debug("gache: group created", slog.String("group", e.Group))
+		case EventGroupDeleted:
+			c.logger.Debug("gache: group deleted", slog.String("group", e.Group))
+		case EventEvict:
+			c.logger.Debug("gache: value evicted", slog.String("group", e.Group), slog.String("key", e.Key))
+		case EventFillFailed:
+			// missing values are routine, unlike failures
+			// of filling functions and their backends
+			if err, ok := e.Value.(error); ok {
+				c.logger.Warn("gache: fill failed", slog.String("group", e.Group), slog.String("key", e.Key), slog.Any("err", err))
+			} else {
+				c.logger.Debug("gache: fill failed", slog.String("group", e.Group), slog.String("key", e.Key))
+			}
+		}
+	}
+}
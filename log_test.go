@@ -0,0 +1,41 @@
+package gache
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c := NewCache(WithLogger(logger))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(1), WithMaxEntries(1), WithFillFunc(func(key string) (interface{}, bool) {
+		if key == "p" {
+			panic("fill")
+		}
+		return nil, false
+	}))
+	g, _ := c.Group("g")
+
+	g.Set("a", 1)
+	g.Set("b", 2)
+	g.Get("c")
+	g.Get("p")
+	c.DelGroup("g")
+
+	for _, line := range []string{
+		`level=DEBUG msg="gache: group created" group=g`,
+		`level=DEBUG msg="gache: value evicted" group=g key=a`,
+		`level=DEBUG msg="gache: fill failed" group=g key=c`,
+		`level=WARN msg="gache: fill failed" group=g key=p err="gache: fill panicked"`,
+		`level=DEBUG msg="gache: group deleted" group=g`,
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Fatalf("expected log record %s, got\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package gache
+
+import (
+	"container/list"
+	"reflect"
+	"unsafe"
+)
+
+// Sizes of bookkeeping data of values
+var (
+	// valueOverhead is size of map entry with value and its
+	// key header, access counters and boxing of value data
+	valueOverhead = int64(unsafe.Sizeof("") + unsafe.Sizeof(value{}) + unsafe.Sizeof(access{}) + 8)
+	elemOverhead  = int64(unsafe.Sizeof(list.Element{}))
+	// expiryOverhead is size of expiration index entry and its slot in heap
+	expiryOverhead = int64(unsafe.Sizeof(expiryEntry{}) + unsafe.Sizeof(&expiryEntry{}))
+)
+
+func (g *group) MemoryUsage() int64 {
+	var n int64
+	for _, s := range g.shards {
+		s.mx.RLock()
+		for key, v := range s.values {
+			n += valueOverhead + int64(len(key))
+			if v.elem != nil {
+				n += elemOverhead
+			}
+			if v.expiry != nil {
+				n += expiryOverhead
+			}
+
+			switch d := v.data.(type) {
+			case slabRef:
+			case compressed:
+				n += int64(len(d.data))
+			default:
+				if g.coster != nil {
+					n += v.cost
+				} else {
+					n += sizeOf(v.data)
+				}
+			}
+		}
+		if s.slabs != nil {
+			n += int64(s.slabs.allocated)
+		}
+		s.mx.RUnlock()
+	}
+
+	return n
+}
+
+func (c *cache) MemoryUsage() int64 {
+	var n int64
+	for _, g := range c.allGroups() {
+		n += g.MemoryUsage()
+	}
+
+	return n
+}
+
+// sizeOf returns estimated number of bytes taken by v
+// and data it references
+func sizeOf(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+
+	rv := reflect.ValueOf(v)
+	return int64(rv.Type().Size()) + referencedSize(rv, make(map[uintptr]bool))
+}
+
+// referencedSize returns estimated number of bytes taken by data,
+// which v references. Data referenced repeatedly is counted once
+func referencedSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+
+		e := v.Elem()
+		return int64(e.Type().Size()) + referencedSize(e, seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+
+		e := v.Elem()
+		return int64(e.Type().Size()) + referencedSize(e, seen)
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+
+		n := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			n += referencedSize(v.Index(i), seen)
+		}
+		return n
+	case reflect.Array:
+		var n int64
+		for i := 0; i < v.Len(); i++ {
+			n += referencedSize(v.Index(i), seen)
+		}
+		return n
+	case reflect.Struct:
+		var n int64
+		for i := 0; i < v.NumField(); i++ {
+			n += referencedSize(v.Field(i), seen)
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+
+		t := v.Type()
+		n := int64(v.Len()) * int64(t.Key().Size()+t.Elem().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			n += referencedSize(iter.Key(), seen) + referencedSize(iter.Value(), seen)
+		}
+		return n
+	}
+
+	return 0
+}
@@ -0,0 +1,58 @@
+package gache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSizeOf(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	n := &node{Name: "abcd"}
+	n.Next = n
+
+	cases := []struct {
+		v    interface{}
+		want int64
+	}{
+		{nil, 0},
+		{int64(1), 8},
+		{"abcd", 16 + 4},
+		{[]byte("abcd"), 24 + 4},
+		{n, 8 + 24 + 4},
+	}
+	for _, c := range cases {
+		if got := sizeOf(c.v); got != c.want {
+			t.Fatalf("expected size %d of %#v, got %d", c.want, c.v, got)
+		}
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	if n := c.MemoryUsage(); n != 0 {
+		t.Fatalf("expected no memory used by empty cache, got %d", n)
+	}
+
+	c.Set("a", strings.Repeat("a", 1000))
+	small := c.MemoryUsage()
+	if small < 1000 {
+		t.Fatalf("expected usage of at least value size, got %d", small)
+	}
+
+	g := c.GetOrCreateGroup("g", WithCoster(func(val interface{}) int64 {
+		return 5000
+	}))
+	g.Set("b", 1)
+	if n := g.MemoryUsage(); n < 5000 {
+		t.Fatalf("expected usage from coster, got %d", n)
+	}
+	if n := c.MemoryUsage(); n != small+g.MemoryUsage() {
+		t.Fatalf("expected usage of cache summed over groups, got %d", n)
+	}
+}
@@ -0,0 +1,118 @@
+package gache
+
+import "time"
+
+// ConflictPolicy presents way of resolving conflicts,
+// when merged value has the same key as existing one
+type ConflictPolicy int
+
+const (
+	// ConflictKeepExisting keeps existing value
+	ConflictKeepExisting ConflictPolicy = iota
+	// ConflictOverwrite replaces existing value with merged one
+	ConflictOverwrite
+	// ConflictKeepNewer keeps value, which was stored later
+	ConflictKeepNewer
+)
+
+func (g *group) Import(vals map[string]interface{}, ttl time.Duration) {
+	if g.frozen() {
+		return
+	}
+
+	g.markAccess()
+
+	keys := make([]string, 0, len(vals))
+	for key, val := range vals {
+		if g.validate(key, val) == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	now := g.now()
+	for s, skeys := range g.splitKeys(keys) {
+		s.mx.Lock()
+		for _, key := range skeys {
+			s.store(key, vals[key], now, ttl)
+		}
+		s.unlock()
+	}
+
+	for _, key := range keys {
+		g.persist(key, vals[key], ttl)
+	}
+}
+
+func (c *cache) Merge(other Cache, policy ConflictPolicy) error {
+	if c.closed() {
+		return ErrCacheClosed
+	}
+
+	c.group.merge(other.Snapshot(), policy)
+
+	for _, key := range other.Groups() {
+		src, ok := other.Group(key)
+		if !ok {
+			continue
+		}
+
+		view := src.Snapshot()
+		if view.Len() == 0 {
+			continue
+		}
+
+		c.getOrCreateGroup(key).merge(view, policy)
+	}
+
+	return nil
+}
+
+// merge stores values of view in group, resolving
+// conflicts with existing values by policy.
+// Values keep their remaining live durations
+func (g *group) merge(view GroupView, policy ConflictPolicy) {
+	if g.frozen() {
+		return
+	}
+
+	now := g.now()
+
+	type merged struct {
+		key string
+		val interface{}
+		ttl time.Duration
+	}
+
+	var stored []merged
+	for key, it := range view.items {
+		var ttl time.Duration
+		if !it.Expiration.IsZero() {
+			if ttl = it.Expiration.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+
+		val := view.copyOut(it.Value)
+		if g.validate(key, val) != nil {
+			continue
+		}
+
+		s := g.shard(key)
+		s.mx.Lock()
+		if v, ok := s.values[key]; ok && !v.expired(now.UnixNano()) && !v.absent() {
+			if policy == ConflictKeepExisting ||
+				policy == ConflictKeepNewer && v.created >= it.Created.UnixNano() {
+				s.unlock()
+				continue
+			}
+		}
+		s.store(key, val, now, ttl)
+		s.unlock()
+
+		stored = append(stored, merged{key: key, val: val, ttl: ttl})
+	}
+
+	for _, m := range stored {
+		g.persist(m.key, m.val, m.ttl)
+	}
+}
@@ -0,0 +1,73 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	c := NewCache(WithClock(newTestClock()))
+	t.Cleanup(func() { c.Close() })
+
+	c.Import(map[string]interface{}{"a": 1, "b": 2}, time.Hour)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected imported value, got %v, %v", v, ok)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != time.Hour {
+		t.Fatalf("expected TTL of imported value, got %v, %v", ttl, ok)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	clock := newTestClock()
+
+	dst := NewCache(WithClock(clock))
+	t.Cleanup(func() { dst.Close() })
+	dst.Set("old", "dst")
+	clock.Advance(time.Second)
+
+	src := NewCache(WithClock(clock))
+	t.Cleanup(func() { src.Close() })
+	src.Set("old", "src")
+	src.SetWithTTL("ttl", "src", time.Minute)
+	src.GetOrCreateGroup("g").Set("a", 1)
+	src.NewGroup("empty")
+	clock.Advance(time.Second)
+	dst.Set("new", "dst")
+	src.Set("new", "src")
+	clock.Advance(time.Second)
+
+	if err := dst.Merge(src, ConflictKeepExisting); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := dst.Get("old"); v != "dst" {
+		t.Fatalf("expected existing value kept, got %v", v)
+	}
+	if ttl, ok := dst.TTL("ttl"); !ok || ttl != time.Minute-2*time.Second {
+		t.Fatalf("expected remaining TTL of merged value, got %v, %v", ttl, ok)
+	}
+	if v, err := dst.GetGroupVal("g", "a"); err != nil || v != 1 {
+		t.Fatalf("expected value of merged group, got %v, %v", v, err)
+	}
+	if _, ok := dst.Group("empty"); ok {
+		t.Fatal("expected empty group not created")
+	}
+
+	dst.Merge(src, ConflictKeepNewer)
+	if v, _ := dst.Get("old"); v != "src" {
+		t.Fatalf("expected newer value, got %v", v)
+	}
+	if v, _ := dst.Get("new"); v != "dst" {
+		t.Fatalf("expected value stored at the same time kept, got %v", v)
+	}
+
+	dst.Merge(src, ConflictOverwrite)
+	if v, _ := dst.Get("new"); v != "src" {
+		t.Fatalf("expected overwritten value, got %v", v)
+	}
+
+	dst.Close()
+	if err := dst.Merge(src, ConflictOverwrite); err != ErrCacheClosed {
+		t.Fatalf("expected ErrCacheClosed, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package metrics provides Prometheus collector for gache caches
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "gache"
+
+// Collector is prometheus.Collector, which exports
+// statistics of cache groups, labeled by group key.
+// Root group of cache has empty label. Fill durations
+// of deleted groups are dropped on collection
+type Collector struct {
+	cache        gache.Cache
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	fills        *prometheus.Desc
+	fillFailures *prometheus.Desc
+	evictions    *prometheus.Desc
+	expirations  *prometheus.Desc
+	items        *prometheus.Desc
+	refreshQueue *prometheus.Desc
+	refreshDrops *prometheus.Desc
+	fillDuration *prometheus.HistogramVec
+	batchFills   *prometheus.HistogramVec
+
+	mx sync.Mutex
+	// filled holds keys of groups, which fill durations are observed
+	filled map[string]struct{}
+}
+
+// NewCollector returns collector for specified cache.
+// It adds fill and batch fill wrappers to cache for
+// observing fill latency, so it must be created once per cache
+func NewCollector(c gache.Cache) *Collector {
+	labels := []string{"group"}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+	}
+
+	col := &Collector{
+		cache:        c,
+		hits:         desc("hits_total", "Number of Get calls served from group."),
+		misses:       desc("misses_total", "Number of Get calls, which didn't find unexpired value."),
+		fills:        desc("fills_total", "Number of filling function invocations."),
+		fillFailures: desc("fill_failures_total", "Number of filling function invocations, which didn't return value."),
+		evictions:    desc("evictions_total", "Number of values evicted on overflow."),
+		expirations:  desc("expirations_total", "Number of values removed on expiration."),
+		items:        desc("items", "Current number of values."),
+		refreshQueue: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "refresh_queue_depth"),
+			"Number of background refreshes waiting for workers of refresh pool.", nil, nil),
+		refreshDrops: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "refresh_dropped_total"),
+			"Number of background refreshes dropped on overflow of refresh pool.", nil, nil),
+		fillDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fill_duration_seconds",
+			Help:      "Duration of filling function invocations.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		batchFills: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "batch_fill_duration_seconds",
+			Help:      "Duration of batch filling function invocations.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		filled: make(map[string]struct{}),
+	}
+
+	c.WrapFills(col.wrapFill)
+	c.WrapBatchFills(col.wrapBatchFill)
+
+	return col
+}
+
+// Describe implements prometheus.Collector
+func (col *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- col.hits
+	ch <- col.misses
+	ch <- col.fills
+	ch <- col.fillFailures
+	ch <- col.evictions
+	ch <- col.expirations
+	ch <- col.items
+	ch <- col.refreshQueue
+	ch <- col.refreshDrops
+	col.fillDuration.Describe(ch)
+	col.batchFills.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (col *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := col.cache.GroupStats()
+	col.prune(stats)
+
+	for group, s := range stats {
+		ch <- prometheus.MustNewConstMetric(col.hits, prometheus.CounterValue, float64(s.Hits), group)
+		ch <- prometheus.MustNewConstMetric(col.misses, prometheus.CounterValue, float64(s.Misses), group)
+		ch <- prometheus.MustNewConstMetric(col.fills, prometheus.CounterValue, float64(s.Fills), group)
+		ch <- prometheus.MustNewConstMetric(col.fillFailures, prometheus.CounterValue, float64(s.FillFailures), group)
+		ch <- prometheus.MustNewConstMetric(col.evictions, prometheus.CounterValue, float64(s.Evictions), group)
+		ch <- prometheus.MustNewConstMetric(col.expirations, prometheus.CounterValue, float64(s.Expirations), group)
+		ch <- prometheus.MustNewConstMetric(col.items, prometheus.GaugeValue, float64(s.Items), group)
+	}
+
+	rs := col.cache.RefreshPoolStats()
+	ch <- prometheus.MustNewConstMetric(col.refreshQueue, prometheus.GaugeValue, float64(rs.Queued))
+	ch <- prometheus.MustNewConstMetric(col.refreshDrops, prometheus.CounterValue, float64(rs.Dropped))
+
+	col.fillDuration.Collect(ch)
+	col.batchFills.Collect(ch)
+}
+
+// prune drops fill durations of groups,
+// which aren't present in stats of cache
+func (col *Collector) prune(stats map[string]gache.Stats) {
+	col.mx.Lock()
+	defer col.mx.Unlock()
+
+	for group := range col.filled {
+		if _, ok := stats[group]; !ok {
+			col.fillDuration.DeleteLabelValues(group)
+			col.batchFills.DeleteLabelValues(group)
+			delete(col.filled, group)
+		}
+	}
+}
+
+// observe returns observer of histogram for
+// group and remembers group for pruning
+func (col *Collector) observe(vec *prometheus.HistogramVec, group string) prometheus.Observer {
+	col.mx.Lock()
+	col.filled[group] = struct{}{}
+	col.mx.Unlock()
+
+	return vec.WithLabelValues(group)
+}
+
+func (col *Collector) wrapFill(group string, next gache.FillFuncCtx) gache.FillFuncCtx {
+	observer := col.observe(col.fillDuration, group)
+
+	return func(ctx context.Context, key string) (interface{}, bool) {
+		start := time.Now()
+		defer func() {
+			observer.Observe(time.Since(start).Seconds())
+		}()
+
+		return next(ctx, key)
+	}
+}
+
+func (col *Collector) wrapBatchFill(group string, next gache.BatchFillFunc) gache.BatchFillFunc {
+	observer := col.observe(col.batchFills, group)
+
+	return func(keys []string) map[string]interface{} {
+		start := time.Now()
+		defer func() {
+			observer.Observe(time.Since(start).Seconds())
+		}()
+
+		return next(keys)
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcasctiv/gache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := gache.NewCache()
+	defer c.Close()
+	col := NewCollector(c)
+
+	c.NewGroup("g", gache.WithFillFunc(func(key string) (interface{}, bool) {
+		return key, true
+	}))
+	c.Set("a", 1)
+	c.GetGroupVal("g", "b")
+
+	expected := `
+# HELP gache_items Current number of values.
+# TYPE gache_items gauge
+gache_items{group=""} 1
+gache_items{group="g"} 1
+`
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "gache_items"); err != nil {
+		t.Fatal(err)
+	}
+	expected = `
+# HELP gache_refresh_dropped_total Number of background refreshes dropped on overflow of refresh pool.
+# TYPE gache_refresh_dropped_total counter
+gache_refresh_dropped_total 0
+# HELP gache_refresh_queue_depth Number of background refreshes waiting for workers of refresh pool.
+# TYPE gache_refresh_queue_depth gauge
+gache_refresh_queue_depth 0
+`
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "gache_refresh_dropped_total", "gache_refresh_queue_depth"); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(col, "gache_fill_duration_seconds"); n != 1 {
+		t.Fatalf("expected fill duration of group, got %d series", n)
+	}
+}
+
+func TestCollectorFills(t *testing.T) {
+	c := gache.NewCache()
+	defer c.Close()
+	col := NewCollector(c)
+
+	g := c.GetOrCreateGroup("g",
+		gache.WithFillFunc(func(key string) (interface{}, bool) {
+			return key, true
+		}),
+		gache.WithBatchFillFunc(func(keys []string) map[string]interface{} {
+			vals := make(map[string]interface{}, len(keys))
+			for _, key := range keys {
+				vals[key] = key
+			}
+			return vals
+		}),
+	)
+	g.Get("a")
+	g.GetMulti([]string{"b", "c"})
+
+	if n := testutil.CollectAndCount(col, "gache_fill_duration_seconds"); n != 1 {
+		t.Fatalf("expected fill duration of group, got %d series", n)
+	}
+	if n := testutil.CollectAndCount(col, "gache_batch_fill_duration_seconds"); n != 1 {
+		t.Fatalf("expected batch fill duration of group, got %d series", n)
+	}
+
+	if err := c.DelGroup("g"); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(col, "gache_fill_duration_seconds", "gache_batch_fill_duration_seconds"); n != 0 {
+		t.Fatalf("expected fill durations of deleted group dropped, got %d series", n)
+	}
+}
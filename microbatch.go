@@ -0,0 +1,92 @@
+package gache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fillBatcher collects keys of values, which are filled
+// at close times, into batches for batch filling function
+type fillBatcher struct {
+	group   *group
+	window  time.Duration
+	maxKeys int
+	mx      sync.Mutex
+	// batch is batch, which collects keys
+	batch *fillBatch
+}
+
+// fillBatch presents keys filled by single invocation
+// of batch filling function
+type fillBatch struct {
+	keys []string
+	vals map[string]interface{}
+	// full is closed, when batch reaches limit of keys
+	full chan struct{}
+	done chan struct{}
+}
+
+// getFillFunc returns function, which fills single values
+// of group: batcher, if group has batch filling function
+// and batches fills, or filling function otherwise.
+// Must be called with locked mutex of group
+func (g *group) getFillFunc() FillFuncCtx {
+	if g.batcher != nil && g.batchFillFunc != nil {
+		return g.batcher.fill
+	}
+
+	return g.fillFunc
+}
+
+// fill adds key to collected batch and waits for its filling
+func (b *fillBatcher) fill(ctx context.Context, key string) (interface{}, bool) {
+	b.mx.Lock()
+	batch := b.batch
+	if batch == nil {
+		batch = &fillBatch{full: make(chan struct{}), done: make(chan struct{})}
+		b.batch = batch
+		go b.dispatch(batch)
+	}
+
+	batch.keys = append(batch.keys, key)
+	if b.maxKeys > 0 && len(batch.keys) >= b.maxKeys {
+		b.batch = nil
+		close(batch.full)
+	}
+	b.mx.Unlock()
+
+	select {
+	case <-batch.done:
+		val, ok := batch.vals[key]
+		return val, ok
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// dispatch fills keys of batch, when window elapses
+// or batch becomes full, and releases its callers
+func (b *fillBatcher) dispatch(batch *fillBatch) {
+	select {
+	case <-b.group.cache.clock.After(b.window):
+		b.mx.Lock()
+		if b.batch == batch {
+			b.batch = nil
+		}
+		b.mx.Unlock()
+	case <-batch.full:
+	}
+
+	g := b.group
+	g.mx.RLock()
+	batchFillFunc := g.batchFillFunc
+	g.mx.RUnlock()
+
+	defer close(batch.done)
+	defer g.recoverFill("")
+
+	if batchFillFunc != nil {
+		batch.vals = g.cache.wrapBatchFill(g.key, batchFillFunc)(batch.keys)
+	}
+}
@@ -0,0 +1,110 @@
+package gache
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFillBatching(t *testing.T) {
+	var calls int32
+	var batched int32
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillBatching(50*time.Millisecond, 0), WithBatchFillFunc(func(keys []string) map[string]interface{} {
+		atomic.AddInt32(&calls, 1)
+		atomic.StoreInt32(&batched, int32(len(keys)))
+		vals := make(map[string]interface{})
+		for _, key := range keys {
+			if key != "missing" {
+				vals[key] = "v" + key
+			}
+		}
+		return vals
+	}))
+	g, _ := c.Group("g")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if v, ok := g.Get(key); !ok || v != "v"+key {
+				t.Errorf("expected batch filled value of %s, got %v, %v", key, v, ok)
+			}
+		}(strconv.Itoa(i))
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if v, ok := g.Get("missing"); ok {
+			t.Errorf("expected missing value not filled, got %v", v)
+		}
+	}()
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected single batch fill, got %d", n)
+	}
+	if n := atomic.LoadInt32(&batched); n != 6 {
+		t.Fatalf("expected 6 keys in batch, got %d", n)
+	}
+}
+
+func TestFillBatchingMaxKeys(t *testing.T) {
+	var calls int32
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillBatching(time.Hour, 2), WithBatchFillFunc(func(keys []string) map[string]interface{} {
+		atomic.AddInt32(&calls, 1)
+		vals := make(map[string]interface{})
+		for _, key := range keys {
+			vals[key] = key
+		}
+		return vals
+	}))
+	g, _ := c.Group("g")
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if v, ok := g.Get(key); !ok || v != key {
+				t.Errorf("expected value of %s, got %v, %v", key, v, ok)
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected full batch filled before window, got %d fills", n)
+	}
+}
+
+func TestFillBatchingWrappers(t *testing.T) {
+	var wrapped int32
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.WrapBatchFills(func(group string, next BatchFillFunc) BatchFillFunc {
+		return func(keys []string) map[string]interface{} {
+			if group == "g" {
+				atomic.AddInt32(&wrapped, int32(len(keys)))
+			}
+			return next(keys)
+		}
+	})
+	c.NewGroup("g", WithFillBatching(time.Millisecond, 0), WithBatchFillFunc(func(keys []string) map[string]interface{} {
+		return map[string]interface{}{keys[0]: 1}
+	}))
+	g, _ := c.Group("g")
+
+	if v, ok := g.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected batch filled value, got %v, %v", v, ok)
+	}
+	if n := atomic.LoadInt32(&wrapped); n != 1 {
+		t.Fatalf("expected batch fill wrapped, got %d wrapped keys", n)
+	}
+}
@@ -0,0 +1,191 @@
+package gache
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MiddlewareOption presents type of function,
+// intended for configuring caching middleware
+type MiddlewareOption func(*middleware)
+
+// WithRouteGroup sets function, which selects key of group
+// for caching response to request, e.g. by its route.
+// Empty key means root group. Responses for groups, which
+// don't exist, aren't cached. By default root group is used
+func WithRouteGroup(groupFn func(r *http.Request) string) MiddlewareOption {
+	return func(m *middleware) {
+		m.groupFn = groupFn
+	}
+}
+
+// Middleware returns HTTP middleware, which records status,
+// headers and body of successful responses to GET requests
+// and replays them for GET requests with the same key. Other
+// requests, including HEAD, are passed to handler, as their
+// responses have no body, which could be replayed for GET.
+// Nil keyFn means key of request is its method and URL,
+// empty key means response isn't cached. Zero or negative
+// ttl means responses expire by group expiration.
+// Responses with "no-store" or "private" Cache-Control
+// directive, with Set-Cookie or Vary header and responses
+// to requests with Authorization header, which aren't
+// marked as public, aren't cached, as they mustn't be
+// shared between clients
+func Middleware(c Cache, keyFn func(r *http.Request) string, ttl time.Duration, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := &middleware{
+		cache: c,
+		keyFn: keyFn,
+		ttl:   ttl,
+	}
+
+	if m.keyFn == nil {
+		m.keyFn = func(r *http.Request) string {
+			return r.Method + " " + r.URL.String()
+		}
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			m.serve(next, w, r)
+		})
+	}
+}
+
+type middleware struct {
+	cache   Cache
+	keyFn   func(r *http.Request) string
+	groupFn func(r *http.Request) string
+	ttl     time.Duration
+}
+
+// recordedResponse presents response, recorded by middleware
+type recordedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+func (m *middleware) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	key := m.keyFn(r)
+	g, ok := m.group(r)
+	if key == "" || !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	if val, ok := g.Get(key); ok {
+		if resp, ok := val.(*recordedResponse); ok {
+			header := w.Header()
+			for name, values := range resp.Header {
+				header[name] = values
+			}
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+	}
+
+	rec := &recorder{ResponseWriter: w}
+	next.ServeHTTP(rec, r)
+
+	if rec.status < 200 || rec.status >= 300 || rec.status == http.StatusPartialContent {
+		return
+	}
+
+	if !shareable(r, rec.header) {
+		return
+	}
+
+	resp := &recordedResponse{
+		Status: rec.status,
+		Header: rec.header,
+		Body:   rec.body.Bytes(),
+	}
+
+	if m.ttl > 0 {
+		g.SetWithTTL(key, resp, m.ttl)
+	} else {
+		g.Set(key, resp)
+	}
+}
+
+// shareable reports whether response with specified headers
+// to request r may be replayed to any client. Response to request
+// with Authorization header is shareable only, if it is marked
+// explicitly by "public" or "s-maxage" Cache-Control directive.
+// Responses varying by request headers aren't shareable, as they
+// would be replayed regardless of headers of later requests
+func shareable(r *http.Request, header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 || len(header.Values("Vary")) > 0 {
+		return false
+	}
+
+	public := false
+	for _, line := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(line, ",") {
+			name, _, _ := strings.Cut(directive, "=")
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "no-store", "private":
+				return false
+			case "public", "s-maxage":
+				public = true
+			}
+		}
+	}
+
+	return public || r.Header.Get("Authorization") == ""
+}
+
+// group returns group for caching response to request
+func (m *middleware) group(r *http.Request) (Group, bool) {
+	if m.groupFn == nil {
+		return m.cache, true
+	}
+
+	key := m.groupFn(r)
+	if key == "" {
+		return m.cache, true
+	}
+
+	return m.cache.Group(key)
+}
+
+// recorder is http.ResponseWriter, which passes response
+// to underlying writer and records it
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = r.ResponseWriter.Header().Clone()
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data)
+}
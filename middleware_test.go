@@ -0,0 +1,151 @@
+package gache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	var calls int
+	handler := Middleware(c, func(r *http.Request) string {
+		return r.URL.Path
+	}, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "max-age=60, private")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "No-Store")
+		case "/cookie":
+			w.Header().Set("Set-Cookie", "session=1")
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		io.WriteString(w, "body")
+	}))
+
+	serve := func(method, path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Body.String()
+	}
+
+	serve(http.MethodHead, "/public")
+	if body := serve(http.MethodGet, "/public"); body != "body" || calls != 2 {
+		t.Fatalf("expected GET not served from HEAD response, got %q after %d calls", body, calls)
+	}
+	if body := serve(http.MethodGet, "/public"); body != "body" || calls != 2 {
+		t.Fatalf("expected cached response, got %q after %d calls", body, calls)
+	}
+
+	for _, path := range []string{"/private", "/no-store", "/cookie", "/error"} {
+		calls = 0
+		serve(http.MethodGet, path)
+		serve(http.MethodGet, path)
+		if calls != 2 {
+			t.Fatalf("expected response to %s not cached, got %d calls", path, calls)
+		}
+	}
+
+	calls = 0
+	serve(http.MethodPost, "/public")
+	serve(http.MethodPost, "/public")
+	if calls != 2 {
+		t.Fatalf("expected POST requests to pass through, got %d calls", calls)
+	}
+}
+
+func TestMiddlewareRouteGroup(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+	c.NewGroup("api")
+
+	var calls int
+	handler := Middleware(c, nil, 0, WithRouteGroup(func(r *http.Request) string {
+		return r.URL.Query().Get("group")
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.WriteString(w, "body")
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?group=api", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?group=missing", nil))
+	}
+	if calls != 3 {
+		t.Fatalf("expected responses cached in existing group only, got %d calls", calls)
+	}
+
+	g, _ := c.Group("api")
+	if g.Len() != 1 {
+		t.Fatalf("expected response in route group, got %d values", g.Len())
+	}
+}
+
+func TestMiddlewareAuthorization(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	var calls int
+	handler := Middleware(c, nil, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		if r.URL.Path == "/shared" {
+			w.Header().Set("Cache-Control", "s-maxage=60")
+		}
+		io.WriteString(w, "body of "+r.Header.Get("Authorization"))
+	}))
+
+	serve := func(path, auth string) string {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	serve("/user", "alice")
+	if body := serve("/user", "bob"); body != "body of bob" || calls != 2 {
+		t.Fatalf("expected authorized response not cached, got %q after %d calls", body, calls)
+	}
+
+	for _, path := range []string{"/public", "/shared"} {
+		calls = 0
+		serve(path, "alice")
+		if body := serve(path, "bob"); body != "body of alice" || calls != 1 {
+			t.Fatalf("expected public response to %s cached, got %q after %d calls", path, body, calls)
+		}
+	}
+}
+
+func TestMiddlewareVary(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	var calls int
+	handler := Middleware(c, nil, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, r.Header.Get("Accept-Language"))
+	}))
+
+	for _, lang := range []string{"en", "de"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if body := rec.Body.String(); body != lang {
+			t.Fatalf("expected response in %s, got %q", lang, body)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected varying responses not cached, got %d calls", calls)
+	}
+}
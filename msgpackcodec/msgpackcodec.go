@@ -0,0 +1,20 @@
+// Package msgpackcodec provides gache.Codec implementation,
+// which uses MessagePack
+package msgpackcodec
+
+import "github.com/vmihailenco/msgpack/v5"
+
+// Codec is gache.Codec, which uses MessagePack.
+// Values stored as interface values are decoded
+// into generic types, e.g. structs into maps
+type Codec struct{}
+
+// Marshal returns MessagePack encoding of v
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal decodes MessagePack encoded data into v
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
@@ -0,0 +1,26 @@
+package msgpackcodec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	type entry struct {
+		Value interface{}
+	}
+
+	in := entry{Value: map[string]interface{}{"a": "b"}}
+	data, err := Codec{}.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out entry
+	if err := (Codec{}).Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("expected %v, got %v", in, out)
+	}
+}
@@ -0,0 +1,95 @@
+// Package natsinvalidator provides gache.Invalidator
+// implementation, backed by NATS
+package natsinvalidator
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kcasctiv/gache"
+	"github.com/nats-io/nats.go"
+)
+
+// Invalidator is gache.Invalidator, which broadcasts
+// invalidation messages as JSON over NATS subject
+type Invalidator struct {
+	conn    *nats.Conn
+	subject string
+
+	mx  sync.Mutex
+	sub *nats.Subscription
+}
+
+// Option presents type of function, intended for
+// configuring invalidator on creation
+type Option func(*Invalidator)
+
+// WithSubject sets NATS subject of messages.
+// Default is "gache.invalidate"
+func WithSubject(subject string) Option {
+	return func(i *Invalidator) {
+		i.subject = subject
+	}
+}
+
+// New returns invalidator, which uses specified NATS connection
+func New(conn *nats.Conn, opts ...Option) *Invalidator {
+	i := &Invalidator{
+		conn:    conn,
+		subject: "gache.invalidate",
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Publish sends message to all subscribed instances
+func (i *Invalidator) Publish(ctx context.Context, inv gache.Invalidation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	return i.conn.Publish(i.subject, data)
+}
+
+// Subscribe starts passing received messages to handler.
+// Malformed messages are skipped
+func (i *Invalidator) Subscribe(handler func(inv gache.Invalidation)) error {
+	sub, err := i.conn.Subscribe(i.subject, func(msg *nats.Msg) {
+		var inv gache.Invalidation
+		if err := json.Unmarshal(msg.Data, &inv); err != nil {
+			return
+		}
+
+		handler(inv)
+	})
+	if err != nil {
+		return err
+	}
+
+	i.mx.Lock()
+	i.sub = sub
+	i.mx.Unlock()
+
+	return nil
+}
+
+// Close stops receiving messages
+func (i *Invalidator) Close() error {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if i.sub == nil {
+		return nil
+	}
+
+	err := i.sub.Unsubscribe()
+	i.sub = nil
+
+	return err
+}
@@ -0,0 +1,91 @@
+package natsinvalidator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/nats-io/nats-server/v2/server"
+	natstest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+)
+
+func runServer(t *testing.T) *server.Server {
+	opts := natstest.DefaultTestOptions
+	opts.Port = -1
+	srv := natstest.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func connect(t *testing.T, srv *server.Server) *nats.Conn {
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+
+	return conn
+}
+
+func TestInvalidator(t *testing.T) {
+	srv := runServer(t)
+	conn := connect(t, srv)
+
+	i := New(conn, WithSubject("test"))
+	received := make(chan gache.Invalidation, 1)
+	if err := i.Subscribe(func(inv gache.Invalidation) { received <- inv }); err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+
+	conn.Publish("test", []byte("garbage"))
+
+	sent := gache.Invalidation{Source: "a", Kind: gache.InvalidateGroup, Group: "g"}
+	if err := i.Publish(context.Background(), sent); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case inv := <-received:
+		if inv.Source != "a" || inv.Kind != gache.InvalidateGroup || inv.Group != "g" {
+			t.Fatalf("expected published message after skipped malformed one, got %+v", inv)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't received")
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	srv := runServer(t)
+	newCache := func() gache.Cache {
+		conn := connect(t, srv)
+		c := gache.NewCache(gache.WithInvalidator(New(conn)))
+		t.Cleanup(func() { c.Close() })
+
+		// subscription is registered asynchronously
+		if err := conn.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	a, b := newCache(), newCache()
+	a.NewGroup("g")
+	b.NewGroup("g")
+
+	a.DelGroup("g")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := b.Group("g"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("group wasn't invalidated on other instance")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
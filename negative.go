@@ -0,0 +1,12 @@
+package gache
+
+// absence presents sentinel, which group stores for keys
+// not found by filling function, when negative caching
+// is enabled. See WithNegativeCaching
+type absence struct{}
+
+// absent reports whether value is cached absence of key
+func (v value) absent() bool {
+	_, ok := v.data.(absence)
+	return ok
+}
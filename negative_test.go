@@ -0,0 +1,65 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNegativeCaching(t *testing.T) {
+	clock := newTestClock()
+	var fills int
+	c := NewCache(WithClock(clock), WithNegativeCaching(time.Second),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			fills++
+			return nil, false
+		}))
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, ok := c.Get("x"); ok {
+			t.Fatal("expected missing key")
+		}
+	}
+	if fills != 1 {
+		t.Fatalf("expected absence of key to be cached, got %d fills", fills)
+	}
+	if _, ok := c.TTL("x"); ok {
+		t.Fatal("expected no TTL of absent key")
+	}
+	if _, loaded := c.GetOrSet("x", 1); loaded {
+		t.Fatal("expected GetOrSet to store value over absence of key")
+	}
+	if v, ok := c.Get("x"); !ok || v != 1 {
+		t.Fatalf("expected stored value, got %v, %v", v, ok)
+	}
+
+	c.Get("y")
+	clock.Advance(2 * time.Second)
+	c.Get("y")
+	if fills != 3 {
+		t.Fatalf("expected absence of key to expire, got %d fills", fills)
+	}
+}
+
+func TestNegativeCachingEviction(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithNegativeCaching(time.Second),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			return nil, false
+		}))
+	defer c.Close()
+
+	var evicted []string
+	c.OnEvicted(func(group, key string, val interface{}) {
+		evicted = append(evicted, key)
+	})
+
+	c.Get("x")
+	c.Del("x")
+	c.Get("y")
+	clock.Advance(2 * time.Second)
+	c.Get("y")
+	if len(evicted) != 0 {
+		t.Fatalf("expected absences of keys not reported as evicted, got %v", evicted)
+	}
+}
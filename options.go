@@ -0,0 +1,667 @@
+package gache
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Option presents option, intended for configuring cache
+// on creation. Every GroupOption is Option as well,
+// which configures root group of cache
+type Option interface {
+	apply(c *cache)
+}
+
+// cacheOption presents type of function, intended for
+// configuring cache on creation
+type cacheOption func(*cache)
+
+func (o cacheOption) apply(c *cache) {
+	o(c)
+}
+
+// GroupOption presents type of function, intended for
+// configuring group on creation
+type GroupOption func(*group)
+
+func (o GroupOption) apply(c *cache) {
+	o(c.group)
+}
+
+// WithLogger sets logger, which cache writes structured records to:
+// creation and deletion of groups, evictions of values, sweeps
+// of janitor, snapshots and fills of missing values are logged
+// at debug level, while other fill failures, e.g. panics, timeouts
+// or rejections by validator, and snapshot errors are logged
+// at warning level. By default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return cacheOption(func(c *cache) {
+		c.logger = logger
+	})
+}
+
+// WithJanitorInterval enables background removal of expired
+// values from all groups of cache with specified interval.
+// Janitor is stopped by Close method of cache
+func WithJanitorInterval(interval time.Duration) Option {
+	return cacheOption(func(c *cache) {
+		c.janitorInterval = interval
+	})
+}
+
+// WithCodec sets codec, which is used for
+// serialization of cache snapshots. Default is GobCodec
+func WithCodec(codec Codec) Option {
+	return cacheOption(func(c *cache) {
+		c.codec = codec
+	})
+}
+
+// WithSnapshotEncryption makes cache encrypt snapshots with
+// AES-GCM using specified key, which must be 16, 24 or 32 bytes
+// long to select AES-128, AES-192 or AES-256. Snapshots are
+// prefixed with authenticated header, which holds their format
+// version. LoadFrom accepts only snapshots encrypted with the key
+func WithSnapshotEncryption(key []byte) Option {
+	return cacheOption(func(c *cache) {
+		c.snapshotKey = append([]byte(nil), key...)
+	})
+}
+
+// WithAutoSnapshot makes cache write its snapshots to file
+// with specified path every interval and on closing. Snapshot is
+// written to temporary file, which then replaces the previous one,
+// so the file is never partially written. Snapshot existing on
+// creation of cache is loaded. Zero or negative interval means
+// snapshot is written only on closing
+func WithAutoSnapshot(path string, interval time.Duration) Option {
+	return cacheOption(func(c *cache) {
+		c.autoSnapshot = &autoSnapshot{
+			path:     path,
+			interval: interval,
+			done:     make(chan struct{}),
+		}
+	})
+}
+
+// WithWAL makes cache append writes of values, their deletions
+// and touches, deletions, renames and clones of groups, flushes
+// and changes of group expiration to write log file with specified
+// path, which is replayed on creation of cache. When log grows
+// beyond maxSize bytes, it is moved to file with the path and
+// ".sealed" suffix and started anew, and state of cache is compacted
+// in background into snapshot with the path and ".snap" suffix,
+// which replaces sealed log. Zero or negative maxSize means
+// log isn't compacted. Records are written without
+// syncing, so they survive crash of process, but not of system.
+// Groups created by replaying have default settings
+func WithWAL(path string, maxSize int64) Option {
+	return cacheOption(func(c *cache) {
+		c.wal = &wal{path: path, maxSize: maxSize}
+	})
+}
+
+// WithSnapshotErrorHandler sets function, which handles errors
+// of automatic snapshots and write log, except errors returned
+// by Close. By default they are ignored
+func WithSnapshotErrorHandler(handler func(err error)) Option {
+	return cacheOption(func(c *cache) {
+		c.snapshotErrorHandler = handler
+	})
+}
+
+// WithKeyInterning makes values of all cache groups with
+// identical keys share single copy of the key, which saves
+// memory, when the same keys are used in many groups.
+// Saved memory is reported by Stats of cache. Interning adds
+// lookup of shared key to every write and removal of value
+func WithKeyInterning() Option {
+	return cacheOption(func(c *cache) {
+		c.interner = newInterner()
+	})
+}
+
+// WithMemoryPressure enables monitor, which checks heap usage
+// every interval and evicts least recently used values from all
+// groups, when it exceeds soft limit in bytes. Every group gives
+// up the same part of its values, which grows with excess of heap
+// usage over soft limit. Above hard limit at least quarter of
+// values is evicted and garbage collection is forced.
+// Pinned values aren't evicted. See WithHeapUsage
+func WithMemoryPressure(soft, hard uint64, interval time.Duration) Option {
+	return cacheOption(func(c *cache) {
+		if c.pressure == nil {
+			c.pressure = &pressure{heapUsage: heapAlloc}
+		}
+		c.pressure.soft, c.pressure.hard, c.pressure.interval = soft, hard, interval
+	})
+}
+
+// WithRefreshPool makes background refreshes of all cache groups,
+// started by StaleWhileRevalidate policy or refresh-ahead, run
+// by pool of specified number of workers. Refreshes wait for workers
+// in queue of specified size, which is at least 1, and overflow
+// policy sets, what happens when it is full. Without pool every
+// refresh runs in its own goroutine. Pool is stopped by Close
+// method of cache. Zero or negative workers disables pool
+func WithRefreshPool(workers, queue int, overflow RefreshOverflow) Option {
+	return cacheOption(func(c *cache) {
+		if workers < 1 {
+			c.refreshes = nil
+			return
+		}
+		if queue < 1 {
+			queue = 1
+		}
+		c.refreshes = &refreshPool{
+			workers:  workers,
+			overflow: overflow,
+			jobs:     make(chan refreshJob, queue),
+		}
+	})
+}
+
+// WithHeapUsage sets function, which reports heap usage
+// in bytes to memory pressure monitor. By default
+// HeapAlloc of runtime.MemStats is used
+func WithHeapUsage(heapUsage func() uint64) Option {
+	return cacheOption(func(c *cache) {
+		if c.pressure == nil {
+			c.pressure = &pressure{}
+		}
+		c.pressure.heapUsage = heapUsage
+	})
+}
+
+// WithMaxGroups limits number of groups of cache, except root
+// group. On exceeding the limit group, which was accessed least
+// recently, is deleted with its subgroups. Group is accessed, when
+// it is looked up, e.g. by Cache.Group, or its values are got or set
+func WithMaxGroups(n int) Option {
+	return cacheOption(func(c *cache) {
+		c.maxGroups = n
+	})
+}
+
+// WithClock sets source of current time and timers for cache.
+// Default is system clock
+func WithClock(clock Clock) Option {
+	return cacheOption(func(c *cache) {
+		c.clock = clock
+	})
+}
+
+// WithFillWrapper adds wrapper, which decorates
+// filling functions of all cache groups
+func WithFillWrapper(wrapper FillWrapper) Option {
+	return cacheOption(func(c *cache) {
+		c.fillWrappers = append(c.fillWrappers, wrapper)
+	})
+}
+
+// WithStore sets second level store, which backs cache.
+// See NewTieredCache
+func WithStore(store Store) Option {
+	return cacheOption(func(c *cache) {
+		c.store = store
+	})
+}
+
+// WithStoreErrorHandler sets function, which handles errors
+// of second level store. By default they are ignored
+func WithStoreErrorHandler(handler func(err error)) Option {
+	return cacheOption(func(c *cache) {
+		c.storeErrorHandler = handler
+	})
+}
+
+// WithInvalidator sets transport, which broadcasts deletions
+// of values and groups and flushes of cache to other instances
+// and applies their deletions locally
+func WithInvalidator(invalidator Invalidator) Option {
+	return cacheOption(func(c *cache) {
+		c.invalidator = invalidator
+	})
+}
+
+// WithInvalidationErrorHandler sets function, which handles
+// errors of invalidator. By default they are ignored
+func WithInvalidationErrorHandler(handler func(err error)) Option {
+	return cacheOption(func(c *cache) {
+		c.invalidationErrorHandler = handler
+	})
+}
+
+// WithGroupDefaults adds options, which groups created afterwards
+// are configured with before their own options. Root group
+// isn't affected. See Cache.SetGroupDefaults
+func WithGroupDefaults(opts ...GroupOption) Option {
+	return cacheOption(func(c *cache) {
+		c.groupDefaults = append(c.groupDefaults, opts...)
+	})
+}
+
+// WithExpiration sets live duration for group values.
+// Zero or negative expiration means values never expire
+func WithExpiration(expiration time.Duration) GroupOption {
+	return func(g *group) {
+		if expiration < 0 {
+			expiration = 0
+		}
+		g.expiration = expiration
+	}
+}
+
+// WithSoftHardTTL sets two-phase expiration of group values.
+// After soft TTL value is still served by Get, which refreshes it
+// in background, and after hard TTL it's never served, so Get waits
+// for its filling. It is shortcut for WithExpiration(soft) with
+// WithRefreshPolicy(StaleWhileRevalidate, hard-soft). Hard TTL not
+// greater than soft one makes values expire after soft TTL as usual.
+// Zero or negative soft TTL means values never expire
+func WithSoftHardTTL(soft, hard time.Duration) GroupOption {
+	return func(g *group) {
+		if soft <= 0 {
+			g.expiration = 0
+			return
+		}
+		g.expiration = soft
+
+		if hard <= soft {
+			g.refreshPolicy, g.staleTTL = RefreshSync, 0
+			return
+		}
+		g.refreshPolicy, g.staleTTL = StaleWhileRevalidate, hard-soft
+	}
+}
+
+// WithValidator sets function, which checks values before they
+// are stored into group by Set and similar methods or by filling.
+// Rejected values aren't stored and are passed to InvalidValueFunc
+// of cache. Rejected filled value is treated as fill failure.
+// Validator may be called with locked mutex of group shard,
+// so it must not call methods of the group
+func WithValidator(validator Validator) GroupOption {
+	return func(g *group) {
+		g.validator = validator
+	}
+}
+
+// WithMaxValueSize limits size of group values in bytes, so single
+// huge value can't exhaust memory. Size is computed by Coster
+// of group, if it is set, or as length of encoding of value by codec
+// of group or cache. By default oversized values are rejected as
+// invalid ones, see WithValidator, SkipOversized mode makes group
+// skip their caching silently. Zero or negative size means no limit
+func WithMaxValueSize(bytes int, modes ...OversizeMode) GroupOption {
+	return func(g *group) {
+		if bytes < 0 {
+			bytes = 0
+		}
+		g.maxValueSize = int64(bytes)
+
+		g.skipOversized = false
+		for _, m := range modes {
+			if m == SkipOversized {
+				g.skipOversized = true
+			}
+		}
+	}
+}
+
+// WithHotKeyTracking makes group track up to capacity most
+// frequently accessed keys, see Group.HotKeys. Every sampleEvery-th
+// Get call is sampled, so tracking is cheap for hot paths.
+// Zero or negative capacity disables tracking
+func WithHotKeyTracking(capacity, sampleEvery int) GroupOption {
+	return func(g *group) {
+		if capacity <= 0 {
+			g.hot = nil
+			return
+		}
+		g.hot = newHotKeys(capacity, sampleEvery)
+	}
+}
+
+// WithCopyOnRead makes group return copies of values made by
+// cloner from Get and other methods, which read values, so callers
+// may modify them without affecting cached values. Nil cloner
+// makes deep copies by reflection: pointers, maps, slices, arrays,
+// interfaces and exported fields of structs are copied recursively,
+// while unexported fields, channels and functions are shared
+func WithCopyOnRead(cloner Cloner) GroupOption {
+	return func(g *group) {
+		if cloner == nil {
+			cloner = deepCopy
+		}
+		g.cloner = cloner
+	}
+}
+
+// WithFillFunc sets filling function of group
+func WithFillFunc(fillFunc FillFunc) GroupOption {
+	return func(g *group) {
+		g.fillFunc = fillFunc.withContext()
+		g.fillName = ""
+	}
+}
+
+// WithFillFuncCtx sets context-aware filling function of group
+func WithFillFuncCtx(fillFunc FillFuncCtx) GroupOption {
+	return func(g *group) {
+		g.fillFunc = fillFunc
+		g.fillName = ""
+	}
+}
+
+// WithFillFuncTTL sets filling function of group,
+// which returns live duration of value
+func WithFillFuncTTL(fillFunc FillFuncTTL) GroupOption {
+	return func(g *group) {
+		g.fillFunc = fillFunc.withContext()
+		g.fillName = ""
+	}
+}
+
+// WithNamedFillFunc sets filling function of group registered
+// with specified name. See Group.SetNamedFillFunc.
+// Panics if name isn't registered
+func WithNamedFillFunc(name string) GroupOption {
+	return func(g *group) {
+		fillFunc, ok := namedFillFunc(name)
+		if !ok {
+			panic("gache: fill function " + strconv.Quote(name) + " isn't registered")
+		}
+		g.fillFunc = fillFunc
+		g.fillName = name
+	}
+}
+
+// WithBatchFillFunc sets function, which fills multiple
+// values missing in GetMulti at once
+func WithBatchFillFunc(batchFillFunc BatchFillFunc) GroupOption {
+	return func(g *group) {
+		g.batchFillFunc = batchFillFunc
+	}
+}
+
+// WithSlidingExpiration makes every access to group value
+// extend its expiration by live duration, which value was
+// stored with, so values expire only after being idle
+func WithSlidingExpiration() GroupOption {
+	return func(g *group) {
+		g.sliding = true
+	}
+}
+
+// WithRefreshAhead makes Get of value, which has less than threshold
+// part of its live duration remaining, refill it in background,
+// so frequently accessed values never expire. At most workers
+// refills run at once, further ones are skipped until next Get.
+// Zero or negative threshold disables refresh-ahead
+func WithRefreshAhead(threshold float64, workers int) GroupOption {
+	return func(g *group) {
+		if threshold > 1 {
+			threshold = 1
+		}
+		if workers < 1 {
+			workers = 1
+		}
+		if threshold <= 0 {
+			g.refreshAhead, g.refreshSlots = 0, nil
+			return
+		}
+		g.refreshAhead = threshold
+		g.refreshSlots = make(chan struct{}, workers)
+	}
+}
+
+// WithFillTimeout limits duration of filling group values.
+// Context passed to filling function is cancelled after timeout,
+// and fill, which still runs, is abandoned: waiting Get calls
+// return miss, and its result is discarded.
+// Zero or negative timeout means no limit
+func WithFillTimeout(timeout time.Duration) GroupOption {
+	return func(g *group) {
+		if timeout < 0 {
+			timeout = 0
+		}
+		g.fillTimeout = timeout
+	}
+}
+
+// WithFillBatching makes group collect keys of values missing
+// in Get calls during window after the first of them, and fill
+// them by single invocation of batch filling function of group.
+// Batch is filled earlier, when it reaches maxKeys keys, zero or
+// negative maxKeys means no limit. Without batch filling function
+// values are filled by filling function one by one.
+// Zero or negative window disables batching
+func WithFillBatching(window time.Duration, maxKeys int) GroupOption {
+	return func(g *group) {
+		if window <= 0 {
+			g.batcher = nil
+			return
+		}
+		g.batcher = &fillBatcher{group: g, window: window, maxKeys: maxKeys}
+	}
+}
+
+// WithFillRetryBackoff makes group delay next fill of key after
+// its fill fails, so failing keys don't hit backend on every Get.
+// Meanwhile Get calls return miss, or value kept for fill failures.
+// Delay starts with initial and doubles on every consecutive failure
+// up to max, max not greater than initial means fixed delay.
+// Failure is forgotten after max passes since delay ends.
+// Zero or negative initial disables backoff
+func WithFillRetryBackoff(initial, max time.Duration) GroupOption {
+	return func(g *group) {
+		if initial <= 0 {
+			g.retry = nil
+			return
+		}
+		if max < initial {
+			max = initial
+		}
+		g.retry = &retryPolicy{initial: initial, max: max}
+	}
+}
+
+// WithFillRateLimit limits rate of fills of group to rps per second
+// with bursts of up to burst fills, so cold cache or mass expiration
+// doesn't overwhelm origin. Policy sets, what Get calls do, when
+// limit is exceeded. Zero or negative rps disables rate limiting
+func WithFillRateLimit(rps float64, burst int, policy RateLimitPolicy) GroupOption {
+	return func(g *group) {
+		if rps <= 0 {
+			g.limiter = nil
+			return
+		}
+		g.limiter = newRateLimiter(rps, burst, policy)
+	}
+}
+
+// WithCircuitBreaker makes group stop invoking filling function
+// for cooldown after threshold consecutive fill failures within
+// window, so struggling backend isn't hammered. Meanwhile Get calls
+// return miss, or stale value if group serves them.
+// Zero or negative window means failures are counted without
+// time limit. Threshold less than 1 disables circuit breaker
+func WithCircuitBreaker(threshold int, window, cooldown time.Duration) GroupOption {
+	return func(g *group) {
+		if threshold < 1 {
+			g.breaker = nil
+			return
+		}
+		g.breaker = newBreaker(threshold, window, cooldown)
+	}
+}
+
+// WithStaleOnError keeps expired group values for grace duration
+// and returns them from Get, if filling them fails, so origin
+// outage doesn't turn into misses. See Group.GetStale.
+// Zero or negative grace disables serving stale values on error
+func WithStaleOnError(grace time.Duration) GroupOption {
+	return func(g *group) {
+		if grace < 0 {
+			grace = 0
+		}
+		g.staleGrace = grace
+	}
+}
+
+// WithTTLJitter randomizes expiration of every group value
+// by up to fraction of its live duration in both directions,
+// so values stored at once, e.g. on warm-up, don't expire
+// at the same instant. Fraction is limited to [0, 1]
+func WithTTLJitter(fraction float64) GroupOption {
+	return func(g *group) {
+		if fraction < 0 {
+			fraction = 0
+		}
+		if fraction > 1 {
+			fraction = 1
+		}
+		g.ttlJitter = fraction
+	}
+}
+
+// WithNegativeCaching makes group remember for ttl keys, which
+// filling function didn't find, so Get returns miss for them
+// without invoking filling function again.
+// Zero or negative ttl disables negative caching
+func WithNegativeCaching(ttl time.Duration) GroupOption {
+	return func(g *group) {
+		if ttl < 0 {
+			ttl = 0
+		}
+		g.negativeTTL = ttl
+	}
+}
+
+// WithMaxEntries limits number of values in group.
+// When limit is exceeded, least recently used values are evicted.
+// For sharded group limit is split evenly between shards,
+// and recency is tracked per shard.
+// Zero or negative n means no limit
+func WithMaxEntries(n int) GroupOption {
+	return func(g *group) {
+		if n < 0 {
+			n = 0
+		}
+		g.maxEntries = n
+	}
+}
+
+// WithMaxCost limits total cost of values in group.
+// When limit is exceeded, least recently used values are evicted.
+// Cost of value is set by Group.SetWithCost or computed by Coster,
+// otherwise it is zero. For sharded group limit is split evenly
+// between shards. Zero or negative limit means no limit
+func WithMaxCost(limit int64) GroupOption {
+	return func(g *group) {
+		if limit < 0 {
+			limit = 0
+		}
+		g.maxCost = limit
+	}
+}
+
+// WithCoster sets function, which computes cost of values
+// stored in group without explicit cost
+func WithCoster(coster Coster) GroupOption {
+	return func(g *group) {
+		g.coster = coster
+	}
+}
+
+// WithTinyLFU enables TinyLFU admission filter for group,
+// limited by WithMaxEntries or WithMaxCost. New value, which would
+// cause eviction, is stored only if its key is accessed more
+// frequently than key of least recently used value, so rarely
+// accessed keys don't evict hot ones
+func WithTinyLFU() GroupOption {
+	return func(g *group) {
+		g.tinyLFU = true
+	}
+}
+
+// WithWriteBehind sets write-behind policy of writing group values
+// to store of cache: writes are put into queue of queueSize and
+// flushed asynchronously by batches of batchSize or every interval,
+// only last write of every key in batch is performed. Writers block
+// while queue is full. Pending writes are flushed by Close of cache.
+// Default policy is write-through, which writes values synchronously.
+// Zero or negative arguments are replaced by defaults
+func WithWriteBehind(queueSize, batchSize int, interval time.Duration) GroupOption {
+	return func(g *group) {
+		g.writeBehind = &writeBehindConfig{
+			queueSize: queueSize,
+			batchSize: batchSize,
+			interval:  interval,
+		}
+	}
+}
+
+// WithShards splits group storage into n shards,
+// each guarded by its own lock, which reduces contention
+// of concurrent writers. Default is single shard
+func WithShards(n int) GroupOption {
+	return func(g *group) {
+		if n < 1 {
+			n = 1
+		}
+		g.shardCount = n
+	}
+}
+
+// WithRefreshPolicy sets way of refreshing expired values of group.
+// See Group.SetRefreshPolicy
+func WithRefreshPolicy(policy RefreshPolicy, staleTTL time.Duration) GroupOption {
+	return func(g *group) {
+		if staleTTL < 0 {
+			staleTTL = 0
+		}
+		g.refreshPolicy = policy
+		g.staleTTL = staleTTL
+	}
+}
+
+// WithGroupCodec sets codec, which serializes values of group
+// kept in slab storage and sent between peers instead of
+// codec of HTTPPool. Default is GobCodec
+func WithGroupCodec(codec Codec) GroupOption {
+	return func(g *group) {
+		g.codec = codec
+	}
+}
+
+// WithCompression makes group keep values of []byte and string
+// types of at least threshold bytes compressed by compressor.
+// Values are decompressed on reading, so compression is
+// transparent for users of group. Values, which don't shrink
+// on compression, are kept as is
+func WithCompression(compressor Compressor, threshold int) GroupOption {
+	return func(g *group) {
+		g.compressor = compressor
+		g.compressThreshold = threshold
+	}
+}
+
+// WithSlabStorage makes group keep values serialized by its codec
+// in byte slabs of specified size instead of keeping them as Go
+// objects, so contents of millions of values take a few allocations,
+// which garbage collector doesn't scan. Values are still indexed by
+// keys in maps of group, so storage isn't off-heap. Values are
+// deserialized on every reading, so they must be supported by codec,
+// and values not supported by it are kept as Go objects. Values,
+// which can't be deserialized, are passed to InvalidValueFunc of
+// cache with error wrapping ErrCorruptValue and treated as missing.
+// Slabs are compacted, when most of their space is taken by removed
+// values. Values in slab storage aren't compressed
+func WithSlabStorage(slabSize int) GroupOption {
+	return func(g *group) {
+		g.slabSize = slabSize
+	}
+}
@@ -0,0 +1,44 @@
+package gache
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// FillPanicFunc presents type of function, intended for
+// handling panics of filling functions. It receives value
+// passed to panic and stack trace of panicking goroutine.
+// Key is empty for panics of batch filling functions.
+// Root group of cache has empty key
+type FillPanicFunc func(group, key string, recovered interface{}, stack []byte)
+
+func (c *cache) OnFillPanic(f FillPanicFunc) {
+	c.onFillPanic.Store(f)
+}
+
+// recoverFill recovers panic of filling function for key
+// and passes it to FillPanicFunc of cache.
+// Must be deferred directly
+func (g *group) recoverFill(key string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if f, _ := g.cache.onFillPanic.Load().(FillPanicFunc); f != nil {
+		f(g.key, key, r, debug.Stack())
+	}
+}
+
+// callFill invokes filling function for key.
+// Panic of filling function is treated as miss
+// and reported by ErrFillPanic
+func (g *group) callFill(ctx context.Context, fillFunc FillFuncCtx, key string) (val interface{}, ok bool, err error) {
+	defer g.recoverFill(key)
+
+	// err is kept, if filling function panics
+	err = ErrFillPanic
+	val, ok = fillFunc(ctx, key)
+
+	return val, ok, nil
+}
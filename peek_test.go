@@ -0,0 +1,76 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeek(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(1), WithMaxEntries(2), WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled", true
+	}))
+	g, _ := c.Group("g")
+
+	if v, ok := g.Peek("x"); ok {
+		t.Fatalf("expected peek without fill, got %v", v)
+	}
+
+	g.Set("a", 1)
+	g.Set("b", 2)
+	if v, ok := g.Peek("a"); !ok || v != 1 {
+		t.Fatalf("expected peeked value 1, got %v, %v", v, ok)
+	}
+
+	// peeked value stays least recently used
+	g.Set("c", 3)
+	if _, ok := g.Peek("a"); ok {
+		t.Fatal("expected peeked value evicted")
+	}
+}
+
+func TestPeekSliding(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithSlidingExpiration())
+	g, _ := c.Group("g")
+
+	g.SetWithTTL("a", 1, time.Minute)
+	clock.Advance(30 * time.Second)
+	g.Peek("a")
+	clock.Advance(31 * time.Second)
+
+	if _, ok := g.Peek("a"); ok {
+		t.Fatal("expected peek not to extend sliding expiration")
+	}
+}
+
+func TestHas(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled", true
+	}))
+
+	c.SetGroupVal("g", "a", 1)
+	c.SetGroupVal("g", "b", 2)
+	g, _ := c.Group("g")
+	g.SetWithTTL("b", 2, time.Minute)
+	clock.Advance(time.Hour)
+
+	if !c.HasGroupVal("g", "a") {
+		t.Fatal("expected value to exist")
+	}
+	if c.HasGroupVal("g", "b") {
+		t.Fatal("expected expired value not to exist")
+	}
+	if c.HasGroupVal("g", "c") || g.Has("c") {
+		t.Fatal("expected absent value not to be filled")
+	}
+	if c.HasGroupVal("missing", "a") {
+		t.Fatal("expected no value of missing group")
+	}
+}
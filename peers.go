@@ -0,0 +1,69 @@
+package gache
+
+import "context"
+
+// Peer presents remote cache node, which owns some values
+type Peer interface {
+	// Get returns value with specified key from group of peer,
+	// filling it on peer if necessary
+	Get(ctx context.Context, group, key string) (interface{}, bool, error)
+}
+
+// PeerPicker presents interface of pools of cache nodes,
+// which distribute ownership of values between nodes
+type PeerPicker interface {
+	// PickPeer returns peer, which owns value with specified
+	// key of group, and false if current node owns it
+	PickPeer(group, key string) (Peer, bool)
+}
+
+type peersKey struct{}
+
+// withoutPeers returns context, which makes filling of
+// values local. It is used for requests from peers, so
+// inconsistent peer lists don't cause request loops
+func withoutPeers(ctx context.Context) context.Context {
+	return context.WithValue(ctx, peersKey{}, true)
+}
+
+func (c *cache) RegisterPeers(picker PeerPicker) {
+	c.peers.Store(&picker)
+}
+
+// hasPeers reports whether pool of cache nodes is registered
+func (c *cache) hasPeers() bool {
+	picker, _ := c.peers.Load().(*PeerPicker)
+	return picker != nil && *picker != nil
+}
+
+// pickPeer returns peer, which owns value with specified key
+// of group, and false if value should be filled locally
+func (c *cache) pickPeer(ctx context.Context, group, key string) (Peer, bool) {
+	if local, _ := ctx.Value(peersKey{}).(bool); local {
+		return nil, false
+	}
+
+	picker, _ := c.peers.Load().(*PeerPicker)
+	if picker == nil || *picker == nil {
+		return nil, false
+	}
+
+	return (*picker).PickPeer(group, key)
+}
+
+// loadFromPeer returns value with specified key from peer,
+// which owns it. Returns false in ok if value should be
+// filled locally: current node owns it or peer failed
+func (g *group) loadFromPeer(ctx context.Context, key string) (val interface{}, found, ok bool) {
+	peer, ok := g.cache.pickPeer(ctx, g.key, key)
+	if !ok {
+		return nil, false, false
+	}
+
+	val, found, err := peer.Get(ctx, g.key, key)
+	if err != nil {
+		return nil, false, false
+	}
+
+	return val, found, true
+}
@@ -0,0 +1,56 @@
+package gache
+
+import "sync/atomic"
+
+func (g *group) Pin(key string) bool {
+	now := g.now().UnixNano()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	defer s.unlock()
+
+	v, ok := s.values[key]
+	if !ok || v.expired(now) || v.absent() {
+		return false
+	}
+
+	if v.pinned {
+		return true
+	}
+
+	if s.lru != nil {
+		s.lru.Remove(v.elem)
+		v.elem = nil
+	}
+	s.unexpire(v)
+	v.expiry = nil
+	v.pinned = true
+	s.values[key] = v
+
+	return true
+}
+
+func (g *group) Unpin(key string) bool {
+	s := g.shard(key)
+	s.mx.Lock()
+	defer s.unlock()
+
+	v, ok := s.values[key]
+	if !ok || !v.pinned {
+		return false
+	}
+
+	v.pinned = false
+	if s.lru != nil {
+		v.elem = s.lru.PushFront(key)
+	}
+	s.expire(key, &v, v.expiration)
+	s.values[key] = v
+
+	for s.overflowed() && s.lru.Len() > 0 {
+		s.removeAs(s.lru.Back().Value.(string), EventEvict)
+		atomic.AddUint64(&g.stats.evictions, 1)
+	}
+
+	return true
+}
@@ -0,0 +1,43 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPin(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute), WithMaxEntries(2))
+	defer c.Close()
+
+	c.Set("a", 1)
+	if !c.Pin("a") {
+		t.Fatal("expected value to be pinned")
+	}
+	if c.Pin("missing") {
+		t.Fatal("expected missing value not to be pinned")
+	}
+
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Set("d", 4)
+	clock.Advance(time.Hour)
+	c.(*cache).deleteExpired()
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected pinned value to survive eviction and expiration, got %v, %v", v, ok)
+	}
+	if ttl, ok := c.TTL("a"); !ok || ttl != 0 {
+		t.Fatalf("expected pinned value without expiration, got %v, %v", ttl, ok)
+	}
+
+	if !c.Unpin("a") {
+		t.Fatal("expected value to be unpinned")
+	}
+	if c.Unpin("a") {
+		t.Fatal("expected unpinned value not to be unpinned again")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected unpinned value to expire by its expiration")
+	}
+}
@@ -0,0 +1,104 @@
+package gache
+
+import "math/rand"
+
+func (g *group) GetAndDelete(key string) (interface{}, bool) {
+	if g.frozen() {
+		return nil, false
+	}
+
+	now := g.now()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	v, ok := s.lookup(key, now.UnixNano())
+	if !ok || v.absent() {
+		s.unlock()
+		return nil, false
+	}
+
+	s.remove(key)
+	s.unlock()
+
+	g.discard(key)
+
+	return v.val(), true
+}
+
+func (g *group) PopOldest() (string, interface{}, bool) {
+	if g.frozen() {
+		return "", nil, false
+	}
+
+	for {
+		now := g.now().UnixNano()
+
+		var (
+			oldest *shard
+			key    string
+			old    value
+		)
+		for _, s := range g.shards {
+			s.mx.RLock()
+			for k, v := range s.values {
+				if v.expired(now) || v.absent() {
+					continue
+				}
+
+				if oldest == nil || v.created < old.created || (v.created == old.created && k < key) {
+					oldest, key, old = s, k, v
+				}
+			}
+			s.mx.RUnlock()
+		}
+
+		if oldest == nil {
+			return "", nil, false
+		}
+
+		// value may be changed or removed since it was found,
+		// so search is repeated in this case
+		oldest.mx.Lock()
+		if v, ok := oldest.values[key]; !ok || v.version != old.version || v.expired(now) {
+			oldest.unlock()
+			continue
+		}
+
+		oldest.remove(key)
+		oldest.unlock()
+
+		g.discard(key)
+
+		return key, old.val(), true
+	}
+}
+
+func (g *group) PopRandom() (string, interface{}, bool) {
+	if g.frozen() {
+		return "", nil, false
+	}
+
+	now := g.now().UnixNano()
+
+	start := rand.Intn(len(g.shards))
+	for i := range g.shards {
+		s := g.shards[(start+i)%len(g.shards)]
+
+		s.mx.Lock()
+		for key, v := range s.values {
+			if v.expired(now) || v.absent() {
+				continue
+			}
+
+			s.remove(key)
+			s.unlock()
+
+			g.discard(key)
+
+			return key, v.val(), true
+		}
+		s.unlock()
+	}
+
+	return "", nil, false
+}
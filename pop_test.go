@@ -0,0 +1,62 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAndDelete(t *testing.T) {
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled", true
+	}))
+	defer c.Close()
+
+	c.Set("a", 1)
+	if v, ok := c.GetAndDelete("a"); !ok || v != 1 {
+		t.Fatalf("expected removed value, got %v, %v", v, ok)
+	}
+	if v, ok := c.GetAndDelete("a"); ok {
+		t.Fatalf("expected missing value not to be filled, got %v", v)
+	}
+}
+
+func TestPopOldest(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithShards(4))
+	defer c.Close()
+
+	c.Set("c", 3)
+	clock.Advance(time.Second)
+	c.Set("b", 2)
+	c.Set("a", 1)
+
+	for _, want := range []string{"c", "a", "b"} {
+		if key, _, ok := c.PopOldest(); !ok || key != want {
+			t.Fatalf("expected %q popped, got %q, %v", want, key, ok)
+		}
+	}
+	if _, _, ok := c.PopOldest(); ok {
+		t.Fatal("expected empty group")
+	}
+}
+
+func TestPopRandom(t *testing.T) {
+	c := NewCache(WithShards(4))
+	defer c.Close()
+
+	vals := map[string]int{"a": 1, "b": 2, "c": 3}
+	for key, val := range vals {
+		c.Set(key, val)
+	}
+
+	for i := 0; i < 3; i++ {
+		key, val, ok := c.PopRandom()
+		if !ok || vals[key] != val {
+			t.Fatalf("expected stored value popped, got %q, %v, %v", key, val, ok)
+		}
+		delete(vals, key)
+	}
+	if _, _, ok := c.PopRandom(); ok {
+		t.Fatal("expected empty group")
+	}
+}
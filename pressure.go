@@ -0,0 +1,135 @@
+package gache
+
+import (
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// minPressureEviction is minimal part of values, which every
+// group gives up, when heap usage exceeds soft limit, and
+// hardPressureEviction is the one for exceeding hard limit
+const (
+	minPressureEviction  = 0.05
+	hardPressureEviction = 0.25
+)
+
+// pressure presents settings of memory pressure monitor
+type pressure struct {
+	soft      uint64
+	hard      uint64
+	interval  time.Duration
+	heapUsage func() uint64
+}
+
+// heapAlloc returns number of bytes of allocated heap objects
+func heapAlloc() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	return ms.HeapAlloc
+}
+
+// watchPressure periodically checks heap usage
+// and relieves memory pressure until cache is closed
+func (c *cache) watchPressure() {
+	if c.pressure.interval <= 0 || c.pressure.soft == 0 {
+		return
+	}
+
+	ticker := c.clock.NewTicker(c.pressure.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.relievePressure()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// relievePressure evicts part of values of every group,
+// if heap usage exceeds soft limit
+func (c *cache) relievePressure() {
+	p := c.pressure
+	heap := p.heapUsage()
+	if heap <= p.soft {
+		return
+	}
+
+	part := float64(heap-p.soft) / float64(heap)
+	if part < minPressureEviction {
+		part = minPressureEviction
+	}
+
+	hard := p.hard > 0 && heap > p.hard
+	if hard && part < hardPressureEviction {
+		part = hardPressureEviction
+	}
+
+	for _, g := range c.allGroups() {
+		g.evictPart(part)
+	}
+
+	if hard {
+		runtime.GC()
+	}
+}
+
+// evictPart evicts specified part of values of every shard,
+// taking least recently used ones
+func (g *group) evictPart(part float64) {
+	for _, s := range g.shards {
+		s.mx.Lock()
+		if n := int(float64(len(s.values))*part + 0.5); n > 0 {
+			s.evictCold(n)
+		}
+		s.unlock()
+	}
+}
+
+// evictCold evicts up to n least recently used unpinned values.
+// Must be called with locked mutex
+func (s *shard) evictCold(n int) {
+	if s.lru != nil {
+		for ; n > 0 && s.lru.Len() > 0; n-- {
+			s.removeAs(s.lru.Back().Value.(string), EventEvict)
+			atomic.AddUint64(&s.group.stats.evictions, 1)
+		}
+		return
+	}
+
+	type candidate struct {
+		key  string
+		last int64
+	}
+
+	candidates := make([]candidate, 0, len(s.values))
+	for key, v := range s.values {
+		if v.pinned {
+			continue
+		}
+
+		last := atomic.LoadInt64(&v.access.last)
+		if last == 0 {
+			last = v.created
+		}
+		candidates = append(candidates, candidate{key: key, last: last})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].last < candidates[j].last
+	})
+
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+
+	for _, cand := range candidates[:n] {
+		s.removeAs(cand.key, EventEvict)
+		atomic.AddUint64(&s.group.stats.evictions, 1)
+	}
+}
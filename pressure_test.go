@@ -0,0 +1,53 @@
+package gache
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryPressure(t *testing.T) {
+	clock := newTestClock()
+	var heap uint64 = 100
+	c := NewCache(WithClock(clock), WithMemoryPressure(100, 110, 0), WithHeapUsage(func() uint64 {
+		return atomic.LoadUint64(&heap)
+	})).(*cache)
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(1))
+	g, _ := c.Group("g")
+
+	for i := 0; i < 20; i++ {
+		g.Set(strconv.Itoa(i), i)
+		clock.Advance(time.Second)
+	}
+	g.Pin("0")
+
+	c.relievePressure()
+	if n := g.Stats().Items; n != 20 {
+		t.Fatalf("expected no eviction below soft limit, got %d values", n)
+	}
+
+	// 5% of values is evicted at least
+	atomic.StoreUint64(&heap, 101)
+	c.relievePressure()
+	if _, ok := g.Get("0"); !ok {
+		t.Fatal("expected pinned value not evicted")
+	}
+	if _, ok := g.Get("1"); ok {
+		t.Fatal("expected least recently used value evicted")
+	}
+	if n := g.Stats().Items; n != 19 {
+		t.Fatalf("expected 19 values left, got %d", n)
+	}
+
+	// quarter of values is evicted above hard limit
+	atomic.StoreUint64(&heap, 111)
+	c.relievePressure()
+	if n := g.Stats().Items; n != 14 {
+		t.Fatalf("expected 14 values left, got %d", n)
+	}
+	if _, ok := g.Get("19"); !ok {
+		t.Fatal("expected recently used value kept")
+	}
+}
@@ -0,0 +1,127 @@
+package gache
+
+import (
+	"container/list"
+	"sort"
+	"sync/atomic"
+)
+
+// Quota presents limits of group and their utilization
+type Quota struct {
+	// MaxEntries is limit of number of values, zero means no limit
+	MaxEntries int
+	// Entries is current number of values
+	Entries int
+	// MaxCost is limit of total cost of values, zero means no limit
+	MaxCost int64
+	// Cost is current total cost of values
+	Cost int64
+}
+
+// Utilization returns largest ratio of usage to limit of quota.
+// Zero is returned, if quota has no limits
+func (q Quota) Utilization() float64 {
+	var u float64
+	if q.MaxEntries > 0 {
+		u = float64(q.Entries) / float64(q.MaxEntries)
+	}
+	if q.MaxCost > 0 {
+		if c := float64(q.Cost) / float64(q.MaxCost); c > u {
+			u = c
+		}
+	}
+
+	return u
+}
+
+func (g *group) SetQuota(maxEntries int, maxCost int64) {
+	if maxEntries < 0 {
+		maxEntries = 0
+	}
+	if maxCost < 0 {
+		maxCost = 0
+	}
+
+	g.mx.Lock()
+	g.maxEntries, g.maxCost = maxEntries, maxCost
+	g.mx.Unlock()
+
+	if maxEntries > 0 {
+		maxEntries = (maxEntries + g.shardCount - 1) / g.shardCount
+	}
+	if maxCost > 0 {
+		maxCost = (maxCost + int64(g.shardCount) - 1) / int64(g.shardCount)
+	}
+
+	for _, s := range g.shards {
+		s.mx.Lock()
+		s.maxEntries, s.maxCost = maxEntries, maxCost
+		if (maxEntries > 0 || maxCost > 0) && s.lru == nil {
+			s.track()
+		}
+
+		for s.overflowed() && s.lru.Len() > 0 {
+			s.removeAs(s.lru.Back().Value.(string), EventEvict)
+			atomic.AddUint64(&g.stats.evictions, 1)
+		}
+		s.unlock()
+	}
+}
+
+// track starts tracking recency of values of shard, which
+// became limited. Values are ordered by time of their creation.
+// Must be called with locked mutex
+func (s *shard) track() {
+	keys := make([]string, 0, len(s.values))
+	for key, v := range s.values {
+		if !v.pinned {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.values[keys[i]].created < s.values[keys[j]].created
+	})
+
+	s.lru = list.New()
+	for _, key := range keys {
+		v := s.values[key]
+		v.elem = s.lru.PushFront(key)
+		s.values[key] = v
+	}
+
+	if s.group.tinyLFU {
+		s.admission = newTinyLFU(s.maxEntries)
+	}
+}
+
+func (g *group) Quota() Quota {
+	g.mx.RLock()
+	q := Quota{MaxEntries: g.maxEntries, MaxCost: g.maxCost}
+	g.mx.RUnlock()
+
+	for _, s := range g.shards {
+		s.mx.RLock()
+		q.Entries += len(s.values)
+		q.Cost += s.cost
+		s.mx.RUnlock()
+	}
+
+	return q
+}
+
+func (c *cache) Quotas() map[string]Quota {
+	c.mx.RLock()
+	groups := make(map[string]*group, len(c.groups))
+	for key, g := range c.groups {
+		groups[key] = g
+	}
+	c.mx.RUnlock()
+
+	quotas := make(map[string]Quota, len(groups)+1)
+	quotas[""] = c.group.Quota()
+	for key, g := range groups {
+		quotas[key] = g.Quota()
+	}
+
+	return quotas
+}
@@ -0,0 +1,93 @@
+package gache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSetQuota(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(1))
+	g, _ := c.Group("g")
+
+	for i := 0; i < 10; i++ {
+		g.Set(strconv.Itoa(i), i)
+		clock.Advance(time.Second)
+	}
+	g.Pin("0")
+
+	if q := g.Quota(); q.Entries != 10 || q.MaxEntries != 0 || q.Utilization() != 0 {
+		t.Fatalf("expected unlimited quota with 10 entries, got %+v", q)
+	}
+
+	g.SetQuota(5, 0)
+	if q := g.Quota(); q.Entries != 5 || q.MaxEntries != 5 || q.Utilization() != 1 {
+		t.Fatalf("expected full quota of 5 entries, got %+v", q)
+	}
+	if _, ok := g.Get("0"); !ok {
+		t.Fatal("expected pinned value kept")
+	}
+	if _, ok := g.Get("1"); ok {
+		t.Fatal("expected oldest value evicted")
+	}
+	if _, ok := g.Get("9"); !ok {
+		t.Fatal("expected newest value kept")
+	}
+
+	g.Set("10", 10)
+	if q := g.Quota(); q.Entries != 5 {
+		t.Fatalf("expected new quota enforced, got %+v", q)
+	}
+
+	g.SetQuota(-1, 0)
+	for i := 11; i < 20; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+	if q := g.Quota(); q.Entries != 14 || q.MaxEntries != 0 {
+		t.Fatalf("expected quota removed, got %+v", q)
+	}
+}
+
+func TestSetQuotaConcurrentGet(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(1))
+	g, _ := c.Group("g")
+	for i := 0; i < 100; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			g.Get(strconv.Itoa(i % 100))
+		}
+	}()
+
+	for i := 1; i <= 10; i++ {
+		g.SetQuota(100-i, 0)
+	}
+	<-done
+}
+
+func TestQuotas(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithMaxCost(10), WithCoster(func(val interface{}) int64 {
+		return 4
+	}))
+	g, _ := c.Group("g")
+	g.Set("a", 1)
+
+	quotas := c.Quotas()
+	if len(quotas) != 2 {
+		t.Fatalf("expected quotas of root group and g, got %v", quotas)
+	}
+	if q := quotas["g"]; q.Cost != 4 || q.MaxCost != 10 || q.Utilization() != 0.4 {
+		t.Fatalf("expected cost 4 of 10, got %+v", q)
+	}
+}
@@ -0,0 +1,101 @@
+package gache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitPolicy presents way of handling Get calls,
+// which fills exceed rate limit of group
+type RateLimitPolicy int
+
+const (
+	// RateLimitWait makes fill wait, until rate limit allows it
+	RateLimitWait RateLimitPolicy = iota
+	// RateLimitStale makes Get call return expired value kept
+	// for fill failures, see WithStaleOnError, or miss
+	RateLimitStale
+	// RateLimitMiss makes Get call return miss
+	RateLimitMiss
+)
+
+// rateLimiter is token bucket, which limits rate of group fills
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	policy RateLimitPolicy
+
+	mx     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps float64, burst int, policy RateLimitPolicy) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		policy: policy,
+		tokens: float64(burst),
+	}
+}
+
+// take takes token at now. If bucket is empty, token is reserved
+// and delay until it becomes available is returned, if wait is true,
+// otherwise false is returned
+func (l *rateLimiter) take(now time.Time, wait bool) (time.Duration, bool) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	if l.last.IsZero() || now.After(l.last) {
+		l.last = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+
+	if !wait {
+		return 0, false
+	}
+
+	l.tokens--
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second)), true
+}
+
+// cancel returns reserved token to bucket
+func (l *rateLimiter) cancel() {
+	l.mx.Lock()
+	l.tokens++
+	l.mx.Unlock()
+}
+
+// allowFill reports whether group may perform fill. With RateLimitWait
+// policy it waits for token, until ctx is done
+func (g *group) allowFill(ctx context.Context) bool {
+	l := g.limiter
+	delay, ok := l.take(g.now(), l.policy == RateLimitWait)
+	if !ok || delay <= 0 {
+		return ok
+	}
+
+	select {
+	case <-g.cache.clock.After(delay):
+		return true
+	case <-ctx.Done():
+		l.cancel()
+		return false
+	}
+}
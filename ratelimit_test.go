@@ -0,0 +1,55 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, 2, RateLimitWait)
+	now := time.Unix(1000, 0)
+
+	for i := 0; i < 2; i++ {
+		if d, ok := l.take(now, false); !ok || d != 0 {
+			t.Fatalf("expected token of burst, got %v, %v", d, ok)
+		}
+	}
+	if _, ok := l.take(now, false); ok {
+		t.Fatal("expected empty bucket")
+	}
+	if d, ok := l.take(now, true); !ok || d != 500*time.Millisecond {
+		t.Fatalf("expected reserved token after 500ms, got %v, %v", d, ok)
+	}
+
+	l.cancel()
+	if d, ok := l.take(now.Add(time.Second), false); !ok || d != 0 {
+		t.Fatalf("expected refilled token, got %v, %v", d, ok)
+	}
+}
+
+func TestFillRateLimit(t *testing.T) {
+	clock := newTestClock()
+	var calls int
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillRateLimit(1, 1, RateLimitMiss), WithFillFunc(func(key string) (interface{}, bool) {
+		calls++
+		return key, true
+	}))
+	g, _ := c.Group("g")
+
+	if _, ok := g.Get("a"); !ok {
+		t.Fatal("expected fill within limit")
+	}
+	if _, ok := g.Get("b"); ok {
+		t.Fatal("expected miss over limit")
+	}
+	if calls != 1 {
+		t.Fatalf("expected single fill, got %d", calls)
+	}
+
+	clock.Advance(time.Second)
+	if v, ok := g.Get("b"); !ok || v != "b" {
+		t.Fatalf("expected fill after token refill, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,100 @@
+// Package redisinvalidator provides gache.Invalidator
+// implementation, backed by Redis pub/sub
+package redisinvalidator
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kcasctiv/gache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Invalidator is gache.Invalidator, which broadcasts
+// invalidation messages as JSON over Redis channel
+type Invalidator struct {
+	client  redis.UniversalClient
+	channel string
+
+	mx     sync.Mutex
+	pubsub *redis.PubSub
+}
+
+// Option presents type of function, intended for
+// configuring invalidator on creation
+type Option func(*Invalidator)
+
+// WithChannel sets Redis channel of messages.
+// Default is "gache:invalidate"
+func WithChannel(channel string) Option {
+	return func(i *Invalidator) {
+		i.channel = channel
+	}
+}
+
+// New returns invalidator, which uses specified Redis client
+func New(client redis.UniversalClient, opts ...Option) *Invalidator {
+	i := &Invalidator{
+		client:  client,
+		channel: "gache:invalidate",
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Publish sends message to all subscribed instances
+func (i *Invalidator) Publish(ctx context.Context, inv gache.Invalidation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	return i.client.Publish(ctx, i.channel, data).Err()
+}
+
+// Subscribe starts passing received messages to handler.
+// Malformed messages are skipped
+func (i *Invalidator) Subscribe(handler func(inv gache.Invalidation)) error {
+	pubsub := i.client.Subscribe(context.Background(), i.channel)
+	if _, err := pubsub.Receive(context.Background()); err != nil {
+		pubsub.Close()
+		return err
+	}
+
+	i.mx.Lock()
+	i.pubsub = pubsub
+	i.mx.Unlock()
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var inv gache.Invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				continue
+			}
+
+			handler(inv)
+		}
+	}()
+
+	return nil
+}
+
+// Close stops receiving messages
+func (i *Invalidator) Close() error {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if i.pubsub == nil {
+		return nil
+	}
+
+	err := i.pubsub.Close()
+	i.pubsub = nil
+
+	return err
+}
@@ -0,0 +1,75 @@
+package redisinvalidator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kcasctiv/gache"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInvalidator(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+
+	i := New(client, WithChannel("test"))
+	received := make(chan gache.Invalidation, 1)
+	if err := i.Subscribe(func(inv gache.Invalidation) { received <- inv }); err != nil {
+		t.Fatal(err)
+	}
+	defer i.Close()
+
+	sent := gache.Invalidation{Source: "a", Kind: gache.InvalidateKeys, Group: "g", Keys: []string{"x"}}
+	if err := i.Publish(context.Background(), sent); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case inv := <-received:
+		if inv.Source != "a" || inv.Group != "g" || len(inv.Keys) != 1 || inv.Keys[0] != "x" {
+			t.Fatalf("expected published message, got %+v", inv)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't received")
+	}
+
+	// malformed messages are skipped
+	srv.Publish("test", "garbage")
+	select {
+	case inv := <-received:
+		t.Fatalf("expected malformed message skipped, got %+v", inv)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	srv := miniredis.RunT(t)
+	newCache := func() gache.Cache {
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		c := gache.NewCache(gache.WithInvalidator(New(client)))
+		t.Cleanup(func() { c.Close() })
+		return c
+	}
+
+	a, b := newCache(), newCache()
+	a.Set("x", 1)
+	b.Set("x", 1)
+
+	a.Del("x")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := b.Get("x"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("value wasn't invalidated on other instance")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
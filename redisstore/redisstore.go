@@ -0,0 +1,139 @@
+// Package redisstore provides gache.Store implementation,
+// backed by Redis
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Store is gache.BatchStore, which keeps values in Redis.
+// Value with key k of group g is stored under key
+// "<prefix>g:k" and serialized by codec
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+	codec  gache.Codec
+}
+
+// Option presents type of function, intended for
+// configuring store on creation
+type Option func(*Store)
+
+// WithPrefix sets prefix of Redis keys. Default is "gache:"
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithCodec sets codec, which serializes values.
+// Default is gache.GobCodec
+func WithCodec(codec gache.Codec) Option {
+	return func(s *Store) {
+		s.codec = codec
+	}
+}
+
+// New returns store, which uses specified Redis client
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{
+		client: client,
+		prefix: "gache:",
+		codec:  gache.GobCodec{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// entry wraps value, so codec keeps its concrete type
+type entry struct {
+	Value interface{}
+}
+
+// Get returns value with specified key from group
+func (s *Store) Get(ctx context.Context, group, key string) (interface{}, bool, error) {
+	data, err := s.client.Get(ctx, s.key(group, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return s.decode(data)
+}
+
+// GetMulti returns values with specified keys from group,
+// fetching them by single pipeline
+func (s *Store) GetMulti(ctx context.Context, group string, keys []string) (map[string]interface{}, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, s.key(group, key))
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	vals := make(map[string]interface{}, len(keys))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		val, _, err := s.decode(data)
+		if err != nil {
+			return nil, err
+		}
+		vals[keys[i]] = val
+	}
+
+	return vals, nil
+}
+
+// Set sets value with live duration for specified key in group.
+// Zero ttl means value never expires
+func (s *Store) Set(ctx context.Context, group, key string, val interface{}, ttl time.Duration) error {
+	data, err := s.codec.Marshal(&entry{Value: val})
+	if err != nil {
+		return err
+	}
+
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return s.client.Set(ctx, s.key(group, key), data, ttl).Err()
+}
+
+// Del removes value with specified key from group
+func (s *Store) Del(ctx context.Context, group, key string) error {
+	return s.client.Del(ctx, s.key(group, key)).Err()
+}
+
+func (s *Store) key(group, key string) string {
+	return s.prefix + group + ":" + key
+}
+
+func (s *Store) decode(data []byte) (interface{}, bool, error) {
+	var e entry
+	if err := s.codec.Unmarshal(data, &e); err != nil {
+		return nil, false, err
+	}
+
+	return e.Value, true, nil
+}
@@ -0,0 +1,67 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/kcasctiv/gache"
+	"github.com/redis/go-redis/v9"
+)
+
+func newStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return New(client, opts...), srv
+}
+
+func TestStore(t *testing.T) {
+	s, srv := newStore(t, WithPrefix("p:"))
+	ctx := context.Background()
+
+	if err := s.Set(ctx, "g", "a", "val", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Exists("p:g:a") || srv.TTL("p:g:a") != time.Minute {
+		t.Fatalf("expected prefixed key with TTL, got keys %v", srv.Keys())
+	}
+
+	if v, ok, err := s.Get(ctx, "g", "a"); err != nil || !ok || v != "val" {
+		t.Fatalf("expected stored value, got %v, %v, %v", v, ok, err)
+	}
+	if _, ok, err := s.Get(ctx, "g", "missing"); err != nil || ok {
+		t.Fatalf("expected missing value without error, got %v, %v", ok, err)
+	}
+
+	if err := s.Set(ctx, "g", "b", 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	vals, err := s.GetMulti(ctx, "g", []string{"a", "b", "missing"})
+	if err != nil || len(vals) != 2 || vals["a"] != "val" || vals["b"] != 2 {
+		t.Fatalf("expected found values, got %v, %v", vals, err)
+	}
+
+	if err := s.Del(ctx, "g", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Exists("p:g:a") {
+		t.Fatal("expected deleted key")
+	}
+}
+
+func TestTieredCache(t *testing.T) {
+	s, _ := newStore(t)
+
+	writer := gache.NewTieredCache(s)
+	defer writer.Close()
+	writer.Set("a", "shared")
+
+	reader := gache.NewTieredCache(s)
+	defer reader.Close()
+	if v, ok := reader.Get("a"); !ok || v != "shared" {
+		t.Fatalf("expected value from Redis, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,152 @@
+package gache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RefreshPolicy presents way of refreshing expired group values
+type RefreshPolicy int
+
+const (
+	// RefreshSync makes Get call wait for filling
+	// of expired value. It is default policy
+	RefreshSync RefreshPolicy = iota
+	// StaleWhileRevalidate makes Get call return expired value
+	// immediately, while filling function refreshes it
+	// in background
+	StaleWhileRevalidate
+)
+
+func (g *group) SetRefreshPolicy(policy RefreshPolicy, staleTTL time.Duration) {
+	if staleTTL < 0 {
+		staleTTL = 0
+	}
+
+	g.mx.Lock()
+	g.refreshPolicy = policy
+	g.staleTTL = staleTTL
+	g.mx.Unlock()
+}
+
+// getStaleTTL returns duration, during which expired values may
+// still be served, and false if group doesn't serve them
+func (g *group) getStaleTTL() (time.Duration, bool) {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	return g.staleTTL, g.refreshPolicy == StaleWhileRevalidate
+}
+
+// stale reports whether expired value may still be served at now.
+// Cached absence of key is never served. Zero staleTTL means expired value may be served without limit
+func (v value) stale(now int64, staleTTL time.Duration) bool {
+	return v.expired(now) && !v.absent() && (staleTTL == 0 || v.expiration+int64(staleTTL) > now)
+}
+
+// getStale returns expired value with specified key, which may
+// still be served, and starts its refreshing in background,
+// when mutex is unlocked. Must be called with locked mutex
+func (s *shard) getStale(key string, now time.Time, staleTTL time.Duration) (value, bool) {
+	v, ok := s.values[key]
+	if !ok || !v.stale(now.UnixNano(), staleTTL) {
+		return value{}, false
+	}
+
+	s.touch(key, v, now.UnixNano())
+	atomic.AddUint64(&s.group.stats.hits, 1)
+
+	if _, ok := s.calls[key]; ok || s.backingOff(key, now.UnixNano()) {
+		return v, true
+	}
+
+	s.group.mx.RLock()
+	fillFunc, expiration := s.group.getFillFunc(), s.group.expiration
+	s.group.mx.RUnlock()
+
+	if (fillFunc != nil || s.group.cache.store != nil || s.group.cache.hasPeers()) && !s.group.cache.closed() && !s.group.frozen() {
+		c := &call{done: make(chan struct{}), refresh: true}
+		s.calls[key] = c
+		s.refreshes = append(s.refreshes, refreshJob{
+			shard:      s,
+			key:        key,
+			call:       c,
+			fillFunc:   fillFunc,
+			expiration: expiration,
+			now:        now,
+		})
+	}
+
+	return v, true
+}
+
+// refreshAhead starts background refilling of value with specified
+// key, if group refreshes values ahead and v has less than threshold
+// part of its live duration remaining. Refilling is skipped, if key
+// is already being filled or its fills are backed off,
+// all refresh workers are busy or cache is closed.
+// Must be called with unlocked mutex
+func (s *shard) refreshAhead(key string, v value, now time.Time) {
+	g := s.group
+	if g.refreshAhead == 0 || v.ttl <= 0 || v.expiration == 0 || v.absent() || g.cache.closed() || g.frozen() {
+		return
+	}
+
+	if remaining := v.expiration - now.UnixNano(); float64(remaining) >= float64(v.ttl)*g.refreshAhead {
+		return
+	}
+
+	g.mx.RLock()
+	fillFunc, expiration := g.getFillFunc(), g.expiration
+	g.mx.RUnlock()
+
+	if fillFunc == nil && g.cache.store == nil && !g.cache.hasPeers() {
+		return
+	}
+
+	select {
+	case g.refreshSlots <- struct{}{}:
+	default:
+		return
+	}
+
+	s.mx.Lock()
+	if _, ok := s.calls[key]; ok || s.backingOff(key, now.UnixNano()) {
+		s.mx.Unlock()
+		<-g.refreshSlots
+		return
+	}
+
+	c := &call{done: make(chan struct{}), refresh: true}
+	s.calls[key] = c
+	s.mx.Unlock()
+
+	g.cache.startRefresh(refreshJob{
+		shard:      s,
+		key:        key,
+		call:       c,
+		fillFunc:   fillFunc,
+		expiration: expiration,
+		now:        g.now(),
+		release:    func() { <-g.refreshSlots },
+	})
+}
+
+// inGrace reports whether expired value is kept at now,
+// so it may be returned if filling it fails
+func (g *group) inGrace(v value, now int64) bool {
+	return g.staleGrace > 0 && !v.absent() && v.expiration+int64(g.staleGrace) > now
+}
+
+// graceValue returns expired value with specified key,
+// which may be returned on fill failure.
+// Must be called with locked mutex
+func (s *shard) graceValue(key string) (value, bool) {
+	v, ok := s.values[key]
+	now := s.group.now().UnixNano()
+	if !ok || !v.expired(now) || !s.group.inGrace(v, now) {
+		return value{}, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,172 @@
+package gache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var version int32
+	release := make(chan struct{})
+	c := NewCache(WithExpiration(time.Millisecond), WithFillFunc(func(key string) (interface{}, bool) {
+		<-release
+		return atomic.AddInt32(&version, 1), true
+	}))
+	c.SetRefreshPolicy(StaleWhileRevalidate, 0)
+
+	c.Set("a", int32(0))
+	time.Sleep(2 * time.Millisecond)
+
+	if v, ok := c.Get("a"); !ok || v != int32(0) {
+		t.Fatalf("expected stale value while refreshing, got %v, %v", v, ok)
+	}
+
+	close(release)
+	waitFor(t, func() bool {
+		v, ok := c.Get("a")
+		return ok && v != int32(0)
+	})
+}
+
+func TestStaleTTL(t *testing.T) {
+	c := NewCache(WithExpiration(time.Millisecond))
+	c.SetRefreshPolicy(StaleWhileRevalidate, time.Millisecond)
+
+	c.Set("a", 1)
+	time.Sleep(3 * time.Millisecond)
+
+	if v, ok := c.Get("a"); ok {
+		t.Fatalf("expected value beyond stale TTL not to be served, got %v", v)
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	clock := newTestClock()
+	var fills, fail int32
+	c := NewCache(WithClock(clock), WithExpiration(10*time.Second), WithRefreshAhead(0.5, 1),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			n := atomic.AddInt32(&fills, 1)
+			return n, atomic.LoadInt32(&fail) == 0
+		}))
+	defer c.Close()
+
+	c.Get("x")
+	clock.Advance(4 * time.Second)
+	if v, _ := c.Get("x"); v != int32(1) || atomic.LoadInt32(&fills) != 1 {
+		t.Fatalf("expected value not refreshed before threshold, got %v", v)
+	}
+
+	clock.Advance(2 * time.Second)
+	if v, _ := c.Get("x"); v != int32(1) {
+		t.Fatalf("expected current value while refreshing, got %v", v)
+	}
+	waitFor(t, func() bool {
+		v, _ := c.Get("x")
+		return v == int32(2)
+	})
+	if ttl, _ := c.TTL("x"); ttl != 10*time.Second {
+		t.Fatalf("expected refreshed value to live full duration, got %v", ttl)
+	}
+
+	atomic.StoreInt32(&fail, 1)
+	clock.Advance(6 * time.Second)
+	c.Get("x")
+	waitFor(t, func() bool { return atomic.LoadInt32(&fills) == 3 })
+	if v, ok := c.Get("x"); !ok || v != int32(2) {
+		t.Fatalf("expected value kept after failed refresh, got %v, %v", v, ok)
+	}
+}
+
+func TestStaleOnError(t *testing.T) {
+	clock := newTestClock()
+	var fail bool
+	c := NewCache(WithClock(clock), WithExpiration(time.Second), WithStaleOnError(time.Minute),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			return "filled", !fail
+		}))
+	defer c.Close()
+
+	c.Set("a", "old")
+	fail = true
+	clock.Advance(2 * time.Second)
+
+	if v, stale, ok := c.GetStale("a"); !ok || !stale || v != "old" {
+		t.Fatalf("expected expired value on fill failure, got %v, %v, %v", v, stale, ok)
+	}
+
+	fail = false
+	if v, stale, ok := c.GetStale("a"); !ok || stale || v != "filled" {
+		t.Fatalf("expected filled value, got %v, %v, %v", v, stale, ok)
+	}
+
+	fail = true
+	clock.Advance(2 * time.Minute)
+	if v, ok := c.Get("a"); ok {
+		t.Fatalf("expected value after grace period to be dropped, got %v", v)
+	}
+}
+
+func TestSoftHardTTL(t *testing.T) {
+	clock := newTestClock()
+	var fills int32
+	c := NewCache(WithClock(clock))
+	defer c.Close()
+	c.NewGroup("g", WithSoftHardTTL(time.Minute, time.Hour), WithFillFunc(func(key string) (interface{}, bool) {
+		return atomic.AddInt32(&fills, 1), true
+	}))
+	g, _ := c.Group("g")
+
+	g.Get("a")
+	clock.Advance(30 * time.Minute)
+	if v, ok := g.Get("a"); !ok || v != int32(1) {
+		t.Fatalf("expected value served after soft TTL, got %v, %v", v, ok)
+	}
+	waitFor(t, func() bool {
+		v, _ := g.Peek("a")
+		return v == int32(2)
+	})
+
+	clock.Advance(2 * time.Hour)
+	if v, ok := g.Get("a"); !ok || v != int32(3) {
+		t.Fatalf("expected value filled synchronously after hard TTL, got %v, %v", v, ok)
+	}
+
+	c.NewGroup("h", WithSoftHardTTL(time.Minute, 0))
+	h, _ := c.Group("h")
+	h.Set("a", 1)
+	clock.Advance(2 * time.Minute)
+	if _, ok := h.Get("a"); ok {
+		t.Fatal("expected value expired after soft TTL without hard TTL")
+	}
+}
+
+func TestStaleRefreshFailure(t *testing.T) {
+	clock := newTestClock()
+	var fills int32
+	c := NewCache(WithClock(clock), WithExpiration(time.Minute), WithNegativeCaching(time.Hour),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			atomic.AddInt32(&fills, 1)
+			return nil, false
+		}))
+	t.Cleanup(func() { c.Close() })
+	c.SetRefreshPolicy(StaleWhileRevalidate, 0)
+
+	c.Set("a", 1)
+	clock.Advance(2 * time.Minute)
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected stale value while refreshing, got %v, %v", v, ok)
+	}
+
+	s := c.(*cache).group.shard("a")
+	waitFor(t, func() bool {
+		s.mx.RLock()
+		defer s.mx.RUnlock()
+		return atomic.LoadInt32(&fills) == 1 && len(s.calls) == 0
+	})
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected stale value kept after failed refresh, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,154 @@
+package gache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// RefreshOverflow presents way of handling background refreshes,
+// which don't fit into queue of refresh pool
+type RefreshOverflow int
+
+const (
+	// RefreshDropOldest makes refresh pool drop oldest queued
+	// refresh for new one. Dropped refresh isn't performed
+	RefreshDropOldest RefreshOverflow = iota
+	// RefreshBlock makes Get call, which starts refresh,
+	// wait until refresh fits into queue
+	RefreshBlock
+)
+
+// RefreshPoolStats presents statistics of refresh pool of cache
+type RefreshPoolStats struct {
+	// Workers is number of workers of pool
+	Workers int
+	// Queued is number of refreshes waiting for workers
+	Queued int
+	// QueueSize is capacity of queue of pool
+	QueueSize int
+	// Dropped is number of refreshes dropped on queue overflow
+	Dropped uint64
+}
+
+// refreshJob presents background refresh of group value
+type refreshJob struct {
+	shard      *shard
+	key        string
+	call       *call
+	fillFunc   FillFuncCtx
+	expiration time.Duration
+	now        time.Time
+	// release is called, when refresh is done or dropped
+	release func()
+}
+
+// run performs refresh
+func (j refreshJob) run() {
+	if j.release != nil {
+		defer j.release()
+	}
+
+	j.shard.fill(context.Background(), j.key, j.call, j.fillFunc, j.expiration, j.now)
+}
+
+// abandon drops refresh without filling value,
+// releasing callers waiting for it
+func (j refreshJob) abandon() {
+	s := j.shard
+	s.mx.Lock()
+	if s.calls[j.key] == j.call {
+		delete(s.calls, j.key)
+	}
+	s.mx.Unlock()
+
+	close(j.call.done)
+
+	if j.release != nil {
+		j.release()
+	}
+}
+
+// refreshPool is pool of workers shared by groups
+// of cache, which perform background refreshes
+type refreshPool struct {
+	workers  int
+	overflow RefreshOverflow
+	jobs     chan refreshJob
+	dropped  uint64
+}
+
+// run performs queued refreshes until stop is closed.
+// Refreshes left in queue are dropped afterwards
+func (p *refreshPool) run(stop <-chan struct{}) {
+	for {
+		select {
+		case j := <-p.jobs:
+			j.run()
+		case <-stop:
+			for {
+				select {
+				case j := <-p.jobs:
+					j.abandon()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// submit puts refresh into queue according to overflow policy
+func (p *refreshPool) submit(j refreshJob, stop <-chan struct{}) {
+	if p.overflow == RefreshBlock {
+		select {
+		case p.jobs <- j:
+		case <-stop:
+			j.abandon()
+		}
+		return
+	}
+
+	for {
+		select {
+		case <-stop:
+			j.abandon()
+			return
+		case p.jobs <- j:
+			return
+		default:
+		}
+
+		select {
+		case old := <-p.jobs:
+			atomic.AddUint64(&p.dropped, 1)
+			old.abandon()
+		default:
+		}
+	}
+}
+
+// startRefresh starts background refresh by refresh pool of cache,
+// if it has one, or by its own goroutine otherwise
+func (c *cache) startRefresh(j refreshJob) {
+	if c.refreshes == nil {
+		go j.run()
+		return
+	}
+
+	c.refreshes.submit(j, c.stop)
+}
+
+func (c *cache) RefreshPoolStats() RefreshPoolStats {
+	p := c.refreshes
+	if p == nil {
+		return RefreshPoolStats{}
+	}
+
+	return RefreshPoolStats{
+		Workers:   p.workers,
+		Queued:    len(p.jobs),
+		QueueSize: cap(p.jobs),
+		Dropped:   atomic.LoadUint64(&p.dropped),
+	}
+}
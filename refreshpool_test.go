@@ -0,0 +1,60 @@
+package gache
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshPool(t *testing.T) {
+	clock := newTestClock()
+	release := make(chan struct{})
+	var fills int32
+	c := NewCache(WithClock(clock), WithRefreshPool(1, 1, RefreshDropOldest), WithExpiration(time.Second),
+		WithFillFunc(func(key string) (interface{}, bool) {
+			atomic.AddInt32(&fills, 1)
+			<-release
+			return "new", true
+		}))
+	t.Cleanup(func() { c.Close() })
+	c.SetRefreshPolicy(StaleWhileRevalidate, 0)
+
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, "old")
+	}
+	clock.Advance(2 * time.Second)
+
+	// worker is busy with refresh of a
+	c.Get("a")
+	waitFor(t, func() bool { return atomic.LoadInt32(&fills) == 1 })
+
+	// refresh of b is dropped for refresh of c
+	for _, key := range []string{"b", "c"} {
+		if v, ok := c.Get(key); !ok || v != "old" {
+			t.Fatalf("expected stale value of %s, got %v, %v", key, v, ok)
+		}
+	}
+	if s := c.RefreshPoolStats(); s.Workers != 1 || s.Queued != 1 || s.QueueSize != 1 || s.Dropped != 1 {
+		t.Fatalf("expected one queued and one dropped refresh, got %+v", s)
+	}
+
+	close(release)
+	waitFor(t, func() bool { return c.RefreshPoolStats().Queued == 0 && atomic.LoadInt32(&fills) == 2 })
+	waitFor(t, func() bool {
+		a, _ := c.Peek("a")
+		v, _ := c.Peek("c")
+		return a == "new" && v == "new"
+	})
+	if _, ok := c.Peek("b"); ok {
+		t.Fatal("expected dropped refresh not to fill value")
+	}
+}
+
+func TestRefreshPoolStatsWithoutPool(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	if s := c.RefreshPoolStats(); s != (RefreshPoolStats{}) {
+		t.Fatalf("expected zero statistics, got %+v", s)
+	}
+}
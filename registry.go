@@ -0,0 +1,50 @@
+package gache
+
+import "sync"
+
+var fillFuncs = struct {
+	mx    sync.RWMutex
+	funcs map[string]FillFuncCtx
+}{funcs: make(map[string]FillFuncCtx)}
+
+// RegisterFillFunc registers filling function with specified name,
+// so it may be set for group by name and bound to group restored
+// from snapshot. Function registered with the same name is replaced
+func RegisterFillFunc(name string, fillFunc FillFunc) {
+	RegisterFillFuncCtx(name, fillFunc.withContext())
+}
+
+// RegisterFillFuncCtx registers context-aware filling function
+// with specified name like RegisterFillFunc
+func RegisterFillFuncCtx(name string, fillFunc FillFuncCtx) {
+	fillFuncs.mx.Lock()
+	fillFuncs.funcs[name] = fillFunc
+	fillFuncs.mx.Unlock()
+}
+
+// namedFillFunc returns filling function
+// registered with specified name
+func namedFillFunc(name string) (FillFuncCtx, bool) {
+	if name == "" {
+		return nil, false
+	}
+
+	fillFuncs.mx.RLock()
+	fillFunc, ok := fillFuncs.funcs[name]
+	fillFuncs.mx.RUnlock()
+
+	return fillFunc, ok && fillFunc != nil
+}
+
+func (g *group) SetNamedFillFunc(name string) bool {
+	fillFunc, ok := namedFillFunc(name)
+	if !ok {
+		return false
+	}
+
+	g.mx.Lock()
+	g.fillFunc, g.fillName = fillFunc, name
+	g.mx.Unlock()
+
+	return true
+}
@@ -0,0 +1,50 @@
+package gache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNamedFillFunc(t *testing.T) {
+	RegisterFillFunc("test.upper", func(key string) (interface{}, bool) {
+		return "filled " + key, true
+	})
+
+	src := NewCache()
+	defer src.Close()
+	src.NewGroup("g", WithNamedFillFunc("test.upper"))
+	src.NewGroup("h")
+
+	h, _ := src.Group("h")
+	if h.SetNamedFillFunc("test.missing") {
+		t.Fatal("expected unregistered name to be rejected")
+	}
+
+	var buf bytes.Buffer
+	if err := src.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewCache()
+	defer dst.Close()
+	if err := dst.LoadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := dst.GetGroupVal("g", "a"); err != nil || v != "filled a" {
+		t.Fatalf("expected fill function rebound on load, got %v, %v", v, err)
+	}
+	if _, err := dst.GetGroupVal("h", "a"); err == nil {
+		t.Fatal("expected group without named fill function not to fill")
+	}
+}
+
+func TestNamedFillFuncPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for unregistered name")
+		}
+	}()
+
+	NewCache(WithNamedFillFunc("test.missing"))
+}
@@ -0,0 +1,181 @@
+package gache
+
+import "sync/atomic"
+
+func (c *cache) RenameGroup(oldKey, newKey string) error {
+	g, err := c.lookupGroup(oldKey)
+	if err != nil {
+		return err
+	}
+
+	if oldKey == newKey {
+		return nil
+	}
+
+	ng := g.derive(newKey)
+
+	// writes are blocked, until new group replaces old one,
+	// so values written during copying aren't lost
+	g.lockShards()
+	g.copyValues(ng)
+
+	c.mx.Lock()
+	if c.groups[oldKey] != g {
+		c.mx.Unlock()
+		g.unlockShards()
+		ng.stopWriter()
+		return &GroupError{Group: oldKey, Err: ErrGroupNotFound}
+	}
+
+	replaced, ok := c.groups[newKey]
+	c.groups[newKey] = ng
+	delete(c.groups, oldKey)
+	c.mx.Unlock()
+	g.unlockShards()
+
+	c.logWrite(walRecord{Op: walRename, Group: oldKey, Target: newKey})
+
+	g.stopWriter()
+	g.drop()
+	if ok {
+		replaced.stopWriter()
+		replaced.clear()
+		c.groupEvent(EventGroupDeleted, newKey)
+	}
+	c.groupEvent(EventGroupDeleted, oldKey)
+	c.groupEvent(EventGroupCreated, newKey)
+
+	return nil
+}
+
+func (c *cache) CloneGroup(src, dst string) error {
+	g, err := c.lookupGroup(src)
+	if err != nil {
+		return err
+	}
+
+	ng := g.derive(dst)
+
+	g.lockShards()
+	g.copyValues(ng)
+	g.unlockShards()
+
+	c.mx.Lock()
+	if _, exists := c.groups[dst]; exists {
+		c.mx.Unlock()
+		ng.stopWriter()
+		return &GroupError{Group: dst, Err: ErrGroupExists}
+	}
+
+	c.groups[dst] = ng
+	c.mx.Unlock()
+
+	c.logWrite(walRecord{Op: walClone, Group: src, Target: dst})
+
+	c.groupEvent(EventGroupCreated, dst)
+	c.evictGroups()
+
+	return nil
+}
+
+// drop discards values of group, which are moved to other group,
+// without notifying about their removal
+func (g *group) drop() {
+	for _, s := range g.shards {
+		s.mx.Lock()
+		for key := range s.values {
+			g.cache.interner.release(key)
+		}
+		s.values = make(map[string]value)
+		s.expiries = nil
+		s.cost = 0
+		s.retries = nil
+		if s.lru != nil {
+			s.lru.Init()
+		}
+		if s.slabs != nil {
+			s.slabs = &slabArena{size: s.slabs.size}
+		}
+		s.mx.Unlock()
+	}
+}
+
+// derive returns new group with specified key,
+// which has settings of group, but no values
+func (g *group) derive(key string) *group {
+	ng := defaultGroup(g.cache, key)
+	ng.opts = g.opts
+	ng.accessed = g.cache.clock.Now().UnixNano()
+
+	for _, opt := range g.opts {
+		opt(ng)
+	}
+
+	g.mx.RLock()
+	ng.maxEntries, ng.maxCost = g.maxEntries, g.maxCost
+	g.mx.RUnlock()
+
+	ng.init()
+
+	// settings, which may be changed at runtime
+	g.mx.RLock()
+	ng.fillFunc, ng.fillName = g.fillFunc, g.fillName
+	ng.batchFillFunc = g.batchFillFunc
+	ng.expiration = g.expiration
+	ng.refreshPolicy = g.refreshPolicy
+	ng.staleTTL = g.staleTTL
+	g.mx.RUnlock()
+	ng.freeze = atomic.LoadInt32(&g.freeze)
+
+	return ng
+}
+
+// lockShards locks all shards of group, so its values can't be changed
+func (g *group) lockShards() {
+	for _, s := range g.shards {
+		s.mx.Lock()
+	}
+}
+
+// unlockShards unlocks shards of group, locked by lockShards
+func (g *group) unlockShards() {
+	for _, s := range g.shards {
+		s.mx.Unlock()
+	}
+}
+
+// copyValues puts unexpired values of group into group ng
+// with their creation times, expirations, live durations,
+// versions and pins. Shards of group must be locked
+func (g *group) copyValues(ng *group) {
+	now := g.now()
+	for _, s := range g.shards {
+		for key, v := range s.values {
+			if v.expired(now.UnixNano()) || v.absent() {
+				continue
+			}
+
+			val, ok := g.read(key, v)
+			if !ok {
+				continue
+			}
+
+			ns := ng.shard(key)
+			ns.mx.Lock()
+			ns.store(key, val, now, v.ttl)
+			if nv, ok := ns.values[key]; ok {
+				nv.created, nv.ttl, nv.version = v.created, v.ttl, v.version
+				// pinned value keeps its expiration,
+				// which applies after it is unpinned
+				ns.expire(key, &nv, v.expiration)
+				ns.values[key] = nv
+				ns.version = max(ns.version, v.version)
+			}
+			ns.unlock()
+
+			if v.pinned {
+				ng.Pin(key)
+			}
+		}
+	}
+}
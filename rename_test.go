@@ -0,0 +1,139 @@
+package gache
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRenameGroup(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	defer c.Close()
+
+	g := c.GetOrCreateGroup("old", WithExpiration(time.Minute))
+	g.Set("a", 1)
+	c.GetOrCreateGroup("new").Set("b", 2)
+
+	clock.Advance(10 * time.Second)
+	if err := c.RenameGroup("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if groups := c.Groups(); !reflect.DeepEqual(groups, []string{"new"}) {
+		t.Fatalf("expected renamed group to replace existing one, got %v", groups)
+	}
+
+	ng, _ := c.Group("new")
+	if ttl, ok := ng.TTL("a"); !ok || ttl != 50*time.Second {
+		t.Fatalf("expected moved value with remaining TTL, got %v, %v", ttl, ok)
+	}
+	if _, ok := ng.Get("b"); ok {
+		t.Fatal("expected values of replaced group dropped")
+	}
+	ng.Set("c", 3)
+	if ttl, _ := ng.TTL("c"); ttl != time.Minute {
+		t.Fatalf("expected settings of group moved, got TTL %v", ttl)
+	}
+
+	if err := c.RenameGroup("missing", "x"); !errors.Is(err, ErrGroupNotFound) {
+		t.Fatalf("expected missing group error, got %v", err)
+	}
+}
+
+func TestCloneGroup(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	g := c.GetOrCreateGroup("src")
+	g.Set("a", 1)
+
+	if err := c.CloneGroup("src", "dst"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CloneGroup("src", "dst"); !errors.Is(err, ErrGroupExists) {
+		t.Fatalf("expected existing group error, got %v", err)
+	}
+
+	dst, _ := c.Group("dst")
+	dst.Set("a", 2)
+	if v, _ := g.Get("a"); v != 1 {
+		t.Fatalf("expected source group independent of clone, got %v", v)
+	}
+	if v, _ := dst.Get("a"); v != 2 {
+		t.Fatalf("expected cloned value, got %v", v)
+	}
+}
+
+func TestRenameGroupPins(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	g := c.GetOrCreateGroup("old", WithMaxEntries(2))
+	g.SetWithTTL("a", 1, time.Hour)
+	g.Pin("a")
+
+	if err := c.CloneGroup("old", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RenameGroup("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"new", "copy"} {
+		ng, _ := c.Group(key)
+		for i := 0; i < 4; i++ {
+			ng.Set(strconv.Itoa(i), i)
+		}
+		if _, ok := ng.Peek("a"); !ok {
+			t.Fatalf("expected pinned value of group %s not evicted", key)
+		}
+		if !ng.Unpin("a") {
+			t.Fatalf("expected value of group %s pinned", key)
+		}
+		if ttl, ok := ng.TTL("a"); !ok || ttl <= 0 || ttl > time.Hour {
+			t.Fatalf("expected expiration of group %s kept, got %v, %v", key, ttl, ok)
+		}
+	}
+}
+
+func TestCloneGroupMetadata(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	defer c.Close()
+
+	g := c.GetOrCreateGroup("src", WithExpiration(time.Minute))
+	g.Set("a", 1)
+	g.Set("a", 2)
+	clock.Advance(10 * time.Second)
+	g.Freeze()
+	_, version, _ := g.GetWithVersion("a")
+	item, _ := g.Snapshot().GetItem("a")
+
+	if err := c.CloneGroup("src", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RenameGroup("src", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"new", "copy"} {
+		ng, _ := c.Group(key)
+		if !ng.Frozen() {
+			t.Fatalf("expected group %s frozen", key)
+		}
+		if got, _ := ng.Snapshot().GetItem("a"); !got.Created.Equal(item.Created) || !got.Expiration.Equal(item.Expiration) {
+			t.Fatalf("expected times of value in group %s kept, got %v, %v", key, got.Created, got.Expiration)
+		}
+		if _, v, ok := ng.GetWithVersion("a"); !ok || v != version {
+			t.Fatalf("expected version of value in group %s kept, got %d, %v", key, v, ok)
+		}
+
+		ng.Unfreeze()
+		ng.Set("a", 3)
+		if _, v, _ := ng.GetWithVersion("a"); v <= version {
+			t.Fatalf("expected versions of group %s to grow, got %d", key, v)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package gache
+
+import "time"
+
+// retryPolicy presents backoff of fills of keys after their failures
+type retryPolicy struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// delay returns duration, during which fills of key
+// are skipped after specified number of consecutive failures
+func (p *retryPolicy) delay(failures int) time.Duration {
+	d := p.initial
+	for i := 1; i < failures && d < p.max; i++ {
+		d *= 2
+	}
+
+	if d > p.max {
+		d = p.max
+	}
+
+	return d
+}
+
+// retryState presents backoff of fills of key
+type retryState struct {
+	failures int
+	// next is time in nanoseconds since Unix epoch,
+	// when key may be filled again
+	next int64
+}
+
+// forgotten reports whether failures are forgotten at now
+func (r retryState) forgotten(p *retryPolicy, now int64) bool {
+	return r.next+int64(p.max) <= now
+}
+
+// backingOff reports whether fills of key are skipped at now.
+// Must be called with locked mutex
+func (s *shard) backingOff(key string, now int64) bool {
+	r, ok := s.retries[key]
+	return ok && now < r.next
+}
+
+// recordFill updates backoff of key with result of its fill.
+// Must be called with locked mutex
+func (s *shard) recordFill(key string, ok bool, now int64) {
+	p := s.group.retry
+	if p == nil {
+		return
+	}
+
+	if ok {
+		delete(s.retries, key)
+		return
+	}
+
+	if s.retries == nil {
+		s.retries = make(map[string]retryState)
+	}
+
+	r := s.retries[key]
+	if r.forgotten(p, now) {
+		r.failures = 0
+	}
+	r.failures++
+	r.next = now + int64(p.delay(r.failures))
+	s.retries[key] = r
+}
+
+// forgetRetries removes backoffs of keys, which failures
+// are forgotten at now. Must be called with locked mutex
+func (s *shard) forgetRetries(now int64) {
+	p := s.group.retry
+	if p == nil {
+		return
+	}
+
+	for key, r := range s.retries {
+		if r.forgotten(p, now) {
+			delete(s.retries, key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &retryPolicy{initial: time.Second, max: 5 * time.Second}
+	for failures, want := range []time.Duration{time.Second, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if d := p.delay(failures); d != want {
+			t.Fatalf("expected delay %v after %d failures, got %v", want, failures, d)
+		}
+	}
+}
+
+func TestFillRetryBackoff(t *testing.T) {
+	clock := newTestClock()
+	var calls int
+	ok := false
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithFillRetryBackoff(time.Second, 4*time.Second), WithFillFunc(func(key string) (interface{}, bool) {
+		calls++
+		return key, ok
+	}))
+	g, _ := c.Group("g")
+
+	g.Get("a")
+	g.Get("a")
+	if calls != 1 {
+		t.Fatalf("expected fill skipped during backoff, got %d fills", calls)
+	}
+
+	clock.Advance(time.Second)
+	g.Get("a")
+	clock.Advance(time.Second)
+	g.Get("a")
+	if calls != 2 {
+		t.Fatalf("expected doubled delay after second failure, got %d fills", calls)
+	}
+
+	clock.Advance(time.Second)
+	ok = true
+	if v, found := g.Get("a"); !found || v != "a" {
+		t.Fatalf("expected value filled after backoff, got %v, %v", v, found)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 fills, got %d", calls)
+	}
+
+	// success resets backoff
+	g.Del("a")
+	ok = false
+	g.Get("a")
+	clock.Advance(time.Second)
+	g.Get("a")
+	if calls != 5 {
+		t.Fatalf("expected initial delay after success, got %d fills", calls)
+	}
+}
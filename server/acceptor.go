@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/kcasctiv/gache"
+)
+
+// ErrServerClosed is returned by Serve after Close call
+var ErrServerClosed = errors.New("server: closed")
+
+// acceptor tracks listeners and connections of server,
+// so they can be closed at once
+type acceptor struct {
+	mx        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+func newAcceptor() acceptor {
+	return acceptor{
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// serve accepts connections on l and passes each of them
+// to handle in its own goroutine until close is called.
+// Connection is closed after handle returns
+func (a *acceptor) serve(l net.Listener, handle func(conn net.Conn)) error {
+	a.mx.Lock()
+	if a.closed {
+		a.mx.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	a.listeners[l] = struct{}{}
+	a.mx.Unlock()
+
+	defer func() {
+		a.mx.Lock()
+		delete(a.listeners, l)
+		a.mx.Unlock()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			a.mx.Lock()
+			closed := a.closed
+			a.mx.Unlock()
+
+			if closed {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		a.mx.Lock()
+		if a.closed {
+			a.mx.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		a.conns[conn] = struct{}{}
+		a.wg.Add(1)
+		a.mx.Unlock()
+
+		go func() {
+			defer func() {
+				a.mx.Lock()
+				delete(a.conns, conn)
+				a.mx.Unlock()
+				conn.Close()
+				a.wg.Done()
+			}()
+
+			handle(conn)
+		}()
+	}
+}
+
+// close stops listeners, closes active connections
+// and waits for their handlers
+func (a *acceptor) close() error {
+	a.mx.Lock()
+	a.closed = true
+	for l := range a.listeners {
+		l.Close()
+	}
+	for conn := range a.conns {
+		conn.Close()
+	}
+	a.mx.Unlock()
+
+	a.wg.Wait()
+
+	return nil
+}
+
+// lookupGroup returns group of cache with specified key.
+// Empty key means root group. If create is true,
+// missing group is created
+func lookupGroup(c gache.Cache, key string, create bool) (gache.Group, bool) {
+	if key == "" {
+		return c, true
+	}
+
+	if !create {
+		return c.Group(key)
+	}
+
+	return c.GetOrCreateGroup(key), true
+}
@@ -0,0 +1,477 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+const (
+	// maxBulkLength is max length of bulk string in request
+	maxBulkLength = 512 << 20
+	// maxArgs is max number of arguments in request
+	maxArgs = 1024 * 1024
+	// maxPrealloc limits memory allocated before data is actually read
+	maxPrealloc = 64 << 10
+)
+
+var errProtocol = errors.New("ERR Protocol error")
+
+// RESPServer serves cache over subset of Redis protocol (RESP).
+// Supported commands are GET, SET with EX, PX, NX and XX, DEL, TTL,
+// KEYS, FLUSHDB, SELECT, PING, ECHO and QUIT. Every connection works
+// with single group, which is selected by SELECT command with group key.
+// Initially and after selecting "0" or "" connection works with root
+// group. Missing groups are empty and created on first SET
+type RESPServer struct {
+	cache gache.Cache
+
+	acceptor
+}
+
+// NewRESP returns RESP server of specified cache
+func NewRESP(c gache.Cache) *RESPServer {
+	return &RESPServer{
+		cache:    c,
+		acceptor: newAcceptor(),
+	}
+}
+
+// ListenAndServe listens on TCP address and serves connections
+func (s *RESPServer) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l and serves them
+// until Close is called
+func (s *RESPServer) Serve(l net.Listener) error {
+	return s.serve(l, s.serveConn)
+}
+
+// Close stops listeners and closes active connections
+func (s *RESPServer) Close() error {
+	return s.close()
+}
+
+// respConn presents state of RESP connection
+type respConn struct {
+	r     *bufio.Reader
+	w     *bufio.Writer
+	group string
+}
+
+func (s *RESPServer) serveConn(conn net.Conn) {
+	rc := &respConn{
+		r: bufio.NewReader(conn),
+		w: bufio.NewWriter(conn),
+	}
+
+	for {
+		args, err := readCommand(rc.r)
+		if errors.Is(err, errProtocol) {
+			writeError(rc.w, err.Error())
+			rc.w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		if len(args) > 0 && !s.handle(rc, args) {
+			rc.w.Flush()
+			return
+		}
+
+		if rc.r.Buffered() == 0 {
+			if err := rc.w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle executes command and writes its response.
+// Returns false if connection should be closed
+func (s *RESPServer) handle(rc *respConn, args []string) bool {
+	cmd := strings.ToUpper(args[0])
+	switch cmd {
+	case "GET":
+		if !arity(rc.w, args, 2, 2) {
+			break
+		}
+
+		g, ok := lookupGroup(s.cache, rc.group, false)
+		if !ok {
+			writeNull(rc.w)
+			break
+		}
+
+		val, ok := g.Get(args[1])
+		if !ok {
+			writeNull(rc.w)
+			break
+		}
+		writeBulk(rc.w, toItem(val).Data)
+	case "SET":
+		if arity(rc.w, args, 3, 6) {
+			s.set(rc, args[1:])
+		}
+	case "DEL":
+		if !arity(rc.w, args, 2, -1) {
+			break
+		}
+
+		deleted := 0
+		if g, ok := lookupGroup(s.cache, rc.group, false); ok {
+			for _, key := range args[1:] {
+				if _, ok := g.TTL(key); ok {
+					g.Del(key)
+					deleted++
+				}
+			}
+		}
+		writeInt(rc.w, int64(deleted))
+	case "TTL":
+		if !arity(rc.w, args, 2, 2) {
+			break
+		}
+
+		g, ok := lookupGroup(s.cache, rc.group, false)
+		if !ok {
+			writeInt(rc.w, -2)
+			break
+		}
+
+		switch ttl, ok := g.TTL(args[1]); {
+		case !ok:
+			writeInt(rc.w, -2)
+		case ttl == 0:
+			writeInt(rc.w, -1)
+		default:
+			writeInt(rc.w, int64((ttl+time.Second/2)/time.Second))
+		}
+	case "KEYS":
+		if !arity(rc.w, args, 2, 2) {
+			break
+		}
+
+		var keys []string
+		if g, ok := lookupGroup(s.cache, rc.group, false); ok {
+			for _, key := range g.Keys() {
+				if matchGlob(args[1], key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		fmt.Fprintf(rc.w, "*%d\r\n", len(keys))
+		for _, key := range keys {
+			writeBulk(rc.w, []byte(key))
+		}
+	case "FLUSHDB":
+		if !arity(rc.w, args, 1, 2) {
+			break
+		}
+
+		if g, ok := lookupGroup(s.cache, rc.group, false); ok {
+			g.Clear()
+		}
+		writeStatus(rc.w, "OK")
+	case "SELECT":
+		if !arity(rc.w, args, 2, 2) {
+			break
+		}
+
+		rc.group = args[1]
+		if rc.group == "0" {
+			rc.group = ""
+		}
+		writeStatus(rc.w, "OK")
+	case "PING":
+		if !arity(rc.w, args, 1, 2) {
+			break
+		}
+
+		if len(args) == 2 {
+			writeBulk(rc.w, []byte(args[1]))
+		} else {
+			writeStatus(rc.w, "PONG")
+		}
+	case "ECHO":
+		if arity(rc.w, args, 2, 2) {
+			writeBulk(rc.w, []byte(args[1]))
+		}
+	case "COMMAND":
+		// clients, e.g. redis-cli, ask for command docs
+		// on start and accept empty reply
+		fmt.Fprint(rc.w, "*0\r\n")
+	case "QUIT":
+		writeStatus(rc.w, "OK")
+		return false
+	default:
+		writeError(rc.w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+
+	return true
+}
+
+// set handles SET key value [EX seconds|PX milliseconds] [NX|XX]
+func (s *RESPServer) set(rc *respConn, args []string) {
+	key, data := args[0], []byte(args[1])
+
+	var ttl time.Duration
+	var nx, xx bool
+	for i := 2; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if ttl != 0 || i+1 == len(args) {
+				writeError(rc.w, "ERR syntax error")
+				return
+			}
+
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(rc.w, "ERR invalid expire time in 'set' command")
+				return
+			}
+
+			if ttl = time.Duration(n) * time.Millisecond; opt == "EX" {
+				ttl = time.Duration(n) * time.Second
+			}
+			i++
+		default:
+			writeError(rc.w, "ERR syntax error")
+			return
+		}
+	}
+
+	if nx && xx {
+		writeError(rc.w, "ERR syntax error")
+		return
+	}
+
+	g, ok := lookupGroup(s.cache, rc.group, !xx)
+	if !ok {
+		writeNull(rc.w)
+		return
+	}
+
+	switch {
+	case nx:
+		if g.AddWithTTL(key, data, ttl) != nil {
+			writeNull(rc.w)
+			return
+		}
+	case xx:
+		if g.ReplaceWithTTL(key, data, ttl) != nil {
+			writeNull(rc.w)
+			return
+		}
+	default:
+		g.SetWithTTL(key, data, ttl)
+	}
+
+	writeStatus(rc.w, "OK")
+}
+
+// readCommand reads command as array of bulk strings
+// or as inline command
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > maxArgs {
+		return nil, errProtocol
+	}
+
+	args := make([]string, 0, min(n, 16))
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(line, "$") {
+			return nil, errProtocol
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLength {
+			return nil, errProtocol
+		}
+
+		var buf bytes.Buffer
+		buf.Grow(min(size+2, maxPrealloc))
+		if _, err := io.CopyN(&buf, r, int64(size+2)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		data := buf.Bytes()
+		if data[size] != '\r' || data[size+1] != '\n' {
+			return nil, errProtocol
+		}
+
+		args = append(args, string(data[:size]))
+	}
+
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// arity checks number of command arguments including command name
+// and writes error if it is wrong. Negative max means no limit
+func arity(w *bufio.Writer, args []string, min, max int) bool {
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(args[0])))
+		return false
+	}
+
+	return true
+}
+
+func writeStatus(w *bufio.Writer, status string) {
+	fmt.Fprintf(w, "+%s\r\n", status)
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+func writeInt(w *bufio.Writer, n int64) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+func writeBulk(w *bufio.Writer, data []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(data))
+	w.Write(data)
+	w.WriteString("\r\n")
+}
+
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+// matchGlob reports whether s matches Redis glob pattern,
+// which supports *, ?, [...] classes with ranges and negation,
+// and escaping by backslash
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern, s[i:]) {
+					return true
+				}
+			}
+
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				// unterminated class is matched literally
+				if s[0] != '[' {
+					return false
+				}
+				pattern, s = pattern[1:], s[1:]
+				continue
+			}
+
+			class := pattern[1 : end+1]
+			negate := len(class) > 0 && class[0] == '^'
+			if negate {
+				class = class[1:]
+			}
+
+			if matchClass(class, s[0]) == negate {
+				return false
+			}
+			pattern, s = pattern[end+2:], s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+
+	return len(s) == 0
+}
+
+// matchClass reports whether c belongs to glob class,
+// e.g. "a-z0"
+func matchClass(class string, c byte) bool {
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			lo, hi := class[i], class[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				return true
+			}
+			i += 2
+			continue
+		}
+
+		if class[i] == c {
+			return true
+		}
+	}
+
+	return false
+}
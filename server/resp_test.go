@@ -0,0 +1,46 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		args  []string
+		err   error
+	}{
+		{"array", "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n", []string{"GET", "key"}, nil},
+		{"empty bulk", "*2\r\n$4\r\nECHO\r\n$0\r\n\r\n", []string{"ECHO", ""}, nil},
+		{"inline", "PING\r\n", []string{"PING"}, nil},
+		{"zero args", "*0\r\n", nil, errProtocol},
+		{"negative args", "*-1\r\n", nil, errProtocol},
+		{"too many args", "*1048577\r\n", nil, errProtocol},
+		{"bad args", "*x\r\n", nil, errProtocol},
+		{"missing bulk", "*1\r\n:1\r\n", nil, errProtocol},
+		{"negative bulk", "*1\r\n$-1\r\n", nil, errProtocol},
+		{"too long bulk", "*1\r\n$536870913\r\n", nil, errProtocol},
+		{"bad bulk", "*1\r\n$x\r\n", nil, errProtocol},
+		{"bad terminator", "*1\r\n$3\r\nGETxx", nil, errProtocol},
+		{"truncated bulk", "*1\r\n$100\r\nGET\r\n", nil, io.ErrUnexpectedEOF},
+		{"huge truncated bulk", "*1\r\n$536870912\r\nGET\r\n", nil, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := readCommand(bufio.NewReader(strings.NewReader(tt.input)))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Fatalf("expected %q, got %q", tt.args, args)
+			}
+		})
+	}
+}
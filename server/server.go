@@ -0,0 +1,409 @@
+// Package server serves gache.Cache over memcached text protocol
+// and subset of Redis protocol (RESP), so non-Go clients and existing
+// memcached and Redis tooling can use it
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+const (
+	maxKeyLength = 250
+	// maxRelativeExptime is largest expiration time, which is
+	// treated as number of seconds from now. Larger ones are
+	// treated as unix timestamps, as memcached does
+	maxRelativeExptime = 60 * 60 * 24 * 30
+)
+
+// Item presents value stored by memcached clients
+type Item struct {
+	Flags uint32
+	Data  []byte
+	CAS   uint64
+}
+
+// Server serves cache over memcached text protocol.
+// Supported commands are get, gets, set, add, replace, delete,
+// touch, flush_all, version and quit. Key "g:k"
+// addresses value with key k of group g, keys without separator
+// address root group. Groups are created on first store
+type Server struct {
+	cache       gache.Cache
+	separator   string
+	maxItemSize int
+	version     string
+
+	cas uint64
+
+	acceptor
+}
+
+// Option presents type of function, intended for
+// configuring server on creation
+type Option func(*Server)
+
+// WithSeparator sets separator between group and value keys.
+// Default is ":"
+func WithSeparator(separator string) Option {
+	return func(s *Server) {
+		s.separator = separator
+	}
+}
+
+// WithMaxItemSize sets max size of stored data in bytes.
+// Default is 1 MiB
+func WithMaxItemSize(size int) Option {
+	return func(s *Server) {
+		s.maxItemSize = size
+	}
+}
+
+// WithVersion sets version reported by version command
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// New returns server of specified cache
+func New(c gache.Cache, opts ...Option) *Server {
+	s := &Server{
+		cache:       c,
+		separator:   ":",
+		maxItemSize: 1 << 20,
+		version:     "gache",
+		acceptor:    newAcceptor(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// ListenAndServe listens on TCP address and serves connections
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l and serves them
+// until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	return s.serve(l, s.serveConn)
+}
+
+// Close stops listeners and closes active connections
+func (s *Server) Close() error {
+	return s.close()
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			fmt.Fprint(w, "ERROR\r\n")
+		} else if !s.handle(fields, r, w) {
+			w.Flush()
+			return
+		}
+
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle executes command and writes its response.
+// Returns false if connection should be closed
+func (s *Server) handle(fields []string, r *bufio.Reader, w *bufio.Writer) bool {
+	switch cmd, args := fields[0], fields[1:]; cmd {
+	case "get", "gets":
+		s.get(args, cmd == "gets", w)
+	case "set", "add", "replace":
+		return s.store(cmd, args, r, w)
+	case "delete":
+		s.delete(args, w)
+	case "touch":
+		s.touch(args, w)
+	case "flush_all":
+		s.flushAll(args, w)
+	case "version":
+		fmt.Fprintf(w, "VERSION %s\r\n", s.version)
+	case "quit":
+		return false
+	default:
+		fmt.Fprint(w, "ERROR\r\n")
+	}
+
+	return true
+}
+
+func (s *Server) get(keys []string, withCAS bool, w *bufio.Writer) {
+	if len(keys) == 0 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+
+	for _, key := range keys {
+		g, vkey, ok := s.group(key, false)
+		if !ok {
+			continue
+		}
+
+		val, ok := g.Get(vkey)
+		if !ok {
+			continue
+		}
+
+		item := toItem(val)
+		if withCAS {
+			fmt.Fprintf(w, "VALUE %s %d %d %d\r\n", key, item.Flags, len(item.Data), item.CAS)
+		} else {
+			fmt.Fprintf(w, "VALUE %s %d %d\r\n", key, item.Flags, len(item.Data))
+		}
+		w.Write(item.Data)
+		fmt.Fprint(w, "\r\n")
+	}
+
+	fmt.Fprint(w, "END\r\n")
+}
+
+// store handles set, add and replace commands:
+// <cmd> <key> <flags> <exptime> <bytes> [noreply]
+func (s *Server) store(cmd string, args []string, r *bufio.Reader, w *bufio.Writer) bool {
+	if len(args) < 4 || len(args) > 5 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return true
+	}
+
+	noreply := len(args) == 5 && args[4] == "noreply"
+	key := args[0]
+	flags, errFlags := strconv.ParseUint(args[1], 10, 32)
+	exptime, errExptime := strconv.ParseInt(args[2], 10, 64)
+	size, errSize := strconv.Atoi(args[3])
+	if errFlags != nil || errExptime != nil || errSize != nil || size < 0 {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return true
+	}
+
+	if size > s.maxItemSize {
+		// data block is discarded, so connection stays in sync
+		if _, err := io.CopyN(io.Discard, r, int64(size)+2); err != nil {
+			return false
+		}
+		fmt.Fprint(w, "SERVER_ERROR object too large for cache\r\n")
+		return true
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return false
+	}
+	if data[size] != '\r' || data[size+1] != '\n' {
+		fmt.Fprint(w, "CLIENT_ERROR bad data chunk\r\n")
+		return true
+	}
+
+	if !validKey(key) {
+		fmt.Fprint(w, "CLIENT_ERROR bad key\r\n")
+		return true
+	}
+
+	g, vkey, _ := s.group(key, true)
+	item := Item{
+		Flags: uint32(flags),
+		Data:  data[:size],
+		CAS:   atomic.AddUint64(&s.cas, 1),
+	}
+
+	ttl, expired := expiration(exptime)
+	stored := true
+	switch {
+	case expired:
+		// value expiring immediately replaces existing one
+		// and is removed right away, as memcached does
+		_, exists := g.TTL(vkey)
+		if cmd == "add" {
+			stored = !exists
+		} else if cmd == "replace" {
+			stored = exists
+		}
+		if exists && cmd != "add" {
+			g.Del(vkey)
+		}
+	case cmd == "set":
+		g.SetWithTTL(vkey, item, ttl)
+	case cmd == "add":
+		stored = g.AddWithTTL(vkey, item, ttl) == nil
+	case cmd == "replace":
+		stored = g.ReplaceWithTTL(vkey, item, ttl) == nil
+	}
+
+	if !noreply {
+		if stored {
+			fmt.Fprint(w, "STORED\r\n")
+		} else {
+			fmt.Fprint(w, "NOT_STORED\r\n")
+		}
+	}
+
+	return true
+}
+
+// delete handles delete <key> [noreply] command
+func (s *Server) delete(args []string, w *bufio.Writer) {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+
+	noreply := len(args) == 2 && args[1] == "noreply"
+
+	found := false
+	if g, vkey, ok := s.group(args[0], false); ok {
+		if _, found = g.TTL(vkey); found {
+			g.Del(vkey)
+		}
+	}
+
+	if noreply {
+		return
+	}
+
+	if found {
+		fmt.Fprint(w, "DELETED\r\n")
+	} else {
+		fmt.Fprint(w, "NOT_FOUND\r\n")
+	}
+}
+
+// touch handles touch <key> <exptime> [noreply] command
+func (s *Server) touch(args []string, w *bufio.Writer) {
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Fprint(w, "ERROR\r\n")
+		return
+	}
+
+	noreply := len(args) == 3 && args[2] == "noreply"
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		fmt.Fprint(w, "CLIENT_ERROR invalid exptime argument\r\n")
+		return
+	}
+
+	found := false
+	if g, vkey, ok := s.group(args[0], false); ok {
+		if ttl, expired := expiration(exptime); expired {
+			if _, found = g.TTL(vkey); found {
+				g.Del(vkey)
+			}
+		} else {
+			found = g.Touch(vkey, ttl)
+		}
+	}
+
+	if noreply {
+		return
+	}
+
+	if found {
+		fmt.Fprint(w, "TOUCHED\r\n")
+	} else {
+		fmt.Fprint(w, "NOT_FOUND\r\n")
+	}
+}
+
+// flushAll handles flush_all [delay] [noreply] command.
+// Delayed flushes aren't supported, so delay is ignored
+func (s *Server) flushAll(args []string, w *bufio.Writer) {
+	noreply := len(args) > 0 && args[len(args)-1] == "noreply"
+
+	s.cache.Flush(false)
+
+	if !noreply {
+		fmt.Fprint(w, "OK\r\n")
+	}
+}
+
+// group returns group, which value with specified key belongs to,
+// and key of value in group. If create is true, missing group
+// is created
+func (s *Server) group(key string, create bool) (gache.Group, string, bool) {
+	i := strings.Index(key, s.separator)
+	if s.separator == "" || i < 0 {
+		return s.cache, key, true
+	}
+
+	g, ok := lookupGroup(s.cache, key[:i], create)
+
+	return g, key[i+len(s.separator):], ok
+}
+
+// expiration converts memcached expiration time to live duration.
+// Returns true if value expires immediately
+func expiration(exptime int64) (time.Duration, bool) {
+	switch {
+	case exptime == 0:
+		return 0, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= maxRelativeExptime:
+		return time.Duration(exptime) * time.Second, false
+	}
+
+	ttl := time.Until(time.Unix(exptime, 0))
+	return ttl, ttl <= 0
+}
+
+// toItem returns item for value, which may be set by Go code
+func toItem(val interface{}) Item {
+	switch v := val.(type) {
+	case Item:
+		return v
+	case []byte:
+		return Item{Data: v}
+	case string:
+		return Item{Data: []byte(v)}
+	default:
+		return Item{Data: []byte(fmt.Sprint(v))}
+	}
+}
+
+func validKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcasctiv/gache"
+)
+
+// session presents client side of connection to server
+type session struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newSession starts serving on local listener
+// and returns session connected to it
+func newSession(t *testing.T, serve func(net.Listener) error) *session {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &session{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// do sends request and reads specified number of response lines
+func (s *session) do(req string, lines int) string {
+	s.t.Helper()
+
+	if _, err := s.conn.Write([]byte(req)); err != nil {
+		s.t.Fatalf("writing request %q: %v", req, err)
+	}
+
+	var resp strings.Builder
+	for i := 0; i < lines; i++ {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("reading response to %q: %v", req, err)
+		}
+		resp.WriteString(line)
+	}
+
+	return resp.String()
+}
+
+func TestMemcached(t *testing.T) {
+	c := gache.NewCache()
+	defer c.Close()
+	srv := New(c)
+	defer srv.Close()
+	s := newSession(t, srv.Serve)
+
+	tests := []struct {
+		req   string
+		lines int
+		resp  string
+	}{
+		{"set g:a 5 0 3\r\nabc\r\n", 1, "STORED\r\n"},
+		{"get g:a b\r\n", 3, "VALUE g:a 5 3\r\nabc\r\nEND\r\n"},
+		{"add g:a 0 0 1\r\nx\r\n", 1, "NOT_STORED\r\n"},
+		{"add g:b 0 0 1\r\nx\r\n", 1, "STORED\r\n"},
+		{"replace c 0 0 1\r\nx\r\n", 1, "NOT_STORED\r\n"},
+		{"replace g:b 0 0 1\r\ny\r\n", 1, "STORED\r\n"},
+		{"get g:b\r\n", 3, "VALUE g:b 0 1\r\ny\r\nEND\r\n"},
+		{"touch g:a 100\r\n", 1, "TOUCHED\r\n"},
+		{"touch c 100\r\n", 1, "NOT_FOUND\r\n"},
+		{"delete g:a\r\n", 1, "DELETED\r\n"},
+		{"delete g:a\r\n", 1, "NOT_FOUND\r\n"},
+		{"set c 0 -1 1\r\nx\r\nget c\r\n", 2, "STORED\r\nEND\r\n"},
+		{"set c 0 0 1 noreply\r\nz\r\nget c\r\n", 3, "VALUE c 0 1\r\nz\r\nEND\r\n"},
+		{"set c 0 0 1\r\nxyz\r\n", 2, "CLIENT_ERROR bad data chunk\r\nERROR\r\n"},
+		{"set c x 0 1\r\n", 1, "CLIENT_ERROR bad command line format\r\n"},
+		{"set c 0 0\r\n", 1, "ERROR\r\n"},
+		{"bogus\r\n", 1, "ERROR\r\n"},
+		{"flush_all\r\nget c g:b\r\n", 2, "OK\r\nEND\r\n"},
+		{"version\r\n", 1, "VERSION gache\r\n"},
+	}
+
+	for _, tt := range tests {
+		if resp := s.do(tt.req, tt.lines); resp != tt.resp {
+			t.Fatalf("%q: expected %q, got %q", tt.req, tt.resp, resp)
+		}
+	}
+}
+
+func TestMemcachedAddTTL(t *testing.T) {
+	c := gache.NewCache(gache.WithExpiration(time.Minute))
+	defer c.Close()
+	srv := New(c)
+	defer srv.Close()
+	s := newSession(t, srv.Serve)
+
+	s.do("add a 0 100 1\r\nx\r\nadd b 0 0 1\r\nx\r\n", 2)
+	if ttl, ok := c.TTL("a"); !ok || ttl <= time.Minute || ttl > 100*time.Second {
+		t.Fatalf("expected TTL of added value, got %v, %v", ttl, ok)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != 0 {
+		t.Fatalf("expected added value without expiration, got %v, %v", ttl, ok)
+	}
+
+	s.do("replace a 0 0 1\r\ny\r\n", 1)
+	if ttl, ok := c.TTL("a"); !ok || ttl != 0 {
+		t.Fatalf("expected replaced value without expiration, got %v, %v", ttl, ok)
+	}
+}
+
+func TestMemcachedTooLarge(t *testing.T) {
+	c := gache.NewCache()
+	defer c.Close()
+	srv := New(c, WithMaxItemSize(2))
+	defer srv.Close()
+	s := newSession(t, srv.Serve)
+
+	req := "set a 0 0 3\r\nabc\r\nget a\r\n"
+	if resp := s.do(req, 2); resp != "SERVER_ERROR object too large for cache\r\nEND\r\n" {
+		t.Fatalf("expected oversized item to be discarded, got %q", resp)
+	}
+}
+
+func TestRESP(t *testing.T) {
+	c := gache.NewCache()
+	defer c.Close()
+	srv := NewRESP(c)
+	defer srv.Close()
+	s := newSession(t, srv.Serve)
+
+	tests := []struct {
+		req   string
+		lines int
+		resp  string
+	}{
+		{"PING\r\n", 1, "+PONG\r\n"},
+		{"*3\r\n$3\r\nSET\r\n$1\r\na\r\n$2\r\nbc\r\n", 1, "+OK\r\n"},
+		{"*2\r\n$3\r\nGET\r\n$1\r\na\r\n", 2, "$2\r\nbc\r\n"},
+		{"*4\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\nx\r\n$2\r\nNX\r\n", 1, "$-1\r\n"},
+		{"*4\r\n$3\r\nSET\r\n$1\r\nb\r\n$1\r\nx\r\n$2\r\nXX\r\n", 1, "$-1\r\n"},
+		{"*5\r\n$3\r\nSET\r\n$1\r\nb\r\n$1\r\nx\r\n$2\r\nEX\r\n$2\r\n10\r\n", 1, "+OK\r\n"},
+		{"*2\r\n$3\r\nTTL\r\n$1\r\nb\r\n", 1, ":10\r\n"},
+		{"*2\r\n$3\r\nTTL\r\n$1\r\na\r\n", 1, ":-1\r\n"},
+		{"*2\r\n$6\r\nSELECT\r\n$1\r\ng\r\n", 1, "+OK\r\n"},
+		{"*2\r\n$3\r\nGET\r\n$1\r\na\r\n", 1, "$-1\r\n"},
+		{"*2\r\n$6\r\nSELECT\r\n$1\r\n0\r\n", 1, "+OK\r\n"},
+		{"*3\r\n$3\r\nDEL\r\n$1\r\na\r\n$1\r\nc\r\n", 1, ":1\r\n"},
+		{"*1\r\n$7\r\nUNKNOWN\r\n", 1, "-ERR unknown command 'UNKNOWN'\r\n"},
+		{"*0\r\n", 1, "-ERR Protocol error\r\n"},
+	}
+
+	for _, tt := range tests {
+		if resp := s.do(tt.req, tt.lines); resp != tt.resp {
+			t.Fatalf("%q: expected %q, got %q", tt.req, tt.resp, resp)
+		}
+	}
+}
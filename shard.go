@@ -0,0 +1,271 @@
+package gache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type value struct {
+	data       interface{}
+	created    int64
+	expiration int64
+	ttl        time.Duration
+	cost       int64
+	elem       *list.Element
+	expiry     *expiryEntry
+	access     *access
+	pinned     bool
+	version    uint64
+}
+
+// expired reports whether value is expired at now.
+// Pinned value never expires
+func (v value) expired(now int64) bool {
+	return !v.pinned && v.expiration != 0 && v.expiration <= now
+}
+
+// remaining returns live duration of value left at now.
+// Zero duration means value never expires
+func (v value) remaining(now int64) time.Duration {
+	if v.expiration == 0 || v.pinned {
+		return 0
+	}
+
+	return time.Duration(v.expiration - now)
+}
+
+// shard presents part of group values,
+// guarded by its own mutex
+type shard struct {
+	group      *group
+	mx         sync.RWMutex
+	values     map[string]value
+	maxEntries int
+	cost       int64
+	maxCost    int64
+	lru        *list.List
+	admission  *tinyLFU
+	calls      map[string]*call
+	removed    []removal
+	events     []Event
+	expiries   expiryHeap
+	slabs      *slabArena
+	// refreshes are background refreshes,
+	// which are started on unlocking
+	refreshes []refreshJob
+	// retries holds backoff of fills, which failed recently
+	retries map[string]retryState
+	// version is the last version assigned to value of shard
+	version uint64
+}
+
+func newShard(g *group, maxEntries int, maxCost int64, tinyLFU bool) *shard {
+	s := &shard{
+		group:      g,
+		values:     make(map[string]value),
+		maxEntries: maxEntries,
+		maxCost:    maxCost,
+		calls:      make(map[string]*call),
+	}
+
+	if maxEntries > 0 || maxCost > 0 {
+		s.lru = list.New()
+
+		if tinyLFU {
+			s.admission = newTinyLFU(maxEntries)
+		}
+	}
+
+	return s
+}
+
+// store puts value into shard with cost, computed by Coster
+// of group. See storeWithCost.
+// Must be called with locked mutex
+func (s *shard) store(key string, data interface{}, now time.Time, ttl time.Duration) {
+	var cost int64
+	if s.group.coster != nil {
+		cost = s.group.coster(data)
+	}
+
+	s.storeWithCost(key, data, now, ttl, cost)
+}
+
+// storeWithCost puts value with specified live duration, randomized
+// by jitter of group, into shard,
+// marks it as most recently used and evicts least recently used
+// values on overflow.
+// New value may be rejected by admission filter of shard.
+// Must be called with locked mutex
+func (s *shard) storeWithCost(key string, data interface{}, now time.Time, ttl time.Duration, cost int64) {
+	v, ok := s.values[key]
+
+	if s.admission != nil {
+		s.admission.record(key)
+		if !ok && !s.admit(key, cost) {
+			atomic.AddUint64(&s.group.stats.rejections, 1)
+			return
+		}
+	}
+
+	if !ok {
+		key = s.group.cache.interner.intern(key)
+	}
+
+	s.cost += cost - v.cost
+	s.release(v.data)
+	v.data = s.pack(data)
+	v.created = now.UnixNano()
+	v.access = &access{}
+	s.expire(key, &v, expireAt(now, s.group.jitter(ttl)))
+	v.ttl = ttl
+	v.cost = cost
+	s.version++
+	v.version = s.version
+
+	if s.lru != nil && !v.pinned {
+		if ok {
+			s.lru.MoveToFront(v.elem)
+		} else {
+			v.elem = s.lru.PushFront(key)
+		}
+	}
+
+	s.values[key] = v
+	s.compactSlabs()
+	if _, ok := data.(absence); !ok {
+		s.event(EventSet, key, data)
+	}
+
+	// pinned values aren't tracked by recency list,
+	// so they are never evicted
+	for s.overflowed() && s.lru.Len() > 0 {
+		s.removeAs(s.lru.Back().Value.(string), EventEvict)
+		atomic.AddUint64(&s.group.stats.evictions, 1)
+	}
+}
+
+// replace changes data of existing value v with specified key,
+// keeping its expiration, and evicts least recently used values
+// on overflow, if cost of value grows.
+// Must be called with locked mutex
+func (s *shard) replace(key string, v value, data interface{}) {
+	var cost int64
+	if s.group.coster != nil {
+		cost = s.group.coster(data)
+	}
+
+	// v may hold unpacked data, so data kept by shard is released
+	s.cost += cost - v.cost
+	s.release(s.values[key].data)
+	v.data = s.pack(data)
+	v.cost = cost
+	s.version++
+	v.version = s.version
+	s.values[key] = v
+	s.compactSlabs()
+	s.event(EventSet, key, data)
+
+	for s.overflowed() && s.lru.Len() > 0 {
+		s.removeAs(s.lru.Back().Value.(string), EventEvict)
+		atomic.AddUint64(&s.group.stats.evictions, 1)
+	}
+}
+
+// overflowed reports whether shard exceeds its limits.
+// Must be called with locked mutex
+func (s *shard) overflowed() bool {
+	if len(s.values) == 0 {
+		return false
+	}
+
+	return (s.maxEntries > 0 && len(s.values) > s.maxEntries) ||
+		(s.maxCost > 0 && s.cost > s.maxCost)
+}
+
+// lookup returns unexpired value with specified key and unpacked
+// data and marks it as most recently used, extending its expiration
+// for group with sliding expiration. Expired value is removed from
+// shard, unless it is kept for fill failures. Value, which can't be
+// unpacked, is passed to InvalidValueFunc of cache and removed.
+// Must be called with locked mutex
+func (s *shard) lookup(key string, now int64) (value, bool) {
+	v, ok := s.values[key]
+	if !ok {
+		return value{}, false
+	}
+
+	if v.expired(now) {
+		if !s.group.inGrace(v, now) {
+			s.removeAs(key, EventExpire)
+			atomic.AddUint64(&s.group.stats.expirations, 1)
+		}
+		return value{}, false
+	}
+
+	if s.group.sliding && v.ttl > 0 && !v.absent() {
+		s.expire(key, &v, now+int64(v.ttl))
+		s.values[key] = v
+	}
+
+	s.touch(key, v, now)
+
+	v, err := v.unpacked()
+	if err != nil {
+		s.group.reject(key, nil, err)
+		s.remove(key)
+		return value{}, false
+	}
+
+	return v, true
+}
+
+// touch accounts access to value at now
+// and marks it as most recently used.
+// Must be called with locked mutex
+func (s *shard) touch(key string, v value, now int64) {
+	v.access.record(now)
+
+	if s.admission != nil {
+		s.admission.record(key)
+	}
+
+	if s.lru != nil && !v.pinned {
+		s.lru.MoveToFront(v.elem)
+	}
+}
+
+// remove deletes value with specified key from shard.
+// Must be called with locked mutex
+func (s *shard) remove(key string) {
+	s.removeAs(key, EventDel)
+}
+
+// removeAs deletes value with specified key from shard
+// and records event of specified type for it.
+// Must be called with locked mutex
+func (s *shard) removeAs(key string, typ EventType) {
+	v, ok := s.values[key]
+	if !ok {
+		return
+	}
+
+	if s.lru != nil && !v.pinned {
+		s.lru.Remove(v.elem)
+	}
+	s.unexpire(v)
+
+	delete(s.values, key)
+	s.cost -= v.cost
+	s.release(v.data)
+	s.group.cache.interner.release(key)
+
+	if !v.absent() {
+		if s.group.cache.evictFunc() != nil {
+			s.removed = append(s.removed, removal{key: key, data: v.data})
+		}
+		s.event(typ, key, v.data)
+	}
+}
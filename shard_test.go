@@ -0,0 +1,54 @@
+package gache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestShards(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g", WithShards(8))
+	g, _ := c.Group("g")
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := strconv.Itoa(w*100 + i)
+				g.Set(key, key)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if n := g.Stats().Items; n != 800 {
+		t.Fatalf("expected 800 values, got %d", n)
+	}
+
+	used := 0
+	for _, s := range g.(*group).shards {
+		if len(s.values) > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Fatalf("expected values spread over shards, got %d used", used)
+	}
+}
+
+func TestShardMaxEntries(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g", WithShards(4), WithMaxEntries(10))
+	g, _ := c.Group("g")
+
+	for i := 0; i < 1000; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+
+	if n := g.Stats().Items; n > 12 {
+		t.Fatalf("expected limit split between shards, got %d values", n)
+	}
+}
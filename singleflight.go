@@ -0,0 +1,169 @@
+package gache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// call presents in-flight or completed fill of group value.
+// Concurrent Get calls for the same key wait for single
+// call and share its result
+type call struct {
+	done       chan struct{}
+	data       interface{}
+	expiration int64
+	version    uint64
+	ok         bool
+	// refresh reports whether call refreshes unexpired value,
+	// which is kept if refreshing fails
+	refresh bool
+	// abandoned reports whether fill was abandoned on timeout
+	// or short-circuited by breaker, so absence of key isn't cached
+	abandoned bool
+	// rejected reports whether fill was rejected by rate limiter,
+	// so expired value isn't returned instead
+	rejected bool
+	// uncached reports whether filled value isn't stored,
+	// because group skips caching of oversized values
+	uncached bool
+	// err is reason of failure of fill other than missing
+	// value, e.g. panic, timeout or validation rejection
+	err error
+}
+
+// fill fetches value for key from peer, which owns it, looks it up
+// in store of cache or invokes filling function for it, stores result
+// and releases callers waiting for c. Filled value is written
+// to store of cache. Live duration reported by filling function
+// overrides expiration
+func (s *shard) fill(ctx context.Context, key string, c *call, fillFunc FillFuncCtx, expiration time.Duration, now time.Time) {
+	defer func() { s.complete(key, c, expiration, now) }()
+
+	g := s.group
+	if val, found, ok := g.loadFromPeer(ctx, key); ok {
+		c.data, c.ok = val, found
+		g.accept(key, c)
+		return
+	}
+
+	if g.cache.store != nil {
+		if c.data, c.ok = g.load(ctx, key); c.ok {
+			if g.accept(key, c); c.ok {
+				return
+			}
+		}
+	}
+
+	if fillFunc == nil {
+		return
+	}
+
+	if g.breaker != nil && !g.breaker.allow(g.now()) {
+		c.abandoned, c.err = true, ErrBreakerOpen
+		return
+	}
+
+	if g.limiter != nil && !g.allowFill(ctx) {
+		if g.breaker != nil {
+			g.breaker.release()
+		}
+		c.abandoned, c.err = true, ErrFillRateLimited
+		c.rejected = g.limiter.policy == RateLimitMiss
+		return
+	}
+
+	fillFunc = g.cache.wrapFill(g.key, fillFunc)
+
+	ttl := &fillTTL{}
+	ctx = context.WithValue(ctx, fillTTLKey{}, ttl)
+
+	atomic.AddUint64(&g.stats.fills, 1)
+	c.data, c.ok, c.err = g.invokeFill(ctx, fillFunc, key)
+	// fill, which panicked, completed and is treated as miss
+	c.abandoned = c.err != nil && c.err != ErrFillPanic
+	g.accept(key, c)
+
+	if c.ok && ttl.ttl > 0 {
+		expiration = ttl.ttl
+	}
+
+	if g.breaker != nil {
+		g.breaker.record(c.ok, g.now())
+	}
+
+	if c.ok && !c.uncached {
+		g.persist(key, c.data, expiration)
+	}
+}
+
+// invokeFill invokes filling function for key. If group limits
+// duration of fills, filling function runs in its own goroutine
+// and is abandoned, when timeout elapses or ctx is done.
+// Returned error is ErrFillPanic, if filling function panicked,
+// or reason of abandoning fill
+func (g *group) invokeFill(ctx context.Context, fillFunc FillFuncCtx, key string) (val interface{}, ok bool, err error) {
+	if g.fillTimeout == 0 {
+		return g.callFill(ctx, fillFunc, key)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		val interface{}
+		ok  bool
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		val, ok, err := g.callFill(ctx, fillFunc, key)
+		done <- result{val: val, ok: ok, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.val, r.ok, r.err
+	case <-g.cache.clock.After(g.fillTimeout):
+		return nil, false, ErrFillTimeout
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// complete stores result of call for key and releases callers
+// waiting for it. If fill failed, callers get expired value kept
+// for fill failures, otherwise absence of key is stored, if group
+// caches it, or value is removed
+func (s *shard) complete(key string, c *call, expiration time.Duration, now time.Time) {
+	s.mx.Lock()
+	s.recordFill(key, c.ok, now.UnixNano())
+	if c.ok && c.uncached {
+		c.expiration = expireAt(now, expiration)
+		s.remove(key)
+	} else if c.ok {
+		c.expiration = expireAt(now, expiration)
+		s.store(key, c.data, now, expiration)
+		if v, ok := s.values[key]; ok {
+			c.expiration, c.version = v.expiration, v.version
+		}
+	} else {
+		atomic.AddUint64(&s.group.stats.fillFailures, 1)
+		s.event(EventFillFailed, key, c.err)
+
+		if v, ok := s.graceValue(key); ok && !c.rejected {
+			c.data, c.expiration, c.version, c.ok = v.val(), v.expiration, v.version, true
+		} else if !c.refresh && !c.abandoned {
+			if ttl := s.group.negativeTTL; ttl > 0 {
+				s.storeWithCost(key, absence{}, now, ttl, 1)
+			} else {
+				s.remove(key)
+			}
+		}
+	}
+	delete(s.calls, key)
+	s.unlock()
+
+	close(c.done)
+}
@@ -0,0 +1,159 @@
+package gache
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFillCoalescing(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return key + "!", true
+	}))
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Get("a")
+		}(i)
+	}
+
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) > 0 })
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected single fill, got %d", n)
+	}
+	for i, v := range results {
+		if v != "a!" {
+			t.Fatalf("expected filled value for caller %d, got %v", i, v)
+		}
+	}
+}
+
+func TestFillFailure(t *testing.T) {
+	var calls int32
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		atomic.AddInt32(&calls, 1)
+		return nil, false
+	}))
+
+	for i := 0; i < 2; i++ {
+		if v, ok := c.Get("a"); ok {
+			t.Fatalf("expected miss, got %v", v)
+		}
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected failed fill not to be cached, got %d calls", n)
+	}
+}
+
+type ctxKey struct{}
+
+func TestGetCtx(t *testing.T) {
+	c := NewCache()
+	c.SetFillFuncCtx(func(ctx context.Context, key string) (interface{}, bool) {
+		return ctx.Value(ctxKey{}), true
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "from ctx")
+	if v, ok := c.GetCtx(ctx, "a"); !ok || v != "from ctx" {
+		t.Fatalf("expected value from fill context, got %v, %v", v, ok)
+	}
+}
+
+func TestGetCtxCancelWait(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		close(started)
+		<-release
+		return 1, true
+	}))
+
+	go c.Get("a")
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v, ok := c.GetCtx(ctx, "a"); ok {
+		t.Fatalf("expected canceled waiter to give up, got %v", v)
+	}
+}
+
+func TestFillPanic(t *testing.T) {
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		panic("boom " + key)
+	}))
+	defer c.Close()
+	c.NewGroup("g", WithBatchFillFunc(func(keys []string) map[string]interface{} {
+		panic("batch boom")
+	}))
+
+	type report struct {
+		group, key string
+		recovered  interface{}
+	}
+	var reports []report
+	c.OnFillPanic(func(group, key string, recovered interface{}, stack []byte) {
+		if len(stack) == 0 {
+			t.Error("expected stack of panic")
+		}
+		reports = append(reports, report{group, key, recovered})
+	})
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected panicking fill to be treated as miss")
+	}
+	if vals, _ := c.GetGroupValMulti("g", []string{"b"}); len(vals) != 0 {
+		t.Fatalf("expected panicking batch fill to be treated as miss, got %v", vals)
+	}
+
+	expected := []report{{"", "a", "boom a"}, {"g", "", "batch boom"}}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Fatalf("expected %v, got %v", expected, reports)
+	}
+}
+
+func TestFillTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var fills int32
+	c := NewCache(WithFillTimeout(10*time.Millisecond), WithNegativeCaching(time.Minute),
+		WithFillFuncCtx(func(ctx context.Context, key string) (interface{}, bool) {
+			if atomic.AddInt32(&fills, 1) > 1 {
+				return "filled", true
+			}
+			// fill ignores cancellation and hangs
+			<-release
+			return "late", true
+		}))
+	defer c.Close()
+
+	start := time.Now()
+	if v, ok := c.Get("a"); ok {
+		t.Fatalf("expected hung fill to be abandoned, got %v", v)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("expected fill abandoned on timeout, took %v", d)
+	}
+
+	// absence of key isn't cached for abandoned fill
+	if v, ok := c.Get("a"); !ok || v != "filled" {
+		t.Fatalf("expected key to be filled again, got %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,161 @@
+package gache
+
+import "fmt"
+
+// slabEntry wraps value, so codec keeps its concrete type
+type slabEntry struct {
+	Value interface{}
+}
+
+// slabRef presents value of group, which is kept
+// serialized in slab storage
+type slabRef struct {
+	buf   []byte
+	codec Codec
+}
+
+// slabArena presents slab storage of shard. Serialized values are
+// appended to the last slab and never overwritten, so they may be
+// read after shard mutex is unlocked. Values are still indexed by
+// map of shard, but their contents take a few large allocations
+// without pointers instead of many small objects. Slabs without
+// referenced values are freed by garbage collector
+type slabArena struct {
+	size int
+	slab []byte
+	// allocated is total size of slabs allocated since compaction
+	allocated int
+	// live is size of values, which are referenced by shard
+	live int
+}
+
+// put copies data into slab storage and returns its copy
+func (a *slabArena) put(data []byte) []byte {
+	if len(data) > cap(a.slab)-len(a.slab) {
+		size := a.size
+		if len(data) > size {
+			size = len(data)
+		}
+		a.slab = make([]byte, 0, size)
+		a.allocated += size
+	}
+
+	off := len(a.slab)
+	a.slab = append(a.slab, data...)
+	a.live += len(data)
+
+	return a.slab[off:len(a.slab):len(a.slab)]
+}
+
+// wasteful reports whether most of allocated slabs
+// are taken by removed values
+func (a *slabArena) wasteful() bool {
+	return a.allocated > 4*a.size && a.allocated > 2*a.live
+}
+
+// pack returns representation of data, which is kept in shard:
+// data serialized into slab storage, if shard has one, or data
+// compressed by compressor of group.
+// Must be called with locked mutex
+func (s *shard) pack(data interface{}) interface{} {
+	if s.slabs == nil {
+		return s.group.compress(data)
+	}
+
+	if _, ok := data.(absence); ok {
+		return data
+	}
+
+	codec := s.group.codec
+	if codec == nil {
+		codec = GobCodec{}
+	}
+
+	b, err := codec.Marshal(&slabEntry{Value: data})
+	if err != nil {
+		return data
+	}
+
+	return slabRef{buf: s.slabs.put(b), codec: codec}
+}
+
+// release accounts removal of data, kept in shard.
+// Must be called with locked mutex
+func (s *shard) release(data interface{}) {
+	if r, ok := data.(slabRef); ok {
+		s.slabs.live -= len(r.buf)
+	}
+}
+
+// compactSlabs copies values, referenced by shard, into new slabs,
+// so old ones may be freed, if most of slabs are taken by removed
+// values. It must be called after stored value replaces the old
+// one, so the old one isn't copied.
+// Must be called with locked mutex
+func (s *shard) compactSlabs() {
+	if s.slabs == nil || !s.slabs.wasteful() {
+		return
+	}
+
+	a := &slabArena{size: s.slabs.size}
+	for key, v := range s.values {
+		if r, ok := v.data.(slabRef); ok {
+			r.buf = a.put(r.buf)
+			v.data = r
+			s.values[key] = v
+		}
+	}
+
+	s.slabs = a
+}
+
+// unpack returns data of group value, which is kept packed by shard.
+// Error wrapping ErrCorruptValue is returned for data,
+// which can't be deserialized or decompressed
+func unpack(data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case compressed:
+		return decompress(d)
+	case slabRef:
+		var e slabEntry
+		if err := d.codec.Unmarshal(d.buf, &e); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorruptValue, err)
+		}
+		return e.Value, nil
+	}
+
+	return data, nil
+}
+
+// val returns data of value, unpacking it if necessary.
+// Data, which can't be unpacked, is returned as nil
+func (v value) val() interface{} {
+	data, _ := unpack(v.data)
+	return data
+}
+
+// unpacked returns value with unpacked data,
+// so it isn't unpacked again on reading
+func (v value) unpacked() (value, error) {
+	data, err := unpack(v.data)
+	if err != nil {
+		return v, err
+	}
+
+	v.data = data
+
+	return v, nil
+}
+
+// read returns unpacked data of value with specified key. Value,
+// which can't be unpacked, is passed to InvalidValueFunc of cache
+// and is treated as missing
+func (g *group) read(key string, v value) (interface{}, bool) {
+	data, err := unpack(v.data)
+	if err != nil {
+		g.reject(key, nil, err)
+		return nil, false
+	}
+
+	return data, true
+}
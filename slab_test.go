@@ -0,0 +1,138 @@
+package gache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestSlabStorage(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithSlabStorage(64), WithShards(1))
+	g, _ := c.Group("g")
+
+	for i := 0; i < 100; i++ {
+		g.Set("k", strconv.Itoa(i))
+		g.Set(strconv.Itoa(i%10), i)
+	}
+
+	if v, ok := g.Get("k"); !ok || v != "99" {
+		t.Fatalf("expected value from slab, got %v, %v", v, ok)
+	}
+	if v, ok := g.Get("9"); !ok || v != 99 {
+		t.Fatalf("expected value from slab, got %v, %v", v, ok)
+	}
+	if n, err := g.Increment("9", 1); err != nil || n != 100 {
+		t.Fatalf("expected incremented value from slab, got %v, %v", n, err)
+	}
+
+	s := g.(*group).shards[0]
+	s.mx.RLock()
+	_, ok := s.values["k"].data.(slabRef)
+	a := *s.slabs
+	s.mx.RUnlock()
+	if !ok {
+		t.Fatal("expected value kept in slab")
+	}
+	if a.wasteful() {
+		t.Fatalf("expected slabs compacted, got %d bytes allocated for %d live", a.allocated, a.live)
+	}
+}
+
+func TestSlabStorageUnsupported(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithSlabStorage(64))
+	g, _ := c.Group("g")
+
+	ch := make(chan int)
+	g.Set("ch", ch)
+	if v, ok := g.Get("ch"); !ok || v != ch {
+		t.Fatalf("expected value unsupported by codec kept as is, got %v, %v", v, ok)
+	}
+}
+
+// liveSlabs returns size of values referenced by shard
+func liveSlabs(s *shard) int {
+	var n int
+	for _, v := range s.values {
+		if r, ok := v.data.(slabRef); ok {
+			n += len(r.buf)
+		}
+	}
+
+	return n
+}
+
+func TestSlabAccounting(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	g := c.GetOrCreateGroup("g", WithSlabStorage(256), WithShards(1))
+	s := c.(*cache).groups["g"].shards[0]
+
+	for i := 0; i < 1000; i++ {
+		g.Set("k", strconv.Itoa(i))
+		g.Set(strconv.Itoa(i%10), i)
+		g.Increment("n", 1)
+		if i%3 == 0 {
+			g.Del(strconv.Itoa(i % 7))
+		}
+
+		if live := liveSlabs(s); s.slabs.live != live {
+			t.Fatalf("step %d: expected %d live bytes, got %d", i, live, s.slabs.live)
+		}
+	}
+
+	if s.slabs.allocated > 8*256 {
+		t.Fatalf("expected slabs to be compacted, got %d allocated bytes", s.slabs.allocated)
+	}
+	if v, _ := g.Get("k"); v != "999" {
+		t.Fatalf("expected 999, got %v", v)
+	}
+	if v, _ := g.Get("n"); v != int64(1000) {
+		t.Fatalf("expected 1000, got %v", v)
+	}
+}
+
+func TestSlabCorruptValue(t *testing.T) {
+	var rejected error
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.OnInvalidValue(func(group, key string, val interface{}, err error) {
+		rejected = err
+	})
+
+	fills := 0
+	g := c.GetOrCreateGroup("g", WithSlabStorage(256), WithFillFunc(func(key string) (interface{}, bool) {
+		fills++
+		return "filled", true
+	}))
+	g.Set("a", "v")
+	g.Set("b", "v")
+
+	// corrupt data of values
+	for _, s := range c.(*cache).groups["g"].shards {
+		for key, v := range s.values {
+			r := v.data.(slabRef)
+			r.buf = []byte{0xff}
+			v.data = r
+			s.values[key] = v
+		}
+	}
+
+	if v, ok := g.Peek("b"); ok {
+		t.Fatalf("expected miss for corrupt value, got %v", v)
+	}
+	if !errors.Is(rejected, ErrCorruptValue) {
+		t.Fatalf("expected ErrCorruptValue, got %v", rejected)
+	}
+
+	rejected = nil
+	if v, ok := g.Get("a"); !ok || v != "filled" || fills != 1 {
+		t.Fatalf("expected corrupt value to be filled, got %v, %v after %d fills", v, ok, fills)
+	}
+	if !errors.Is(rejected, ErrCorruptValue) {
+		t.Fatalf("expected ErrCorruptValue, got %v", rejected)
+	}
+}
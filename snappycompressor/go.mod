@@ -0,0 +1,7 @@
+module github.com/kcasctiv/gache/snappycompressor
+
+go 1.21
+
+require github.com/golang/snappy v1.0.0
+
+replace github.com/kcasctiv/gache => ../
@@ -0,0 +1,19 @@
+// Package snappycompressor provides gache.Compressor
+// implementation, which uses Snappy
+package snappycompressor
+
+import "github.com/golang/snappy"
+
+// Compressor is gache.Compressor, which uses Snappy.
+// It is fast, but compresses worse than gzip
+type Compressor struct{}
+
+// Compress returns Snappy compressed data
+func (Compressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+// Decompress returns data decompressed from Snappy compressed one
+func (Compressor) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
@@ -0,0 +1,23 @@
+package snappycompressor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressor(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 64)
+
+	z, err := Compressor{}.Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(z) >= len(data) {
+		t.Fatalf("expected data to shrink, got %d bytes", len(z))
+	}
+
+	out, err := Compressor{}.Decompress(z)
+	if err != nil || !bytes.Equal(out, data) {
+		t.Fatalf("expected %q, got %q, %v", data, out, err)
+	}
+}
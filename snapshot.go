@@ -0,0 +1,163 @@
+package gache
+
+import (
+	"io"
+	"time"
+)
+
+type snapshot struct {
+	Groups []groupSnapshot
+}
+
+type groupSnapshot struct {
+	Key        string
+	Root       bool
+	Expiration time.Duration
+	// FillFunc is name of registered filling function of group
+	FillFunc string
+	Items    []itemSnapshot
+}
+
+type itemSnapshot struct {
+	Key   string
+	Value interface{}
+	// TTL is remaining live duration of value,
+	// zero means value never expires
+	TTL time.Duration
+}
+
+func (c *cache) SaveTo(w io.Writer) error {
+	c.mx.RLock()
+	keys := make([]string, 0, len(c.groups))
+	groups := make([]*group, 0, len(c.groups))
+	for key, g := range c.groups {
+		keys = append(keys, key)
+		groups = append(groups, g)
+	}
+	c.mx.RUnlock()
+
+	now := c.clock.Now()
+	snap := snapshot{
+		Groups: make([]groupSnapshot, 0, len(groups)+1),
+	}
+
+	root := c.group.snapshot(now)
+	root.Root = true
+	snap.Groups = append(snap.Groups, root)
+
+	for i, g := range groups {
+		gs := g.snapshot(now)
+		gs.Key = keys[i]
+		snap.Groups = append(snap.Groups, gs)
+	}
+
+	data, err := c.codec.Marshal(&snap)
+	if err != nil {
+		return err
+	}
+
+	if c.snapshotKey != nil {
+		if data, err = sealSnapshot(c.snapshotKey, data); err != nil {
+			return err
+		}
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *cache) LoadFrom(r io.Reader) error {
+	if c.closed() {
+		return ErrCacheClosed
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if c.snapshotKey != nil {
+		if data, err = openSnapshot(c.snapshotKey, data); err != nil {
+			return err
+		}
+	}
+
+	var snap snapshot
+	if err := c.codec.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	for _, gs := range snap.Groups {
+		g := c.group
+		if !gs.Root {
+			c.mx.Lock()
+			var ok bool
+			if g, ok = c.groups[gs.Key]; !ok {
+				g = newGroup(c, gs.Key, WithExpiration(gs.Expiration))
+				c.groups[gs.Key] = g
+			}
+			c.mx.Unlock()
+
+			if !ok {
+				c.groupEvent(EventGroupCreated, gs.Key)
+				c.evictGroups()
+			}
+		}
+
+		g.rebind(gs.FillFunc)
+		g.restore(gs, now)
+	}
+
+	return nil
+}
+
+// snapshot returns unexpired values of group
+func (g *group) snapshot(now time.Time) groupSnapshot {
+	g.mx.RLock()
+	gs := groupSnapshot{
+		Expiration: g.expiration,
+		FillFunc:   g.fillName,
+	}
+	g.mx.RUnlock()
+
+	g.each(now, func(key string, v value) {
+		var ttl time.Duration
+		if v.expiration != 0 {
+			ttl = time.Duration(v.expiration - now.UnixNano())
+		}
+
+		gs.Items = append(gs.Items, itemSnapshot{
+			Key:   key,
+			Value: v.val(),
+			TTL:   ttl,
+		})
+	})
+
+	return gs
+}
+
+// rebind sets filling function registered with specified name,
+// if group has no filling function and the name is registered
+func (g *group) rebind(name string) {
+	fillFunc, ok := namedFillFunc(name)
+	if !ok {
+		return
+	}
+
+	g.mx.Lock()
+	if g.fillFunc == nil {
+		g.fillFunc, g.fillName = fillFunc, name
+	}
+	g.mx.Unlock()
+}
+
+// restore puts values of snapshot into group
+func (g *group) restore(gs groupSnapshot, now time.Time) {
+	for _, item := range gs.Items {
+		s := g.shard(item.Key)
+		s.mx.Lock()
+		s.store(item.Key, item.Value, now, item.TTL)
+		s.unlock()
+	}
+}
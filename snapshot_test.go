@@ -0,0 +1,49 @@
+package gache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	src := NewCache()
+	src.Set("a", "root")
+	src.SetWithTTL("b", "expiring", time.Hour)
+	src.SetWithTTL("c", "expired", time.Nanosecond)
+	src.NewGroup("g", WithExpiration(time.Minute))
+	src.SetGroupVal("g", "x", "grouped")
+
+	var buf bytes.Buffer
+	if err := src.SaveTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := NewCache()
+	if err := dst.LoadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := dst.Get("a"); !ok || v != "root" {
+		t.Fatalf("expected root value, got %v, %v", v, ok)
+	}
+	if ttl, ok := dst.TTL("b"); !ok || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Fatalf("expected remaining TTL to be restored, got %v, %v", ttl, ok)
+	}
+	if _, ok := dst.Get("c"); ok {
+		t.Fatal("expected expired value not to be saved")
+	}
+	if v, err := dst.GetGroupVal("g", "x"); err != nil || v != "grouped" {
+		t.Fatalf("expected group value, got %v, %v", v, err)
+	}
+	if ttl, ok := dst.(*cache).groups["g"].TTL("x"); !ok || ttl > time.Minute {
+		t.Fatalf("expected group value to expire with group, got %v, %v", ttl, ok)
+	}
+}
+
+func TestLoadFromInvalid(t *testing.T) {
+	c := NewCache()
+	if err := c.LoadFrom(bytes.NewBufferString("garbage")); err == nil {
+		t.Fatal("expected error for malformed snapshot")
+	}
+}
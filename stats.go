@@ -0,0 +1,117 @@
+package gache
+
+import "sync/atomic"
+
+// Stats presents statistics of cache or group
+type Stats struct {
+	// Hits is number of Get calls served from group
+	Hits uint64
+	// Misses is number of Get calls, which didn't find
+	// unexpired value in group
+	Misses uint64
+	// Fills is number of filling function invocations
+	Fills uint64
+	// FillFailures is number of filling function invocations,
+	// which didn't return value
+	FillFailures uint64
+	// Evictions is number of values evicted on overflow
+	Evictions uint64
+	// Expirations is number of values removed on expiration
+	Expirations uint64
+	// Rejections is number of new values,
+	// rejected by admission filter
+	Rejections uint64
+	// Items is current number of unexpired values like Len.
+	// Expired values, which aren't removed yet, and cached
+	// absences of values aren't counted
+	Items int
+	// Cost is current total cost of values, including
+	// expired ones, which aren't removed yet
+	Cost int64
+	// KeyBytesSaved is number of bytes, which are saved by sharing
+	// storage of identical keys of values. It is reported only
+	// for whole cache with key interning
+	KeyBytesSaved int64
+}
+
+// add accumulates statistics of other into s
+func (s *Stats) add(other Stats) {
+	s.Hits += other.Hits
+	s.Misses += other.Misses
+	s.Fills += other.Fills
+	s.FillFailures += other.FillFailures
+	s.Evictions += other.Evictions
+	s.Expirations += other.Expirations
+	s.Rejections += other.Rejections
+	s.Items += other.Items
+	s.Cost += other.Cost
+}
+
+// counters holds group statistics,
+// which are updated atomically
+type counters struct {
+	hits         uint64
+	misses       uint64
+	fills        uint64
+	fillFailures uint64
+	evictions    uint64
+	expirations  uint64
+	rejections   uint64
+}
+
+func (g *group) Stats() Stats {
+	var (
+		items int
+		cost  int64
+	)
+	now := g.now().UnixNano()
+	for _, s := range g.shards {
+		s.mx.RLock()
+		for _, v := range s.values {
+			if !v.expired(now) && !v.absent() {
+				items++
+			}
+		}
+		cost += s.cost
+		s.mx.RUnlock()
+	}
+
+	return Stats{
+		Hits:         atomic.LoadUint64(&g.stats.hits),
+		Misses:       atomic.LoadUint64(&g.stats.misses),
+		Fills:        atomic.LoadUint64(&g.stats.fills),
+		FillFailures: atomic.LoadUint64(&g.stats.fillFailures),
+		Evictions:    atomic.LoadUint64(&g.stats.evictions),
+		Expirations:  atomic.LoadUint64(&g.stats.expirations),
+		Rejections:   atomic.LoadUint64(&g.stats.rejections),
+		Items:        items,
+		Cost:         cost,
+	}
+}
+
+func (c *cache) Stats() Stats {
+	var stats Stats
+	for _, g := range c.allGroups() {
+		stats.add(g.Stats())
+	}
+	stats.KeyBytesSaved = c.interner.saved()
+
+	return stats
+}
+
+func (c *cache) GroupStats() map[string]Stats {
+	c.mx.RLock()
+	groups := make(map[string]*group, len(c.groups))
+	for key, g := range c.groups {
+		groups[key] = g
+	}
+	c.mx.RUnlock()
+
+	stats := make(map[string]Stats, len(groups)+1)
+	stats[""] = c.group.Stats()
+	for key, g := range groups {
+		stats[key] = g.Stats()
+	}
+
+	return stats
+}
@@ -0,0 +1,29 @@
+package gache
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		return key, key != "missing"
+	}))
+	if err := c.NewGroup("g", WithMaxEntries(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+	c.Get("missing")
+	c.SetGroupVal("g", "x", 1)
+	c.SetGroupVal("g", "y", 2)
+
+	want := Stats{Hits: 1, Misses: 2, Fills: 2, FillFailures: 1, Items: 2}
+	if got := c.(*cache).group.Stats(); got != want {
+		t.Fatalf("expected root group stats %+v, got %+v", want, got)
+	}
+
+	want.Evictions, want.Items = 1, 3
+	if got := c.Stats(); got != want {
+		t.Fatalf("expected cache stats %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,145 @@
+package gache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Store presents interface of second level storage,
+// e.g. Redis or Memcached, which backs in-memory cache.
+// Values are addressed by group and value keys,
+// root group of cache has empty key
+type Store interface {
+	// Get returns value with specified key from group
+	// and false if it is not found
+	Get(ctx context.Context, group, key string) (interface{}, bool, error)
+	// Set sets value with live duration for specified key
+	// in group. Zero ttl means value never expires
+	Set(ctx context.Context, group, key string, val interface{}, ttl time.Duration) error
+	// Del removes value with specified key from group
+	Del(ctx context.Context, group, key string) error
+}
+
+// BatchStore presents Store, which is able to get
+// multiple values at once. It is used by GetMulti
+type BatchStore interface {
+	Store
+	// GetMulti returns values with specified keys from group,
+	// which were found
+	GetMulti(ctx context.Context, group string, keys []string) (map[string]interface{}, error)
+}
+
+// NewTieredCache returns new cache object, backed by l2 store.
+// Values missing in memory are looked up in l2 before invoking
+// filling function, filled and set values are written to l2,
+// and deleted values are removed from it
+func NewTieredCache(l2 Store, opts ...Option) Cache {
+	return NewCache(append(opts[:len(opts):len(opts)], WithStore(l2))...)
+}
+
+// load returns value with specified key from store of cache
+func (g *group) load(ctx context.Context, key string) (interface{}, bool) {
+	val, ok, err := g.cache.store.Get(ctx, g.key, key)
+	if err != nil {
+		g.cache.storeError(err)
+		return nil, false
+	}
+
+	return val, ok
+}
+
+// loadMulti looks up values with specified keys in batch store,
+// puts found ones into group and vals and returns keys of missing ones.
+// Frozen group is not loaded, so all keys are returned as missing
+func (g *group) loadMulti(bs BatchStore, keys []string, vals map[string]interface{}) []string {
+	if g.frozen() {
+		return keys
+	}
+
+	loaded, err := bs.GetMulti(context.Background(), g.key, keys)
+	if err != nil {
+		g.cache.storeError(err)
+		return keys
+	}
+
+	if len(loaded) == 0 {
+		return keys
+	}
+
+	atomic.AddUint64(&g.stats.misses, uint64(len(loaded)))
+	now, expiration := g.now(), g.getExpiration()
+
+	missed := keys[:0]
+	for _, key := range keys {
+		val, ok := loaded[key]
+		if !ok {
+			missed = append(missed, key)
+			continue
+		}
+
+		s := g.shard(key)
+		s.mx.Lock()
+		s.store(key, val, now, expiration)
+		s.unlock()
+
+		vals[key] = g.copyOut(val)
+	}
+
+	return missed
+}
+
+// persist writes value to store of cache, if it is set.
+// For group with write-behind policy write is queued
+func (g *group) persist(key string, val interface{}, ttl time.Duration) {
+	g.cache.logSet(g.key, key, val, ttl)
+
+	if g.cache.store == nil {
+		return
+	}
+
+	if g.writer != nil {
+		g.enqueue(writeOp{key: key, val: val, ttl: ttl})
+		return
+	}
+
+	g.save(key, val, ttl)
+}
+
+// unpersist removes value from store of cache, if it is set.
+// For group with write-behind policy removal is queued
+func (g *group) unpersist(key string) {
+	g.cache.logWrite(walRecord{Op: walDel, Group: g.key, Key: key})
+
+	if g.cache.store == nil {
+		return
+	}
+
+	if g.writer != nil {
+		g.enqueue(writeOp{key: key, del: true})
+		return
+	}
+
+	g.erase(key)
+}
+
+// save writes value to store of cache
+func (g *group) save(key string, val interface{}, ttl time.Duration) {
+	if err := g.cache.store.Set(context.Background(), g.key, key, val, ttl); err != nil {
+		g.cache.storeError(err)
+	}
+}
+
+// erase removes value from store of cache
+func (g *group) erase(key string) {
+	if err := g.cache.store.Del(context.Background(), g.key, key); err != nil {
+		g.cache.storeError(err)
+	}
+}
+
+// storeError passes error of store to handler of cache, if it is set
+func (c *cache) storeError(err error) {
+	if c.storeErrorHandler != nil {
+		c.storeErrorHandler(err)
+	}
+}
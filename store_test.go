@@ -0,0 +1,197 @@
+package gache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapStore presents Store, which keeps values in memory
+// by group and value keys, joined with slash
+type mapStore struct {
+	mx     sync.Mutex
+	vals   map[string]interface{}
+	ttls   map[string]time.Duration
+	writes int
+	err    error
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{
+		vals: make(map[string]interface{}),
+		ttls: make(map[string]time.Duration),
+	}
+}
+
+func (s *mapStore) Get(_ context.Context, group, key string) (interface{}, bool, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	val, ok := s.vals[group+"/"+key]
+	return val, ok, s.err
+}
+
+func (s *mapStore) Set(_ context.Context, group, key string, val interface{}, ttl time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.vals[group+"/"+key] = val
+	s.ttls[group+"/"+key] = ttl
+	s.writes++
+	return s.err
+}
+
+func (s *mapStore) Del(_ context.Context, group, key string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	delete(s.vals, group+"/"+key)
+	delete(s.ttls, group+"/"+key)
+	s.writes++
+	return s.err
+}
+
+// writeCount returns number of performed writes and deletions
+func (s *mapStore) writeCount() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return s.writes
+}
+
+// value returns value with specified key, joined with its group
+func (s *mapStore) value(key string) (interface{}, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	val, ok := s.vals[key]
+	return val, ok
+}
+
+func TestTieredCache(t *testing.T) {
+	l2 := newMapStore()
+	l2.vals["g/stored"] = "from l2"
+
+	c := NewTieredCache(l2, WithExpiration(time.Minute))
+	c.NewGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled " + key, true
+	}))
+	g, _ := c.Group("g")
+
+	if v, ok := g.Get("stored"); !ok || v != "from l2" {
+		t.Fatalf("expected value from l2, got %v, %v", v, ok)
+	}
+	if v, ok := g.Get("a"); !ok || v != "filled a" {
+		t.Fatalf("expected filled value, got %v, %v", v, ok)
+	}
+	if v, ok := l2.value("g/a"); !ok || v != "filled a" {
+		t.Fatalf("expected filled value written to l2, got %v, %v", v, ok)
+	}
+
+	c.SetWithTTL("b", 2, time.Hour)
+	if v, ok := l2.value("/b"); !ok || v != 2 || l2.ttls["/b"] != time.Hour {
+		t.Fatalf("expected set value written to l2 with its TTL, got %v, %v", v, ok)
+	}
+
+	c.Del("b")
+	if _, ok := l2.value("/b"); ok {
+		t.Fatal("expected deleted value removed from l2")
+	}
+
+	// values missing in memory are loaded from l2 without fill function
+	l2.vals["/c"] = 3
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Fatalf("expected root value from l2, got %v, %v", v, ok)
+	}
+}
+
+func TestStoreErrorHandler(t *testing.T) {
+	l2 := newMapStore()
+	l2.err = errors.New("unavailable")
+
+	var errs []error
+	c := NewTieredCache(l2, WithStoreErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	c.Set("a", 1)
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected miss on failed l2 lookup")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected value in memory despite l2 error, got %v, %v", v, ok)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected errors of set and get, got %v", errs)
+	}
+}
+
+// batchMapStore presents mapStore, which supports batch lookups
+type batchMapStore struct {
+	*mapStore
+	batches int
+}
+
+func (s *batchMapStore) GetMulti(ctx context.Context, group string, keys []string) (map[string]interface{}, error) {
+	s.batches++
+
+	vals := make(map[string]interface{})
+	for _, key := range keys {
+		if val, ok, _ := s.Get(ctx, group, key); ok {
+			vals[key] = val
+		}
+	}
+
+	return vals, nil
+}
+
+func TestBatchStore(t *testing.T) {
+	l2 := &batchMapStore{mapStore: newMapStore()}
+	l2.vals["/a"] = 1
+	l2.vals["/b"] = 2
+
+	c := NewTieredCache(l2, WithFillFunc(func(key string) (interface{}, bool) {
+		return "filled " + key, true
+	}))
+
+	vals := c.GetMulti([]string{"a", "b", "c"})
+	if len(vals) != 3 || vals["a"] != 1 || vals["b"] != 2 || vals["c"] != "filled c" {
+		t.Fatalf("expected values from l2 and fill, got %v", vals)
+	}
+	if l2.batches != 1 {
+		t.Fatalf("expected single batch lookup, got %d", l2.batches)
+	}
+
+	c.GetMulti([]string{"a", "b"})
+	if l2.batches != 1 {
+		t.Fatal("expected loaded values to be kept in memory")
+	}
+}
+
+func TestBatchFillStore(t *testing.T) {
+	l2 := newMapStore()
+	c := NewTieredCache(l2,
+		WithExpiration(time.Minute),
+		WithMaxValueSize(4, SkipOversized),
+		WithBatchFillFunc(func(keys []string) map[string]interface{} {
+			return map[string]interface{}{"a": "v", "big": "oversized"}
+		}),
+	)
+	t.Cleanup(func() { c.Close() })
+
+	vals := c.GetMulti([]string{"a", "big", "missing"})
+	if len(vals) != 2 {
+		t.Fatalf("expected filled values, got %v", vals)
+	}
+
+	if val, ok := l2.value("/a"); !ok || val != "v" || l2.ttls["/a"] != time.Minute {
+		t.Fatalf("expected filled value written to store, got %v, %v", val, ok)
+	}
+	for _, key := range []string{"/big", "/missing"} {
+		if _, ok := l2.value(key); ok {
+			t.Fatalf("expected %s not written to store", key)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package gache
+
+import "strings"
+
+// GroupSeparator separates keys of parent group
+// and its subgroup in key of subgroup
+const GroupSeparator = "/"
+
+func (g *group) Subgroup(key string, opts ...GroupOption) Group {
+	if g != g.cache.group {
+		key = g.key + GroupSeparator + key
+	}
+
+	return g.cache.GetOrCreateGroup(key, opts...)
+}
+
+// isSubgroup reports whether group with specified key
+// is descendant of group with parent key
+func isSubgroup(key, parent string) bool {
+	return strings.HasPrefix(key, parent+GroupSeparator)
+}
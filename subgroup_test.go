@@ -0,0 +1,30 @@
+package gache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubgroup(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	a := c.Subgroup("a")
+	b := a.Subgroup("b")
+	b.Set("x", 1)
+	c.GetOrCreateGroup("ab")
+
+	if g, ok := c.Group("a/b"); !ok || g != b {
+		t.Fatal("expected subgroup accessible by its path")
+	}
+	if v, err := c.GetGroupVal("a/b", "x"); err != nil || v != 1 {
+		t.Fatalf("expected subgroup value, got %v, %v", v, err)
+	}
+
+	if err := c.DelGroup("a"); err != nil {
+		t.Fatal(err)
+	}
+	if groups := c.Groups(); !reflect.DeepEqual(groups, []string{"ab"}) {
+		t.Fatalf("expected group and its subgroups deleted, got %v", groups)
+	}
+}
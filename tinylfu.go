@@ -0,0 +1,154 @@
+package gache
+
+// tinyLFU presents admission filter, which estimates access
+// frequency of keys with count-min sketch, guarded by
+// doorkeeper bloom filter against one-hit keys.
+// Frequencies are halved periodically, so filter adapts
+// to changing workload
+type tinyLFU struct {
+	sketch  [sketchDepth][]uint8
+	door    []uint64
+	mask    uint64
+	samples int
+	window  int
+}
+
+const (
+	sketchDepth      = 4
+	maxSketchCounter = 15
+	// minLFUWidth keeps collisions rare for small shards
+	// and shards limited by cost only
+	minLFUWidth = 1024
+)
+
+// newTinyLFU returns filter, sized for shard of specified capacity
+func newTinyLFU(capacity int) *tinyLFU {
+	width := minLFUWidth
+	for width < capacity*4 {
+		width <<= 1
+	}
+
+	f := &tinyLFU{
+		door:   make([]uint64, width/64*8),
+		mask:   uint64(width - 1),
+		window: width * 10,
+	}
+	for i := range f.sketch {
+		f.sketch[i] = make([]uint8, width)
+	}
+
+	return f
+}
+
+// record registers access to key
+func (f *tinyLFU) record(key string) {
+	h := hashKey(key)
+
+	if f.doorkeep(h) {
+		for i := range f.sketch {
+			c := &f.sketch[i][f.index(h, i)]
+			if *c < maxSketchCounter {
+				*c++
+			}
+		}
+	}
+
+	f.samples++
+	if f.samples >= f.window {
+		f.reset()
+	}
+}
+
+// estimate returns estimated access frequency of key
+func (f *tinyLFU) estimate(key string) int {
+	h := hashKey(key)
+
+	freq := maxSketchCounter
+	for i := range f.sketch {
+		if c := int(f.sketch[i][f.index(h, i)]); c < freq {
+			freq = c
+		}
+	}
+
+	if f.seen(h) {
+		freq++
+	}
+
+	return freq
+}
+
+// doorkeep adds key hash to doorkeeper and reports
+// whether it has been there before
+func (f *tinyLFU) doorkeep(h uint64) bool {
+	seen := true
+	for i := 0; i < 2; i++ {
+		bit := f.doorBit(h, i)
+		if f.door[bit/64]&(1<<(bit%64)) == 0 {
+			seen = false
+			f.door[bit/64] |= 1 << (bit % 64)
+		}
+	}
+
+	return seen
+}
+
+// seen reports whether key hash is in doorkeeper
+func (f *tinyLFU) seen(h uint64) bool {
+	for i := 0; i < 2; i++ {
+		bit := f.doorBit(h, i)
+		if f.door[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reset halves all frequencies and clears doorkeeper
+func (f *tinyLFU) reset() {
+	for i := range f.sketch {
+		for j := range f.sketch[i] {
+			f.sketch[i][j] >>= 1
+		}
+	}
+
+	for i := range f.door {
+		f.door[i] = 0
+	}
+
+	f.samples = 0
+}
+
+func (f *tinyLFU) index(h uint64, row int) uint64 {
+	return (h + uint64(row)*(h>>32|1)) & f.mask
+}
+
+func (f *tinyLFU) doorBit(h uint64, i int) uint64 {
+	return (h>>16 + uint64(i)*(h>>40|1)) % uint64(len(f.door)*64)
+}
+
+// hashKey returns 64-bit FNV-1a hash of key
+func hashKey(key string) uint64 {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+
+	return h
+}
+
+// admit reports whether new value with specified key and cost
+// should be stored in shard, evicting least recently used value.
+// Must be called with locked mutex
+func (s *shard) admit(key string, cost int64) bool {
+	full := (s.maxEntries > 0 && len(s.values)+1 > s.maxEntries) ||
+		(s.maxCost > 0 && s.cost+cost > s.maxCost)
+	if !full || s.lru.Len() == 0 {
+		return true
+	}
+
+	victim := s.lru.Back().Value.(string)
+
+	return s.admission.estimate(key) > s.admission.estimate(victim)
+}
@@ -0,0 +1,52 @@
+package gache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestTinyLFUEstimate(t *testing.T) {
+	f := newTinyLFU(16)
+
+	for i := 0; i < 5; i++ {
+		f.record("hot")
+	}
+	f.record("cold")
+
+	if hot, cold := f.estimate("hot"), f.estimate("cold"); hot <= cold {
+		t.Fatalf("expected hot key estimated higher, got %d <= %d", hot, cold)
+	}
+	if n := f.estimate("unknown"); n != 0 {
+		t.Fatalf("expected zero estimate of unknown key, got %d", n)
+	}
+
+	f.reset()
+	if n := f.estimate("hot"); n != 2 {
+		t.Fatalf("expected halved estimate after reset, got %d", n)
+	}
+}
+
+func TestTinyLFUAdmission(t *testing.T) {
+	c := NewCache(WithMaxEntries(10), WithTinyLFU())
+
+	for i := 0; i < 10; i++ {
+		key := "hot" + strconv.Itoa(i)
+		c.Set(key, i)
+		for j := 0; j < 3; j++ {
+			c.Get(key)
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		c.Set("scan"+strconv.Itoa(i), i)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, ok := c.Get("hot" + strconv.Itoa(i)); !ok {
+			t.Fatalf("expected hot value %d to survive scan", i)
+		}
+	}
+	if n := c.Stats().Rejections; n == 0 {
+		t.Fatal("expected scanned values to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+// Package tracing provides OpenTelemetry instrumentation
+// of gache filling functions
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/kcasctiv/gache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanName is name of spans, started for fills
+const SpanName = "gache.fill"
+
+// WithTracer returns cache option, which makes every
+// invocation of filling function start span with tracer
+func WithTracer(tracer trace.Tracer) gache.Option {
+	return gache.WithFillWrapper(FillWrapper(tracer))
+}
+
+// FillWrapper returns fill wrapper, which starts span with tracer
+// for every invocation of filling function. Span has group key,
+// value key, fill duration and whether value was found as attributes.
+// Span context is passed to filling function
+func FillWrapper(tracer trace.Tracer) gache.FillWrapper {
+	return func(group string, next gache.FillFuncCtx) gache.FillFuncCtx {
+		return func(ctx context.Context, key string) (interface{}, bool) {
+			start := time.Now()
+			ctx, span := tracer.Start(ctx, SpanName,
+				trace.WithSpanKind(trace.SpanKindInternal),
+				trace.WithAttributes(
+					attribute.String("gache.group", group),
+					attribute.String("gache.key", key),
+				),
+			)
+			defer span.End()
+
+			val, ok := next(ctx, key)
+
+			span.SetAttributes(
+				attribute.Bool("gache.fill.found", ok),
+				attribute.Int64("gache.fill.duration_ms", time.Since(start).Milliseconds()),
+			)
+
+			return val, ok
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcasctiv/gache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	c := gache.NewCache(WithTracer(provider.Tracer("test")))
+	defer c.Close()
+
+	var traced bool
+	c.NewGroup("g", gache.WithFillFuncCtx(func(ctx context.Context, key string) (interface{}, bool) {
+		traced = trace.SpanContextFromContext(ctx).IsValid()
+		return nil, false
+	}))
+	c.GetGroupVal("g", "a")
+
+	if !traced {
+		t.Fatal("expected span context passed to filling function")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != SpanName {
+		t.Fatalf("expected single fill span, got %v", spans)
+	}
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["gache.group"].AsString() != "g" || attrs["gache.key"].AsString() != "a" || attrs["gache.fill.found"].AsBool() {
+		t.Fatalf("expected attributes of missed fill, got %v", attrs)
+	}
+}
@@ -0,0 +1,26 @@
+package gache
+
+func (g *group) Update(key string, fn func(old interface{}, exists bool) (interface{}, bool)) {
+	if g.frozen() {
+		return
+	}
+
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	v, ok := s.lookup(key, now.UnixNano())
+	ok = ok && !v.absent()
+
+	val, set := fn(v.val(), ok)
+	if !set || g.validate(key, val) != nil {
+		s.unlock()
+		return
+	}
+
+	s.store(key, val, now, expiration)
+	s.unlock()
+
+	g.persist(key, val, expiration)
+}
@@ -0,0 +1,40 @@
+package gache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestUpdate(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	appendItem := func(old interface{}, exists bool) (interface{}, bool) {
+		if !exists {
+			return []int{1}, true
+		}
+		items := old.([]int)
+		return append(items[:len(items):len(items)], len(items)+1), true
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Update("a", appendItem)
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := c.Get("a"); len(v.([]int)) != 100 {
+		t.Fatalf("expected 100 items of concurrent updates, got %d", len(v.([]int)))
+	}
+
+	c.Update("b", func(old interface{}, exists bool) (interface{}, bool) {
+		return nil, false
+	})
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected declined update not to set value")
+	}
+}
@@ -0,0 +1,112 @@
+package gache
+
+// Validator presents type of function, which checks value
+// before it is stored into group, and returns error
+// if value is malformed
+type Validator func(key string, val interface{}) error
+
+// InvalidValueFunc presents type of function, intended for
+// handling values rejected by validators of groups. It receives
+// error returned by validator, ErrValueTooLarge for value, which
+// exceeds maximal value size of group, or error wrapping
+// ErrCorruptValue with nil value for stored value, which can't
+// be read back. Root group of cache has empty key
+type InvalidValueFunc func(group, key string, val interface{}, err error)
+
+func (c *cache) OnInvalidValue(f InvalidValueFunc) {
+	c.onInvalidValue.Store(f)
+}
+
+// OversizeMode presents way of handling values,
+// which exceed maximal value size of group
+type OversizeMode int
+
+const (
+	// RejectOversized makes group reject oversized values
+	// as invalid ones. It is default mode
+	RejectOversized OversizeMode = iota
+	// SkipOversized makes group silently skip caching of oversized
+	// values: they aren't stored, but filled ones are still returned
+	SkipOversized
+)
+
+// validate checks value with specified key by maximal value size
+// and validator of group. Rejected value is passed to
+// InvalidValueFunc of cache, unless it's oversized one skipped
+// by group. ErrValueTooLarge is returned for oversized value
+func (g *group) validate(key string, val interface{}) error {
+	if g.validator == nil && g.maxValueSize == 0 {
+		return nil
+	}
+
+	if g.maxValueSize > 0 && g.valueSize(val) > g.maxValueSize {
+		if g.skipOversized {
+			return ErrValueTooLarge
+		}
+		g.reject(key, val, ErrValueTooLarge)
+		return ErrValueTooLarge
+	}
+
+	if g.validator == nil {
+		return nil
+	}
+
+	err := g.validator(key, val)
+	if err != nil {
+		g.reject(key, val, err)
+	}
+
+	return err
+}
+
+// accept checks filled value of call for key by validate. Rejected
+// value makes call fail, while oversized value skipped by group is
+// returned to callers without storing
+func (g *group) accept(key string, c *call) {
+	if !c.ok {
+		return
+	}
+
+	switch err := g.validate(key, c.data); {
+	case err == nil:
+	case err == ErrValueTooLarge && g.skipOversized:
+		c.uncached = true
+	default:
+		c.data, c.ok, c.err = nil, false, err
+	}
+}
+
+// valueSize returns size of val in bytes, computed by Coster
+// of group, if it is set, or by length of its encoding by codec
+// of group or cache. Size of value, which can't be encoded,
+// is estimated by traversing it
+func (g *group) valueSize(val interface{}) int64 {
+	if g.coster != nil {
+		return g.coster(val)
+	}
+
+	switch v := val.(type) {
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	}
+
+	codec := g.codec
+	if codec == nil {
+		codec = g.cache.codec
+	}
+
+	if data, err := codec.Marshal(val); err == nil {
+		return int64(len(data))
+	}
+
+	return sizeOf(val)
+}
+
+// reject passes value rejected with err to InvalidValueFunc of cache
+func (g *group) reject(key string, val interface{}, err error) {
+	if f, _ := g.cache.onInvalidValue.Load().(InvalidValueFunc); f != nil {
+		f(g.key, key, val, err)
+	}
+}
@@ -0,0 +1,91 @@
+package gache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	errNegative := errors.New("negative value")
+
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	var rejected []string
+	c.OnInvalidValue(func(group, key string, val interface{}, err error) {
+		if group != "g" || !errors.Is(err, errNegative) {
+			t.Errorf("unexpected rejection of %s in %q: %v", key, group, err)
+		}
+		rejected = append(rejected, key)
+	})
+	c.NewGroup("g", WithValidator(func(key string, val interface{}) error {
+		if val.(int) < 0 {
+			return errNegative
+		}
+		return nil
+	}), WithFillFunc(func(key string) (interface{}, bool) {
+		return -1, true
+	}))
+	g, _ := c.Group("g")
+
+	g.Set("a", 1)
+	g.Set("b", -1)
+	if _, ok := g.Peek("b"); ok {
+		t.Fatal("expected rejected value not stored")
+	}
+	if err := g.Add("c", -1); !errors.Is(err, errNegative) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	g.SetMulti(map[string]interface{}{"d": 1, "e": -1})
+	if _, ok := g.Peek("e"); ok {
+		t.Fatal("expected rejected value of batch not stored")
+	}
+	if v, ok := g.Get("f"); ok {
+		t.Fatalf("expected rejected filled value treated as miss, got %v", v)
+	}
+
+	if len(rejected) != 4 {
+		t.Fatalf("expected 4 rejected values, got %v", rejected)
+	}
+	if n := g.Len(); n != 2 {
+		t.Fatalf("expected 2 valid values stored, got %d", n)
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+
+	var rejected int
+	c.OnInvalidValue(func(group, key string, val interface{}, err error) {
+		if !errors.Is(err, ErrValueTooLarge) {
+			t.Errorf("expected ErrValueTooLarge, got %v", err)
+		}
+		rejected++
+	})
+
+	c.NewGroup("reject", WithMaxValueSize(4))
+	g, _ := c.Group("reject")
+	g.Set("a", "abcd")
+	g.Set("b", "abcde")
+	if _, ok := g.Peek("b"); ok || rejected != 1 {
+		t.Fatalf("expected oversized value rejected, got %d rejections", rejected)
+	}
+	if err := g.Add("c", []byte("abcde")); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("expected ErrValueTooLarge, got %v", err)
+	}
+
+	c.NewGroup("skip", WithMaxValueSize(4, SkipOversized), WithFillFunc(func(key string) (interface{}, bool) {
+		return "abcde", true
+	}))
+	s, _ := c.Group("skip")
+	if v, ok := s.Get("a"); !ok || v != "abcde" {
+		t.Fatalf("expected oversized filled value returned, got %v, %v", v, ok)
+	}
+	if _, ok := s.Peek("a"); ok {
+		t.Fatal("expected oversized filled value not cached")
+	}
+	if rejected != 2 {
+		t.Fatalf("expected skipped values not reported, got %d rejections", rejected)
+	}
+}
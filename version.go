@@ -0,0 +1,40 @@
+package gache
+
+import "context"
+
+func (g *group) GetWithVersion(key string) (interface{}, uint64, bool) {
+	v, ok := g.get(context.Background(), key)
+	if !ok {
+		return nil, 0, false
+	}
+
+	return g.copyOut(v.val()), v.version, true
+}
+
+func (g *group) SetIfVersion(key string, val interface{}, version uint64) bool {
+	if g.frozen() {
+		return false
+	}
+
+	if g.validate(key, val) != nil {
+		return false
+	}
+
+	now := g.now()
+	expiration := g.getExpiration()
+
+	s := g.shard(key)
+	s.mx.Lock()
+	v, ok := s.lookup(key, now.UnixNano())
+	if !ok || v.absent() || v.version != version {
+		s.unlock()
+		return false
+	}
+
+	s.store(key, val, now, expiration)
+	s.unlock()
+
+	g.persist(key, val, expiration)
+
+	return true
+}
@@ -0,0 +1,40 @@
+package gache
+
+import "testing"
+
+func TestSetIfVersion(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+
+	if _, _, ok := c.GetWithVersion("a"); ok {
+		t.Fatal("expected missing value")
+	}
+	if c.SetIfVersion("a", 1, 0) {
+		t.Fatal("expected absent value not to be set")
+	}
+
+	c.Set("a", 1)
+	_, version, ok := c.GetWithVersion("a")
+	if !ok {
+		t.Fatal("expected versioned value")
+	}
+
+	if !c.SetIfVersion("a", 2, version) {
+		t.Fatal("expected value of matching version to be set")
+	}
+	if c.SetIfVersion("a", 3, version) {
+		t.Fatal("expected stale version to be rejected")
+	}
+
+	v, newVersion, _ := c.GetWithVersion("a")
+	if v != 2 || newVersion == version {
+		t.Fatalf("expected new value and version, got %v, %d", v, newVersion)
+	}
+
+	c.Increment("b", 1)
+	_, version, _ = c.GetWithVersion("b")
+	c.Increment("b", 1)
+	if c.SetIfVersion("b", 0, version) {
+		t.Fatal("expected increment to change version")
+	}
+}
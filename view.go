@@ -0,0 +1,143 @@
+package gache
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// GroupView presents immutable point-in-time view of group values.
+// View doesn't hold locks of group and isn't affected by its
+// later changes, so it may be iterated, serialized or compared
+// with another view at any time. View is safe for concurrent use
+type GroupView struct {
+	group  string
+	taken  time.Time
+	items  map[string]Item
+	cloner Cloner
+}
+
+func (g *group) Snapshot() GroupView {
+	now := g.now()
+
+	for _, s := range g.shards {
+		s.mx.RLock()
+	}
+
+	items := make(map[string]Item)
+	for _, s := range g.shards {
+		for key, v := range s.values {
+			if v.expired(now.UnixNano()) || v.absent() {
+				continue
+			}
+
+			data, ok := g.read(key, v)
+			if !ok {
+				continue
+			}
+
+			item := Item{
+				Value:       g.copyOut(data),
+				Created:     time.Unix(0, v.created),
+				AccessCount: atomic.LoadUint64(&v.access.count),
+			}
+			if v.expiration != 0 {
+				item.Expiration = time.Unix(0, v.expiration)
+			}
+			if last := atomic.LoadInt64(&v.access.last); last != 0 {
+				item.LastAccess = time.Unix(0, last)
+			}
+
+			items[key] = item
+		}
+	}
+
+	for _, s := range g.shards {
+		s.mx.RUnlock()
+	}
+
+	return GroupView{group: g.key, taken: now, items: items, cloner: g.cloner}
+}
+
+// Group returns key of group, which view was taken of
+func (v GroupView) Group() string {
+	return v.group
+}
+
+// Taken returns time, when view was taken
+func (v GroupView) Taken() time.Time {
+	return v.taken
+}
+
+// Len returns number of values in view
+func (v GroupView) Len() int {
+	return len(v.items)
+}
+
+// Keys returns sorted keys of values in view
+func (v GroupView) Keys() []string {
+	keys := make([]string, 0, len(v.items))
+	for key := range v.items {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Get returns value with specified key and true,
+// if view contains it, otherwise nil and false
+func (v GroupView) Get(key string) (interface{}, bool) {
+	it, ok := v.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	return v.copyOut(it.Value), true
+}
+
+// GetItem returns value with specified key and its metadata
+// at the time view was taken, if view contains it
+func (v GroupView) GetItem(key string) (Item, bool) {
+	item, ok := v.items[key]
+	if !ok {
+		return Item{}, false
+	}
+
+	item.Value = v.copyOut(item.Value)
+
+	return item, true
+}
+
+// Range calls f for values of view in order of their keys.
+// If f returns false, range stops
+func (v GroupView) Range(f func(key string, val interface{}) bool) {
+	for _, key := range v.Keys() {
+		if !f(key, v.copyOut(v.items[key].Value)) {
+			return
+		}
+	}
+}
+
+// Items returns values of view with their metadata by keys.
+// Returned map may be modified and serialized by caller
+func (v GroupView) Items() map[string]Item {
+	items := make(map[string]Item, len(v.items))
+	for key, item := range v.items {
+		item.Value = v.copyOut(item.Value)
+		items[key] = item
+	}
+
+	return items
+}
+
+// copyOut returns copy of val, if group of view copies values on read,
+// so values captured by view can't be changed by callers
+func (v GroupView) copyOut(val interface{}) interface{} {
+	if v.cloner == nil || val == nil {
+		return val
+	}
+
+	return v.cloner(val)
+}
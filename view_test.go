@@ -0,0 +1,66 @@
+package gache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotView(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock))
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithShards(4))
+	g, _ := c.Group("g")
+
+	g.Set("b", 2)
+	g.Set("a", 1)
+	g.SetWithTTL("x", 0, time.Second)
+	clock.Advance(2 * time.Second)
+
+	v := g.Snapshot()
+	g.Set("c", 3)
+	g.Del("a")
+
+	if v.Group() != "g" || !v.Taken().Equal(clock.Now()) {
+		t.Fatalf("unexpected view of %q taken at %v", v.Group(), v.Taken())
+	}
+	if keys := v.Keys(); v.Len() != 2 || !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Fatalf("expected unexpired values at the time view was taken, got %v", keys)
+	}
+	if val, ok := v.Get("a"); !ok || val != 1 {
+		t.Fatalf("expected value deleted later, got %v, %v", val, ok)
+	}
+	if item, ok := v.GetItem("b"); !ok || item.Value != 2 || !item.Created.Equal(time.Unix(1000, 0)) {
+		t.Fatalf("expected item with metadata, got %+v, %v", item, ok)
+	}
+
+	var keys []string
+	v.Range(func(key string, val interface{}) bool {
+		keys = append(keys, key)
+		return false
+	})
+	if !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Fatalf("expected range stopped after first key, got %v", keys)
+	}
+
+	if items := v.Items(); len(items) != 2 || items["b"].Value != 2 {
+		t.Fatalf("expected items of view, got %v", items)
+	}
+}
+
+func TestSnapshotViewCopyOnRead(t *testing.T) {
+	c := NewCache()
+	t.Cleanup(func() { c.Close() })
+	c.NewGroup("g", WithCopyOnRead(nil))
+	g, _ := c.Group("g")
+
+	g.Set("a", []int{1})
+	v := g.Snapshot()
+
+	val, _ := v.Get("a")
+	val.([]int)[0] = 2
+	if val, _ := v.Get("a"); val.([]int)[0] != 1 {
+		t.Fatalf("expected value of view not modified, got %v", val)
+	}
+}
@@ -0,0 +1,390 @@
+package gache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRecordSize limits size of record of write log,
+	// larger size is treated as corruption of log
+	maxRecordSize = 256 << 20
+	// maxRecordPrealloc limits memory allocated for record
+	// before it is read, so corrupted size doesn't cause
+	// allocation of memory, which log doesn't contain
+	maxRecordPrealloc = 64 << 10
+)
+
+// errRecordSize is returned by readRecord
+// for record, which exceeds maxRecordSize
+var errRecordSize = errors.New("gache: write log record is too large")
+
+// walOp presents kind of operation recorded in write log
+type walOp uint8
+
+const (
+	walSet walOp = iota + 1
+	walDel
+	walDelGroup
+	walFlush
+	walExpiration
+	walRename
+	walClone
+	walTouch
+)
+
+// walRecord presents operation recorded in write log
+type walRecord struct {
+	Op    walOp
+	Group string
+	Key   string
+	Value interface{}
+	// Expires is time of value expiration in nanoseconds
+	// since Unix epoch, zero means value never expires
+	Expires      int64
+	TTL          time.Duration
+	Mode         ExpirationMode
+	DeleteGroups bool
+	// Target is key of group, which group is renamed or cloned to
+	Target string
+}
+
+// wal presents write log of cache. Log is written to current
+// segment, which is sealed on compaction, and records of sealed
+// segment are dropped, when snapshot of cache is saved
+type wal struct {
+	path    string
+	maxSize int64
+	mx      sync.Mutex
+	f       *os.File
+	size    int64
+	// compact signals compaction of log, done
+	// is closed, when compaction is stopped
+	compact chan struct{}
+	done    chan struct{}
+}
+
+// sealedPath returns path of sealed segment of log
+func (w *wal) sealedPath() string {
+	return w.path + ".sealed"
+}
+
+// logSet records write of value with specified live duration
+func (c *cache) logSet(group, key string, val interface{}, ttl time.Duration) {
+	if c.wal == nil {
+		return
+	}
+
+	var expires int64
+	if ttl > 0 {
+		expires = c.clock.Now().Add(ttl).UnixNano()
+	}
+
+	c.logWrite(walRecord{Op: walSet, Group: group, Key: key, Value: val, Expires: expires})
+}
+
+// logTouch records change of value expiration to specified
+// time in nanoseconds since Unix epoch, zero means never
+func (c *cache) logTouch(group, key string, expires int64) {
+	c.logWrite(walRecord{Op: walTouch, Group: group, Key: key, Expires: expires})
+}
+
+// logWrite appends record to write log of cache, if it is set
+func (c *cache) logWrite(rec walRecord) {
+	if c.wal == nil {
+		return
+	}
+
+	if err := c.wal.append(c, rec); err != nil {
+		c.snapshotError(err)
+	}
+}
+
+// append writes record, prefixed with its size, to log and
+// signals compaction of log, if it grows beyond its limit.
+// Records are ignored, until log is replayed, and after it is closed
+func (w *wal) append(c *cache, rec walRecord) error {
+	data, err := c.codec.Marshal(&rec)
+	if err != nil {
+		return err
+	}
+
+	if c.snapshotKey != nil {
+		if data, err = sealSnapshot(c.snapshotKey, data); err != nil {
+			return err
+		}
+	}
+
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	n, err := w.f.Write(frame)
+	w.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		select {
+		case w.compact <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// compactWAL compacts write log, when it is signaled, until cache
+// is closed, so writes aren't blocked by saving of snapshot
+func (c *cache) compactWAL() {
+	w := c.wal
+	defer close(w.done)
+
+	for {
+		select {
+		case <-w.compact:
+			if err := w.compactTo(c); err != nil {
+				c.snapshotError(err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// compactTo seals current segment of log, writes snapshot of
+// cache and removes sealed segment, as its records are in snapshot.
+// Records written during saving of snapshot stay in new segment
+func (w *wal) compactTo(c *cache) error {
+	w.mx.Lock()
+	err := w.rotate()
+	w.mx.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.saveSnapshot(w.path + ".snap"); err != nil {
+		return err
+	}
+
+	if err := os.Remove(w.sealedPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// rotate seals current segment of log and starts new one.
+// Segment isn't sealed, if previously sealed segment is still
+// present, as its records are dropped by next snapshot anyway.
+// Must be called with locked mutex
+func (w *wal) rotate() error {
+	if w.f == nil {
+		return nil
+	}
+
+	if _, err := os.Stat(w.sealedPath()); err == nil {
+		return nil
+	}
+
+	if err := os.Rename(w.path, w.sealedPath()); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		// keep writing to current segment
+		os.Rename(w.sealedPath(), w.path)
+		return err
+	}
+
+	w.f.Close()
+	w.f, w.size = f, 0
+
+	return nil
+}
+
+// close stops compaction and closes log file,
+// so further records are ignored
+func (w *wal) close() error {
+	if w.done != nil {
+		<-w.done
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.f == nil {
+		return nil
+	}
+
+	err := w.f.Close()
+	w.f = nil
+
+	return err
+}
+
+// replayWAL loads snapshot of write log and applies records of
+// sealed and current segments of log to cache. Partially written
+// record at the end of current segment is dropped
+func (c *cache) replayWAL() error {
+	w := c.wal
+	if err := c.loadSnapshot(w.path + ".snap"); err != nil {
+		return err
+	}
+
+	// sealed segment is left, if cache was stopped during compaction
+	if f, err := os.Open(w.sealedPath()); err == nil {
+		c.replaySegment(f)
+		f.Close()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	size := c.replaySegment(f)
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return err
+	}
+
+	w.mx.Lock()
+	w.f, w.size = f, size
+	w.mx.Unlock()
+
+	if w.maxSize > 0 {
+		w.compact = make(chan struct{}, 1)
+		w.done = make(chan struct{})
+		go c.compactWAL()
+	}
+
+	return nil
+}
+
+// replaySegment applies records of segment of log to cache
+// and returns size of records, which are read completely
+func (c *cache) replaySegment(f *os.File) int64 {
+	var size int64
+	r := bufio.NewReader(f)
+	for {
+		rec, n, err := c.readRecord(r)
+		if err != nil {
+			return size
+		}
+
+		c.replay(rec)
+		size += n
+	}
+}
+
+// readRecord reads record of write log from r
+// and returns it with its size in log
+func (c *cache) readRecord(r io.Reader) (walRecord, int64, error) {
+	var rec walRecord
+
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return rec, 0, err
+	}
+
+	n := int64(binary.BigEndian.Uint32(header[:]))
+	if n > maxRecordSize {
+		return rec, 0, errRecordSize
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(min(n, maxRecordPrealloc)))
+	if _, err := io.CopyN(&buf, r, n); err != nil {
+		return rec, 0, err
+	}
+
+	data := buf.Bytes()
+	size := int64(len(header)) + n
+
+	var err error
+	if c.snapshotKey != nil {
+		if data, err = openSnapshot(c.snapshotKey, data); err != nil {
+			return rec, 0, err
+		}
+	}
+
+	if err := c.codec.Unmarshal(data, &rec); err != nil {
+		return rec, 0, err
+	}
+
+	return rec, size, nil
+}
+
+// replay applies record of write log to cache without
+// writing to store of cache or notifying other instances
+func (c *cache) replay(rec walRecord) {
+	switch rec.Op {
+	case walDelGroup:
+		c.delGroup(rec.Group)
+		return
+	case walFlush:
+		c.flush(rec.DeleteGroups)
+		return
+	case walRename:
+		c.RenameGroup(rec.Group, rec.Target)
+		return
+	case walClone:
+		c.CloneGroup(rec.Group, rec.Target)
+		return
+	}
+
+	g := c.group
+	if rec.Group != "" {
+		g = c.getOrCreateGroup(rec.Group)
+	}
+
+	now := c.clock.Now()
+	switch rec.Op {
+	case walSet:
+		var ttl time.Duration
+		if rec.Expires != 0 {
+			if ttl = time.Duration(rec.Expires - now.UnixNano()); ttl <= 0 {
+				g.remove(rec.Key)
+				return
+			}
+		}
+
+		s := g.shard(rec.Key)
+		s.mx.Lock()
+		s.store(rec.Key, rec.Value, now, ttl)
+		s.unlock()
+	case walDel:
+		g.remove(rec.Key)
+	case walTouch:
+		var ttl time.Duration
+		if rec.Expires != 0 {
+			if ttl = time.Duration(rec.Expires - now.UnixNano()); ttl <= 0 {
+				g.remove(rec.Key)
+				return
+			}
+		}
+
+		g.Touch(rec.Key, ttl)
+	case walExpiration:
+		g.SetExpiration(rec.TTL, rec.Mode)
+	}
+}
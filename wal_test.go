@@ -0,0 +1,262 @@
+package gache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWALReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Del("b")
+	g := c.GetOrCreateGroup("g")
+	g.SetWithTTL("x", "y", time.Hour)
+	c.GetOrCreateGroup("tmp").Set("z", 0)
+	c.DelGroup("tmp")
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewCache(WithWAL(path, 0), WithSnapshotErrorHandler(func(err error) { t.Error(err) }))
+	defer c.Close()
+
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected replayed value 1, got %v, %v", v, ok)
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected deleted value to stay deleted")
+	}
+	if _, ok := c.Group("tmp"); ok {
+		t.Fatal("expected deleted group to stay deleted")
+	}
+
+	g, ok := c.Group("g")
+	if !ok {
+		t.Fatal("expected replayed group")
+	}
+	if ttl, ok := g.TTL("x"); !ok || ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("expected remaining TTL of replayed value, got %v, %v", ttl, ok)
+	}
+}
+
+func TestWALRename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.GetOrCreateGroup("old").Set("a", 1)
+	c.GetOrCreateGroup("src").Set("b", 2)
+	if err := c.RenameGroup("old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CloneGroup("src", "dst"); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	defer c.Close()
+
+	if groups := c.Groups(); len(groups) != 3 || groups[0] != "dst" || groups[1] != "new" || groups[2] != "src" {
+		t.Fatalf("expected replayed rename and clone, got groups %v", groups)
+	}
+	if v, err := c.GetGroupVal("new", "a"); err != nil || v != 1 {
+		t.Fatalf("expected value of renamed group, got %v, %v", v, err)
+	}
+	if v, err := c.GetGroupVal("dst", "b"); err != nil || v != 2 {
+		t.Fatalf("expected value of cloned group, got %v, %v", v, err)
+	}
+}
+
+func TestWALClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	g := c.GetOrCreateGroup("g")
+	g.Set("a", 1)
+	g.Set("b", 2)
+	g.Clear()
+	g.Set("c", 3)
+	c.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	defer c.Close()
+
+	g, _ = c.Group("g")
+	if keys := g.Keys(); len(keys) != 1 || keys[0] != "c" {
+		t.Fatalf("expected only value stored after clear, got %v", keys)
+	}
+}
+
+func TestWALTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.Set("a", 1)
+	c.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// partially written frame
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 1})
+	f.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected value before torn tail, got %v, %v", v, ok)
+	}
+	c.Close()
+
+	if info2, _ := os.Stat(path); info2.Size() != info.Size() {
+		t.Fatalf("expected torn tail to be truncated to %d bytes, got %d", info.Size(), info2.Size())
+	}
+}
+
+func TestWALCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 256))
+	for i := 0; i < 50; i++ {
+		c.Set("k", i)
+	}
+
+	// compaction runs in background
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path + ".snap"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected compaction snapshot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Set("a", "b")
+	c.Close()
+
+	c = NewCache(WithWAL(path, 256))
+	defer c.Close()
+
+	if v, _ := c.Get("k"); v != 49 {
+		t.Fatalf("expected last written value 49, got %v", v)
+	}
+	if v, _ := c.Get("a"); v != "b" {
+		t.Fatalf("expected value b, got %v", v)
+	}
+}
+
+func TestWALSealedSegment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.Set("a", 1)
+	c.Set("b", 1)
+	c.Close()
+
+	// cache was stopped after sealing segment, before saving snapshot
+	if err := os.Rename(path, path+".sealed"); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewCache(WithWAL(path, 0))
+	c.Set("b", 2)
+	c.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	defer c.Close()
+
+	if v, _ := c.Get("a"); v != 1 {
+		t.Fatalf("expected value of sealed segment, got %v", v)
+	}
+	if v, _ := c.Get("b"); v != 2 {
+		t.Fatalf("expected value of current segment, got %v", v)
+	}
+}
+
+func TestWALOversizedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.Set("a", 1)
+	c.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0xff, 0xff, 0xff, 0xff, 1, 2, 3})
+	f.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected value before oversized record, got %v, %v", v, ok)
+	}
+	c.Close()
+
+	if info2, _ := os.Stat(path); info2.Size() != info.Size() {
+		t.Fatalf("expected oversized record to be truncated, got %d bytes", info2.Size())
+	}
+}
+
+func TestWALTouch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+
+	c := NewCache(WithWAL(path, 0))
+	c.SetWithTTL("a", 1, time.Minute)
+	c.Touch("a", time.Hour)
+	c.SetWithTTL("b", 1, time.Hour)
+	c.Touch("b", 0)
+	c.Close()
+
+	c = NewCache(WithWAL(path, 0))
+	defer c.Close()
+
+	if ttl, ok := c.TTL("a"); !ok || ttl <= time.Minute {
+		t.Fatalf("expected replayed touch, got %v, %v", ttl, ok)
+	}
+	if ttl, ok := c.TTL("b"); !ok || ttl != 0 {
+		t.Fatalf("expected value without expiration, got %v, %v", ttl, ok)
+	}
+}
+
+func TestWALEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.wal")
+	key := make([]byte, 32)
+
+	c := NewCache(WithWAL(path, 0), WithSnapshotEncryption(key))
+	c.Set("pii", "secret-value")
+	c.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || bytes.Contains(data, []byte("secret-value")) {
+		t.Fatal("expected encrypted log")
+	}
+
+	c = NewCache(WithWAL(path, 0), WithSnapshotEncryption(key))
+	defer c.Close()
+
+	if v, _ := c.Get("pii"); v != "secret-value" {
+		t.Fatalf("expected decrypted value, got %v", v)
+	}
+}
@@ -0,0 +1,83 @@
+package gache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// WarmOption presents type of function,
+// intended for configuring warming of group
+type WarmOption func(*warming)
+
+// WithWarmParallelism limits number of values, which are
+// loaded concurrently. Default is runtime.GOMAXPROCS(0)
+func WithWarmParallelism(n int) WarmOption {
+	return func(w *warming) {
+		if n < 1 {
+			n = 1
+		}
+		w.parallelism = n
+	}
+}
+
+// WithWarmProgress sets function, which is called after loading
+// of every value with its key, result of loading, number of
+// processed keys and number of all keys. Calls aren't concurrent
+func WithWarmProgress(progress func(key string, ok bool, done, total int)) WarmOption {
+	return func(w *warming) {
+		w.progress = progress
+	}
+}
+
+// warming presents settings of warming of group
+type warming struct {
+	parallelism int
+	progress    func(key string, ok bool, done, total int)
+}
+
+func (g *group) Warm(ctx context.Context, keys []string, opts ...WarmOption) error {
+	if g.frozen() {
+		return &GroupError{Group: g.key, Err: ErrGroupFrozen}
+	}
+
+	w := &warming{parallelism: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mx   sync.Mutex
+		done int
+	)
+
+	slots := make(chan struct{}, w.parallelism)
+	for _, key := range keys {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			_, ok := g.get(ctx, key)
+
+			if w.progress != nil {
+				mx.Lock()
+				done++
+				w.progress(key, ok, done, len(keys))
+				mx.Unlock()
+			}
+		}(key)
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
@@ -0,0 +1,61 @@
+package gache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarm(t *testing.T) {
+	var running, maxRunning int32
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		return key, key != "missing"
+	}))
+	defer c.Close()
+
+	keys := []string{"missing"}
+	for i := 0; i < 20; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+
+	var failed, last int
+	err := c.Warm(context.Background(), keys, WithWarmParallelism(2),
+		WithWarmProgress(func(key string, ok bool, done, total int) {
+			if !ok {
+				failed++
+			}
+			if total != len(keys) || done != last+1 {
+				t.Errorf("unexpected progress %d of %d", done, total)
+			}
+			last = done
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last != len(keys) || failed != 1 {
+		t.Fatalf("expected all keys processed with 1 failure, got %d, %d", last, failed)
+	}
+	if n := c.Len(); n != 20 {
+		t.Fatalf("expected 20 values loaded, got %d", n)
+	}
+	if n := atomic.LoadInt32(&maxRunning); n > 2 {
+		t.Fatalf("expected at most 2 concurrent loads, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Warm(ctx, []string{"x"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package gache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventType presents kind of change of cache
+type EventType int
+
+const (
+	// EventSet means value was stored or filled
+	EventSet EventType = iota
+	// EventDel means value was deleted explicitly
+	// or by deletion of its group
+	EventDel
+	// EventExpire means value was removed on expiration
+	EventExpire
+	// EventEvict means value was evicted by size limits of group
+	EventEvict
+	// EventFillFailed means value wasn't found or filled.
+	// Value of event is error, which caused failure,
+	// e.g. ErrFillTimeout, or nil, if value wasn't found
+	EventFillFailed
+	// EventGroupCreated means group was created.
+	// Key of event is empty
+	EventGroupCreated
+	// EventGroupDeleted means group was deleted.
+	// Key of event is empty
+	EventGroupDeleted
+)
+
+// Event presents change of cache. Value is set for events
+// of stored and removed values. Root group of cache has empty key
+type Event struct {
+	Type  EventType
+	Group string
+	Key   string
+	Value interface{}
+}
+
+// watchBuffer is capacity of channels, which deliver events.
+// Events for watchers, which fall behind, are dropped
+const watchBuffer = 64
+
+// watchScope presents set of events, which watcher receives
+type watchScope int
+
+const (
+	watchValue watchScope = iota
+	watchGroup
+	watchAll
+)
+
+// watcher presents subscriber of events of single value,
+// of whole group or of whole cache
+type watcher struct {
+	ch    chan Event
+	key   watchKey
+	scope watchScope
+}
+
+// watchKey presents key of value in cache
+type watchKey struct {
+	group string
+	key   string
+}
+
+// watchers holds subscribers of events of cache
+type watchers struct {
+	mx     sync.RWMutex
+	count  int32
+	keys   map[watchKey]map[*watcher]struct{}
+	groups map[string]map[*watcher]struct{}
+	all    map[*watcher]struct{}
+}
+
+// active reports whether anyone watches events,
+// so they should be collected
+func (w *watchers) active() bool {
+	return atomic.LoadInt32(&w.count) > 0
+}
+
+// watch registers watcher and returns function, which
+// unregisters it and closes its channel
+func (w *watchers) watch(wt *watcher) func() {
+	w.mx.Lock()
+	switch wt.scope {
+	case watchAll:
+		if w.all == nil {
+			w.all = make(map[*watcher]struct{})
+		}
+		w.all[wt] = struct{}{}
+	case watchGroup:
+		if w.groups == nil {
+			w.groups = make(map[string]map[*watcher]struct{})
+		}
+		if w.groups[wt.key.group] == nil {
+			w.groups[wt.key.group] = make(map[*watcher]struct{})
+		}
+		w.groups[wt.key.group][wt] = struct{}{}
+	default:
+		if w.keys == nil {
+			w.keys = make(map[watchKey]map[*watcher]struct{})
+		}
+		if w.keys[wt.key] == nil {
+			w.keys[wt.key] = make(map[*watcher]struct{})
+		}
+		w.keys[wt.key][wt] = struct{}{}
+	}
+	atomic.AddInt32(&w.count, 1)
+	w.mx.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mx.Lock()
+			switch wt.scope {
+			case watchAll:
+				delete(w.all, wt)
+			case watchGroup:
+				delete(w.groups[wt.key.group], wt)
+				if len(w.groups[wt.key.group]) == 0 {
+					delete(w.groups, wt.key.group)
+				}
+			default:
+				delete(w.keys[wt.key], wt)
+				if len(w.keys[wt.key]) == 0 {
+					delete(w.keys, wt.key)
+				}
+			}
+			atomic.AddInt32(&w.count, -1)
+			close(wt.ch)
+			w.mx.Unlock()
+		})
+	}
+}
+
+// notify delivers events to their watchers without blocking
+func (w *watchers) notify(events []Event) {
+	w.mx.RLock()
+	defer w.mx.RUnlock()
+
+	for _, e := range events {
+		if e.Type < EventGroupCreated {
+			for wt := range w.keys[watchKey{group: e.Group, key: e.Key}] {
+				wt.send(e)
+			}
+		}
+		for wt := range w.groups[e.Group] {
+			wt.send(e)
+		}
+		for wt := range w.all {
+			wt.send(e)
+		}
+	}
+}
+
+func (wt *watcher) send(e Event) {
+	select {
+	case wt.ch <- e:
+	default:
+	}
+}
+
+func (g *group) Watch(key string) (<-chan Event, func()) {
+	wt := &watcher{
+		ch:  make(chan Event, watchBuffer),
+		key: watchKey{group: g.key, key: key},
+	}
+
+	return wt.ch, g.cache.watchers.watch(wt)
+}
+
+func (g *group) WatchGroup() (<-chan Event, func()) {
+	wt := &watcher{
+		ch:    make(chan Event, watchBuffer),
+		key:   watchKey{group: g.key},
+		scope: watchGroup,
+	}
+
+	return wt.ch, g.cache.watchers.watch(wt)
+}
+
+func (c *cache) Subscribe(buffer int) (<-chan Event, func()) {
+	if buffer < 1 {
+		buffer = watchBuffer
+	}
+
+	wt := &watcher{
+		ch:    make(chan Event, buffer),
+		scope: watchAll,
+	}
+
+	return wt.ch, c.watchers.watch(wt)
+}
+
+// groupEvent delivers event of group with specified key
+// to watchers, if anyone watches events
+func (c *cache) groupEvent(typ EventType, key string) {
+	if c.watchers.active() {
+		c.watchers.notify([]Event{{Type: typ, Group: key}})
+	}
+	c.logEvents([]Event{{Type: typ, Group: key}})
+}
+
+// event records event of value with specified key, if anyone
+// watches events or they are logged. Events are delivered,
+// when mutex is unlocked.
+// Must be called with locked mutex
+func (s *shard) event(typ EventType, key string, data interface{}) {
+	if s.group.cache.watchers.active() || s.group.cache.logged(typ) {
+		s.events = append(s.events, Event{Type: typ, Group: s.group.key, Key: key, Value: data})
+	}
+}
@@ -0,0 +1,133 @@
+package gache
+
+import (
+	"testing"
+	"time"
+)
+
+// receive returns next event from ch or fails test
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("event wasn't delivered")
+		return Event{}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	clock := newTestClock()
+	c := NewCache(WithClock(clock), WithMaxEntries(1))
+	defer c.Close()
+
+	ch, stop := c.Watch("a")
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("a", 3)
+	c.SetWithTTL("a", 4, time.Second)
+	clock.Advance(time.Second)
+	c.Get("a")
+
+	expected := []Event{
+		{Type: EventSet, Key: "a", Value: 1},
+		{Type: EventEvict, Key: "a", Value: 1},
+		{Type: EventSet, Key: "a", Value: 3},
+		{Type: EventSet, Key: "a", Value: 4},
+		{Type: EventExpire, Key: "a", Value: 4},
+	}
+	for _, want := range expected {
+		if e := receive(t, ch); e != want {
+			t.Fatalf("expected %+v, got %+v", want, e)
+		}
+	}
+
+	stop()
+	stop()
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel closed after stop")
+	}
+}
+
+func TestWatchGroup(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+	c.NewGroup("g")
+	g, _ := c.Group("g")
+
+	ch, stop := g.WatchGroup()
+	defer stop()
+
+	c.Set("a", 1)
+	g.Set("a", 2)
+	g.Del("a")
+
+	if e := receive(t, ch); e != (Event{Type: EventSet, Group: "g", Key: "a", Value: 2}) {
+		t.Fatalf("expected set event of group value, got %+v", e)
+	}
+	if e := receive(t, ch); e != (Event{Type: EventDel, Group: "g", Key: "a", Value: 2}) {
+		t.Fatalf("expected delete event of group value, got %+v", e)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	c := NewCache(WithFillFunc(func(key string) (interface{}, bool) {
+		return nil, false
+	}))
+	defer c.Close()
+
+	ch, stop := c.Subscribe(0)
+	defer stop()
+
+	c.NewGroup("g")
+	c.SetGroupVal("g", "a", 1)
+	c.Get("missing")
+	c.DelGroup("g")
+
+	expected := []Event{
+		{Type: EventGroupCreated, Group: "g"},
+		{Type: EventSet, Group: "g", Key: "a", Value: 1},
+		{Type: EventFillFailed, Key: "missing"},
+		{Type: EventDel, Group: "g", Key: "a", Value: 1},
+		{Type: EventGroupDeleted, Group: "g"},
+	}
+	for _, want := range expected {
+		if e := receive(t, ch); e != want {
+			t.Fatalf("expected %+v, got %+v", want, e)
+		}
+	}
+}
+
+func TestFillFailedReasons(t *testing.T) {
+	c := NewCache()
+	defer c.Close()
+	c.NewGroup("g", WithMaxValueSize(1), WithFillTimeout(time.Hour), WithFillFunc(func(key string) (interface{}, bool) {
+		switch key {
+		case "panic":
+			panic("fill")
+		case "large":
+			return "value", true
+		}
+		return nil, false
+	}))
+	g, _ := c.Group("g")
+
+	ch, stop := g.WatchGroup()
+	defer stop()
+
+	for _, tc := range []struct {
+		key string
+		err error
+	}{
+		{"missing", nil},
+		{"panic", ErrFillPanic},
+		{"large", ErrValueTooLarge},
+	} {
+		g.Get(tc.key)
+		if e := receive(t, ch); e.Type != EventFillFailed || e.Key != tc.key || e.Value != tc.err {
+			t.Fatalf("expected failed fill of %s with %v, got %+v", tc.key, tc.err, e)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package gache
+
+// FillWrapper presents type of function, intended for
+// decorating filling functions of cache groups,
+// e.g. for collecting metrics or tracing
+type FillWrapper func(group string, next FillFuncCtx) FillFuncCtx
+
+// BatchFillWrapper presents type of function, intended
+// for decorating batch filling functions of cache groups
+type BatchFillWrapper func(group string, next BatchFillFunc) BatchFillFunc
+
+func (c *cache) WrapFills(wrappers ...FillWrapper) {
+	c.mx.Lock()
+	c.fillWrappers = append(c.fillWrappers[:len(c.fillWrappers):len(c.fillWrappers)], wrappers...)
+	c.mx.Unlock()
+}
+
+func (c *cache) WrapBatchFills(wrappers ...BatchFillWrapper) {
+	c.mx.Lock()
+	c.batchFillWrappers = append(c.batchFillWrappers[:len(c.batchFillWrappers):len(c.batchFillWrappers)], wrappers...)
+	c.mx.Unlock()
+}
+
+// wrapFill decorates filling function of group with specified key
+// by fill wrappers of cache. First added wrapper is outermost
+func (c *cache) wrapFill(group string, fillFunc FillFuncCtx) FillFuncCtx {
+	c.mx.RLock()
+	wrappers := c.fillWrappers
+	c.mx.RUnlock()
+
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		fillFunc = wrappers[i](group, fillFunc)
+	}
+
+	return fillFunc
+}
+
+// wrapBatchFill decorates batch filling function of group with
+// specified key by batch fill wrappers of cache like wrapFill
+func (c *cache) wrapBatchFill(group string, batchFillFunc BatchFillFunc) BatchFillFunc {
+	c.mx.RLock()
+	wrappers := c.batchFillWrappers
+	c.mx.RUnlock()
+
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		batchFillFunc = wrappers[i](group, batchFillFunc)
+	}
+
+	return batchFillFunc
+}
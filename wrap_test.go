@@ -0,0 +1,46 @@
+package gache
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestWrapFills(t *testing.T) {
+	var calls []string
+	wrapper := func(name string) FillWrapper {
+		return func(group string, next FillFuncCtx) FillFuncCtx {
+			return func(ctx context.Context, key string) (interface{}, bool) {
+				calls = append(calls, name+":"+group+"/"+key)
+				return next(ctx, key)
+			}
+		}
+	}
+
+	c := NewCache(WithFillWrapper(wrapper("outer")))
+	c.WrapFills(wrapper("inner"))
+	c.NewGroup("g", WithFillFunc(func(key string) (interface{}, bool) {
+		calls = append(calls, "fill")
+		return key, true
+	}))
+
+	if v, err := c.GetGroupVal("g", "a"); err != nil || v != "a" {
+		t.Fatalf("expected filled value, got %v, %v", v, err)
+	}
+	if got := strings.Join(calls, " "); got != "outer:g/a inner:g/a fill" {
+		t.Fatalf("expected wrappers in order of addition, got %q", got)
+	}
+}
+
+func TestGroupStats(t *testing.T) {
+	c := NewCache()
+	c.NewGroup("g")
+	c.Set("a", 1)
+	c.SetGroupVal("g", "b", 2)
+	c.GetGroupVal("g", "b")
+
+	stats := c.GroupStats()
+	if len(stats) != 2 || stats[""].Items != 1 || stats["g"].Hits != 1 {
+		t.Fatalf("expected stats of root group and g, got %+v", stats)
+	}
+}
@@ -0,0 +1,150 @@
+package gache
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultWriteQueueSize     = 1024
+	defaultWriteBatchSize     = 100
+	defaultWriteFlushInterval = time.Second
+)
+
+type writeBehindConfig struct {
+	queueSize int
+	batchSize int
+	interval  time.Duration
+}
+
+// writeOp presents pending write of value to store
+type writeOp struct {
+	key string
+	val interface{}
+	ttl time.Duration
+	del bool
+}
+
+// writeBehind buffers writes of group to store and flushes
+// them asynchronously by batches
+type writeBehind struct {
+	queue     chan writeOp
+	batchSize int
+	interval  time.Duration
+	mx        sync.RWMutex
+	closed    bool
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newWriteBehind(cfg writeBehindConfig) *writeBehind {
+	if cfg.queueSize <= 0 {
+		cfg.queueSize = defaultWriteQueueSize
+	}
+	if cfg.batchSize <= 0 {
+		cfg.batchSize = defaultWriteBatchSize
+	}
+	if cfg.interval <= 0 {
+		cfg.interval = defaultWriteFlushInterval
+	}
+
+	return &writeBehind{
+		queue:     make(chan writeOp, cfg.queueSize),
+		batchSize: cfg.batchSize,
+		interval:  cfg.interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// enqueue puts write into queue, blocking while queue is full.
+// After writer is stopped, write is performed synchronously
+func (g *group) enqueue(op writeOp) {
+	w := g.writer
+
+	w.mx.RLock()
+	if w.closed {
+		w.mx.RUnlock()
+		g.write([]writeOp{op})
+		return
+	}
+	w.queue <- op
+	w.mx.RUnlock()
+}
+
+// runWriter flushes queued writes of group, when batch is full
+// or flush interval elapses, until writer is stopped
+func (g *group) runWriter() {
+	w := g.writer
+	defer close(w.done)
+
+	ticker := g.cache.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	batch := make([]writeOp, 0, w.batchSize)
+	for {
+		select {
+		case op := <-w.queue:
+			batch = append(batch, op)
+			if len(batch) >= w.batchSize {
+				g.write(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C():
+			if len(batch) > 0 {
+				g.write(batch)
+				batch = batch[:0]
+			}
+		case <-w.stop:
+			for {
+				select {
+				case op := <-w.queue:
+					batch = append(batch, op)
+				default:
+					g.write(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// stopWriter drains queued writes of group and stops its writer
+func (g *group) stopWriter() {
+	w := g.writer
+	if w == nil {
+		return
+	}
+
+	w.mx.Lock()
+	if w.closed {
+		w.mx.Unlock()
+		return
+	}
+	w.closed = true
+	w.mx.Unlock()
+
+	close(w.stop)
+	<-w.done
+}
+
+// write performs writes to store of cache. Only last write
+// of every key is performed
+func (g *group) write(ops []writeOp) {
+	last := make(map[string]int, len(ops))
+	for i, op := range ops {
+		last[op.key] = i
+	}
+
+	for i, op := range ops {
+		if last[op.key] != i {
+			continue
+		}
+
+		if op.del {
+			g.erase(op.key)
+		} else {
+			g.save(op.key, op.val, op.ttl)
+		}
+	}
+}
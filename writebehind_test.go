@@ -0,0 +1,55 @@
+package gache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWriteBehind(t *testing.T) {
+	l2 := newMapStore()
+	c := NewTieredCache(l2)
+	c.NewGroup("g", WithWriteBehind(16, 4, time.Hour))
+	g, _ := c.Group("g")
+
+	g.Set("a", 1)
+	g.Set("a", 2)
+	g.Set("b", 1)
+	if n := l2.writeCount(); n != 0 {
+		t.Fatalf("expected writes to be queued, got %d performed", n)
+	}
+
+	g.Del("b")
+	waitFor(t, func() bool { return l2.writeCount() == 2 })
+	if v, ok := l2.value("g/a"); !ok || v != 2 {
+		t.Fatalf("expected last write of a, got %v, %v", v, ok)
+	}
+	if _, ok := l2.value("g/b"); ok {
+		t.Fatal("expected deleted b not to be written")
+	}
+
+	for i := 0; i < 3; i++ {
+		g.Set(strconv.Itoa(i), i)
+	}
+	c.Close()
+	if n := l2.writeCount(); n != 5 {
+		t.Fatalf("expected pending writes flushed on close, got %d writes", n)
+	}
+
+	g.Set("c", 3)
+	if _, ok := l2.value("g/c"); !ok {
+		t.Fatal("expected synchronous write after close")
+	}
+}
+
+func TestWriteBehindInterval(t *testing.T) {
+	l2 := newMapStore()
+	c := NewTieredCache(l2, WithWriteBehind(16, 100, time.Millisecond))
+	defer c.Close()
+
+	c.Set("a", 1)
+	waitFor(t, func() bool {
+		_, ok := l2.value("/a")
+		return ok
+	})
+}
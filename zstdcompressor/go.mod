@@ -0,0 +1,7 @@
+module github.com/kcasctiv/gache/zstdcompressor
+
+go 1.25
+
+require github.com/klauspost/compress v1.20.0
+
+replace github.com/kcasctiv/gache => ../
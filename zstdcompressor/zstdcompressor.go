@@ -0,0 +1,37 @@
+// Package zstdcompressor provides gache.Compressor
+// implementation, which uses Zstandard
+package zstdcompressor
+
+import "github.com/klauspost/compress/zstd"
+
+// Compressor is gache.Compressor, which uses Zstandard.
+// It is safe for concurrent use
+type Compressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// New returns Zstandard compressor
+func New() (*Compressor, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Compressor{encoder: encoder, decoder: decoder}, nil
+}
+
+// Compress returns Zstandard compressed data
+func (c *Compressor) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+// Decompress returns data decompressed from Zstandard compressed one
+func (c *Compressor) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
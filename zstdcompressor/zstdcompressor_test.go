@@ -0,0 +1,27 @@
+package zstdcompressor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCompressor(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Repeat([]byte("a"), 64)
+	z, err := c.Compress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(z) >= len(data) {
+		t.Fatalf("expected data to shrink, got %d bytes", len(z))
+	}
+
+	out, err := c.Decompress(z)
+	if err != nil || !bytes.Equal(out, data) {
+		t.Fatalf("expected %q, got %q, %v", data, out, err)
+	}
+}